@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/krli/go-sui-mcp/internal/config"
+	"github.com/krli/go-sui-mcp/internal/sui/suitest"
+)
+
+const usdcType = "0xdba34672e30cb065b1f93e3ab55318768fd6fef66c15942c9f7cb846e2f900e7::usdc::USDC"
+
+// usdcCoin is the recorded coin object with its type changed to a USDC coin
+func usdcCoin(t *testing.T) json.RawMessage {
+	t.Helper()
+	var obj map[string]any
+	if err := json.Unmarshal([]byte(suitest.Fixture("rpc_get_object.json")), &obj); err != nil {
+		t.Fatal(err)
+	}
+	data := obj["data"].(map[string]any)
+	data["type"] = "0x2::coin::Coin<" + usdcType + ">"
+	data["content"].(map[string]any)["type"] = data["type"]
+	raw, err := json.Marshal(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return raw
+}
+
+func TestAmountUnits(t *testing.T) {
+	tests := []struct {
+		name   string
+		amount any
+		usdc   bool
+		// want is the amount in base units passed to sui, or "" when the call must fail
+		want string
+		// wantErr must appear in the error
+		wantErr string
+	}{
+		{name: "base units", amount: "1000000000", want: "1000000000"},
+		{name: "JSON number", amount: 1000.0, want: "1000"},
+		{name: "SUI", amount: "1.5 SUI", want: "1500000000"},
+		{name: "lower-case unit without space", amount: "2sui", want: "2000000000"},
+		{name: "MIST", amount: "7 MIST", want: "7"},
+		{name: "smallest SUI fraction", amount: "0.000000001 SUI", want: "1"},
+		{name: "largest u64", amount: "18446744073709551615", want: "18446744073709551615"},
+		{name: "coin symbol from metadata", amount: "1.25 USDC", usdc: true, want: "1250000"},
+
+		{name: "excess SUI precision", amount: "1.0000000001 SUI", wantErr: "more than 9 decimal places"},
+		{name: "excess coin precision", amount: "0.1234567 USDC", usdc: true, wantErr: "more than 6 decimal places"},
+		{name: "u64 overflow", amount: "18446744073709551616", wantErr: "more than the largest coin amount"},
+		{name: "u64 overflow after scaling", amount: "18446744074 SUI", wantErr: "more than the largest coin amount"},
+		{name: "fraction without unit", amount: "1.5", wantErr: "no unit"},
+		{name: "unit of another coin", amount: "1 USDC", wantErr: "does not match the coin type"},
+		{name: "SUI on a USDC coin", amount: "1 SUI", usdc: true, wantErr: "whose symbol is USDC"},
+		{name: "inexact JSON number", amount: 1.5, wantErr: "not an exact amount"},
+		{name: "negative JSON number", amount: -1.0, wantErr: "not an exact amount"},
+		{name: "JSON number beyond 2^53", amount: 1e18, wantErr: "not an exact amount"},
+		{name: "exponent", amount: "1e9", wantErr: "unit e9 does not match"},
+		{name: "negative", amount: "-5 SUI", wantErr: "read as a command-line flag"},
+		{name: "not a number", amount: "five SUI", wantErr: "is not an amount"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ts := newTestServer(t, &config.Config{}, true)
+			ts.exec.Fallback(suitest.Response{Output: "Raw tx_bytes to execute:\nAAACAAgA4fUFAAAAAAAg"})
+			if tc.usdc {
+				ts.rpc.On("sui_getObject", usdcCoin(t))
+				ts.rpc.On("suix_getCoinMetadata", json.RawMessage(`{"decimals":6,"symbol":"USDC","name":"USD Coin","description":"","iconUrl":null,"id":null}`))
+			}
+
+			text, failed := ts.tryCall("sui-split-coin", map[string]any{
+				"coin-id": suitest.CoinObjectID,
+				"amounts": []any{tc.amount},
+				"dry-run": true,
+			})
+			if tc.wantErr != "" {
+				if !failed || !strings.Contains(text, tc.wantErr) {
+					t.Errorf("result = %q (failed %v), want an error containing %q", text, failed, tc.wantErr)
+				}
+				if hasCall(ts.exec.Calls(), "client", "split-coin") {
+					t.Error("sui split-coin ran with a rejected amount")
+				}
+				return
+			}
+			if failed {
+				t.Fatalf("split-coin failed: %s", text)
+			}
+			var argv []string
+			for _, call := range ts.exec.Calls() {
+				if hasCall([]suitest.Call{call}, "client", "split-coin") {
+					argv = call.Args
+				}
+			}
+			i := slices.Index(argv, "--amounts")
+			if i < 0 || i+1 >= len(argv) || argv[i+1] != tc.want {
+				t.Errorf("ran %v, want --amounts %s", argv, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,230 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/krli/go-sui-mcp/internal/approval"
+	"github.com/krli/go-sui-mcp/internal/config"
+	"github.com/krli/go-sui-mcp/internal/sui/suitest"
+	"github.com/mark3labs/mcp-go/client"
+	mcptransport "github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// approvalEndpoint answers every approval request with status and body and
+// records the requests it received
+func approvalEndpoint(t *testing.T, status int, body string) (*httptest.Server, *[]approval.Request) {
+	t.Helper()
+	var received []approval.Request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req approval.Request
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("approval request is not JSON: %v", err)
+		}
+		received = append(received, req)
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &received
+}
+
+func TestApprovalGate(t *testing.T) {
+	transfer := map[string]any{"to": suitest.OtherAddress, "object-id": suitest.CoinObjectID}
+	tests := []struct {
+		name   string
+		tool   string
+		args   map[string]any
+		status int
+		body   string
+		// wantAsked is whether the approver must be consulted
+		wantAsked bool
+		// wantError is text the refusal must contain, or "" for success
+		wantError string
+		// wantSigned is whether the real, non dry-run command must run
+		wantSigned bool
+	}{
+		{
+			name: "approved", tool: "sui-transfer", args: transfer,
+			status: http.StatusOK, body: `{"approved":true}`,
+			wantAsked: true, wantSigned: true,
+		},
+		{
+			name: "rejected with reason", tool: "sui-transfer", args: transfer,
+			status: http.StatusOK, body: `{"approved":false,"reason":"wrong recipient"}`,
+			wantAsked: true, wantError: "was not executed: wrong recipient",
+		},
+		{
+			name: "rejected without reason", tool: "sui-transfer", args: transfer,
+			status: http.StatusOK, body: `{"approved":false}`,
+			wantAsked: true, wantError: "was not executed: rejected",
+		},
+		{
+			name: "endpoint error fails closed", tool: "sui-transfer", args: transfer,
+			status: http.StatusInternalServerError, body: "boom",
+			wantAsked: true, wantError: "approval failed",
+		},
+		{
+			name: "invalid decision fails closed", tool: "sui-transfer", args: transfer,
+			status: http.StatusOK, body: "yes",
+			wantAsked: true, wantError: "approval failed",
+		},
+		{
+			name: "dry run is not gated", tool: "sui-transfer",
+			args:   map[string]any{"to": suitest.OtherAddress, "object-id": suitest.CoinObjectID, "dry-run": true},
+			status: http.StatusOK, body: `{"approved":false}`,
+		},
+		{
+			name: "read tool is not gated", tool: "sui-gas",
+			status: http.StatusOK, body: `{"approved":false}`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			endpoint, received := approvalEndpoint(t, tc.status, tc.body)
+			ts := newTestServer(t, &config.Config{Approval: config.ApprovalConfig{Mode: approval.ModeHTTP, URL: endpoint.URL}}, false)
+			ts.exec.Fallback(suitest.Response{Output: suitest.Fixture("dry_run.json")})
+
+			res := ts.call(t, tc.tool, tc.args)
+
+			if asked := len(*received) > 0; asked != tc.wantAsked {
+				t.Fatalf("approver asked = %v, want %v", asked, tc.wantAsked)
+			}
+			if tc.wantAsked {
+				req := (*received)[0]
+				if req.Tool != tc.tool || req.Preview == "" {
+					t.Errorf("approval request = %+v, want tool %s with a preview", req, tc.tool)
+				}
+				if dryRun, _ := req.Arguments["dry-run"].(bool); dryRun {
+					t.Errorf("approval request shows the forced dry-run argument: %v", req.Arguments)
+				}
+			}
+
+			if tc.wantError != "" {
+				if !res.IsError || !strings.Contains(resultText(res), tc.wantError) {
+					t.Errorf("result = %q (error %v), want error containing %q", resultText(res), res.IsError, tc.wantError)
+				}
+			} else if res.IsError {
+				t.Fatalf("%s failed: %s", tc.tool, resultText(res))
+			}
+
+			signed := slices.ContainsFunc(ts.exec.Calls(), func(call suitest.Call) bool {
+				return call.Name == suitest.Executable && len(call.Args) > 1 && call.Args[1] == "transfer" && !slices.Contains(call.Args, "--dry-run")
+			})
+			if signed != tc.wantSigned {
+				t.Errorf("signed transaction ran = %v, want %v; calls %v", signed, tc.wantSigned, ts.exec.Calls())
+			}
+		})
+	}
+}
+
+// elicitFunc answers elicitation requests in an in-process client
+type elicitFunc func(ctx context.Context, request mcp.ElicitationRequest) (*mcp.ElicitationResult, error)
+
+func (f elicitFunc) Elicit(ctx context.Context, request mcp.ElicitationRequest) (*mcp.ElicitationResult, error) {
+	return f(ctx, request)
+}
+
+// answer is an elicitFunc that replies with action and content
+func answer(action mcp.ElicitationResponseAction, content any) elicitFunc {
+	return func(ctx context.Context, request mcp.ElicitationRequest) (*mcp.ElicitationResult, error) {
+		return &mcp.ElicitationResult{ElicitationResponse: mcp.ElicitationResponse{Action: action, Content: content}}, nil
+	}
+}
+
+func TestElicitationApproval(t *testing.T) {
+	tests := []struct {
+		name string
+		// elicit is the client's elicitation handler; nil when it has none
+		elicit elicitFunc
+		// wantError is text the refusal must contain, or "" for success
+		wantError string
+	}{
+		{
+			name:   "approved",
+			elicit: answer(mcp.ElicitationResponseActionAccept, map[string]any{"approve": true}),
+		},
+		{
+			name:      "accepted without ticking approve",
+			elicit:    answer(mcp.ElicitationResponseActionAccept, map[string]any{"approve": false}),
+			wantError: "was not executed: user did not tick approve",
+		},
+		{
+			name:      "declined",
+			elicit:    answer(mcp.ElicitationResponseActionDecline, nil),
+			wantError: "was not executed: user declined",
+		},
+		{
+			name:      "cancelled",
+			elicit:    answer(mcp.ElicitationResponseActionCancel, nil),
+			wantError: "was not executed: user cancelled",
+		},
+		{
+			name: "client error fails closed",
+			elicit: func(ctx context.Context, request mcp.ElicitationRequest) (*mcp.ElicitationResult, error) {
+				return nil, errors.New("dialog crashed")
+			},
+			wantError: "was not executed: approval failed",
+		},
+		{
+			name:      "client without elicitation fails closed",
+			wantError: "was not executed: approval failed",
+		},
+		{
+			name: "unanswered request times out",
+			elicit: func(ctx context.Context, request mcp.ElicitationRequest) (*mcp.ElicitationResult, error) {
+				<-ctx.Done()
+				return nil, ctx.Err()
+			},
+			wantError: "was not executed: approval failed",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ts, s := buildTestServer(t, &config.Config{Approval: config.ApprovalConfig{Mode: approval.ModeElicitation, Timeout: 50 * time.Millisecond}}, false)
+			ts.exec.Fallback(suitest.Response{Output: suitest.Fixture("dry_run.json")})
+
+			var asked []mcp.ElicitationRequest
+			tr := mcptransport.NewInProcessTransport(s)
+			if tc.elicit != nil {
+				tr = mcptransport.NewInProcessTransportWithOptions(s, mcptransport.WithElicitationHandler(elicitFunc(
+					func(ctx context.Context, request mcp.ElicitationRequest) (*mcp.ElicitationResult, error) {
+						asked = append(asked, request)
+						return tc.elicit(ctx, request)
+					})))
+			}
+			ts.connect(t, client.NewClient(tr))
+
+			res := ts.call(t, "sui-transfer", transferArgs)
+
+			if tc.elicit != nil {
+				if len(asked) != 1 {
+					t.Fatalf("user was asked %d times, want once", len(asked))
+				}
+				if msg := asked[0].Params.Message; !strings.Contains(msg, "sui-transfer") || !strings.Contains(msg, suitest.OtherAddress) {
+					t.Errorf("approval prompt does not show the tool and the previewed transfer:\n%s", msg)
+				}
+			}
+			if tc.wantError != "" {
+				if !res.IsError || !strings.Contains(resultText(res), tc.wantError) {
+					t.Errorf("result = %q (error %v), want error containing %q", resultText(res), res.IsError, tc.wantError)
+				}
+			} else if res.IsError {
+				t.Fatalf("sui-transfer failed: %s", resultText(res))
+			}
+			if signed := hasCall(ts.exec.Calls(), signedTransfer()...); signed != (tc.wantError == "") {
+				t.Errorf("signed transaction ran = %v; calls %v", signed, ts.exec.Calls())
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/krli/go-sui-mcp/internal/audit"
+	"github.com/spf13/cobra"
+)
+
+// auditCmd groups the commands that work on the audit log
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Inspect the tool invocation audit log",
+}
+
+// auditAnchor is the head recorded by an earlier verify that the log must still contain
+var auditAnchor string
+
+// auditVerifyCmd checks the hash chain of the audit log
+var auditVerifyCmd = &cobra.Command{
+	Use:   "verify [path]",
+	Short: "Verify that the audit log has not been tampered with",
+	Long: `Walk the audit log's hash chain and report the first record that was
+modified, removed or reordered. Uses audit.path when no path is given.
+
+The chain cannot tell on its own whether records were cut from the end of the
+log, so verify prints the head of the chain as <seq>:<hash>. Keep it somewhere
+the log's writers cannot reach and pass it back with --anchor; verify then also
+fails if that record is gone or was rewritten.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		path := ""
+		if len(args) == 1 {
+			path = args[0]
+		} else {
+			cfg, err := loadConfig()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Config error: %v\n", err)
+				os.Exit(1)
+			}
+			path = cfg.Audit.Path
+		}
+
+		var anchor *audit.Head
+		if auditAnchor != "" {
+			head, err := audit.ParseHead(auditAnchor)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			anchor = &head
+		}
+
+		head, err := audit.VerifyFile(path, anchor)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: verification FAILED after %d valid records: %v\n", path, head.Seq, err)
+			os.Exit(1)
+		}
+		fmt.Printf("%s: %d records, hash chain intact\nhead: %s\n", path, head.Seq, head)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(auditCmd)
+	auditCmd.AddCommand(auditVerifyCmd)
+	auditVerifyCmd.Flags().StringVar(&auditAnchor, "anchor", "", "Head printed by an earlier verify, as <seq>:<hash>, that the log must still contain")
+}
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/krli/go-sui-mcp/internal/audit"
+	"github.com/krli/go-sui-mcp/internal/config"
+	"github.com/krli/go-sui-mcp/internal/sui/suitest"
+	"github.com/mark3labs/mcp-go/client"
+)
+
+func TestAuditRecordsCaller(t *testing.T) {
+	authCfg := config.AuthConfig{
+		Tokens: []config.TokenConfig{{Name: "ci-bot", Token: "bot-token", Role: "reader"}},
+		Roles:  map[string]config.RoleConfig{"reader": {}},
+	}
+	tests := []struct {
+		name       string
+		auth       bool
+		wantCaller string
+		wantRole   string
+	}{
+		{name: "network transport", auth: true, wantCaller: "ci-bot", wantRole: "reader"},
+		{name: "stdio", auth: false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "audit.jsonl")
+			cfg := &config.Config{Audit: config.AuditConfig{Enabled: true, Path: path}}
+			var ts *testServer
+			if tc.auth {
+				cfg.Server.Auth = authCfg
+				ts = newAuthTestServer(t, cfg, "bot-token", false)
+			} else {
+				ts = newTestServer(t, cfg, false)
+			}
+			if res := ts.call(t, "sui-gas", nil); res.IsError {
+				t.Fatalf("sui-gas failed: %s", resultText(res))
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+			var rec audit.Record
+			if err := json.Unmarshal([]byte(lines[len(lines)-1]), &rec); err != nil {
+				t.Fatal(err)
+			}
+			if rec.Tool != "sui-gas" || rec.Caller != tc.wantCaller || rec.Role != tc.wantRole {
+				t.Errorf("record = tool %q caller %q role %q, want sui-gas %q %q", rec.Tool, rec.Caller, rec.Role, tc.wantCaller, tc.wantRole)
+			}
+			if _, err := audit.VerifyFile(path, nil); err != nil {
+				t.Errorf("VerifyFile: %v", err)
+			}
+		})
+	}
+}
+
+func TestShutdownClosesAuditLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	cfg := &config.Config{Audit: config.AuditConfig{Enabled: true, Path: path}}
+	ts := &testServer{exec: suitest.NewRecordedExecutor()}
+	s, shutdown, err := newMCPServer(cfg, suitest.NewClient(ts.exec))
+	if err != nil {
+		t.Fatalf("newMCPServer: %v", err)
+	}
+	c, err := client.NewInProcessClient(s)
+	if err != nil {
+		t.Fatalf("NewInProcessClient: %v", err)
+	}
+	ts.connect(t, c)
+	if res := ts.call(t, "sui-gas", nil); res.IsError {
+		t.Fatalf("sui-gas failed: %s", resultText(res))
+	}
+
+	shutdown()
+	head, err := audit.VerifyFile(path, nil)
+	if err != nil {
+		t.Fatalf("VerifyFile after shutdown: %v", err)
+	}
+	if head.Seq != 1 {
+		t.Fatalf("log holds %d records, want 1", head.Seq)
+	}
+
+	// A call that outlives serving is no longer written to the closed log
+	ts.call(t, "sui-gas", nil)
+	if after, err := audit.VerifyFile(path, nil); err != nil || after != head {
+		t.Errorf("log after a late call = %v (%v), want it unchanged at %v", after, err, head)
+	}
+}
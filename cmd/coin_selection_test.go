@@ -0,0 +1,243 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/krli/go-sui-mcp/internal/config"
+	"github.com/krli/go-sui-mcp/internal/sui"
+	"github.com/krli/go-sui-mcp/internal/sui/suitest"
+)
+
+// Coins owned by the signer in the selection tests
+var (
+	threeSUI  = testCoin(1, sui.SUICoinType, "3000000000")
+	oneSUI    = testCoin(2, sui.SUICoinType, "1000000000")
+	dustSUI   = testCoin(3, sui.SUICoinType, "10000000")
+	usdcLarge = testCoin(4, usdcType, "200")
+	usdcSmall = testCoin(5, usdcType, "100")
+)
+
+func testCoin(n int, coinType, balance string) sui.Coin {
+	return sui.Coin{CoinType: coinType, CoinObjectID: fmt.Sprintf("0x%064x", n), Digest: "9Gcx3uCBhGnW8pUx1mGZXSTzMHCcNzuo8rsP3FNmLvc3", Balance: balance}
+}
+
+// flagValues returns the values of every occurrence of flag in args
+func flagValues(args []string, flag string) []string {
+	var values []string
+	for i := 0; i+1 < len(args); i++ {
+		if args[i] == flag {
+			values = append(values, args[i+1])
+		}
+	}
+	return values
+}
+
+func TestCoinSelection(t *testing.T) {
+	tests := []struct {
+		name  string
+		coins []sui.Coin
+		tool  string
+		args  map[string]any
+		// wantCommand is the sui client subcommand run and wantInputs the coins it spends
+		wantCommand string
+		wantInputs  []string
+		// wantErr must appear in the refusal when nothing may run
+		wantErr string
+		// wantNoListing is set when the owned coins must not be looked up
+		wantNoListing bool
+	}{
+		{
+			name:        "pay-sui picks the largest coin that covers amount and gas",
+			coins:       []sui.Coin{oneSUI, threeSUI, dustSUI},
+			tool:        "sui-pay-sui",
+			args:        map[string]any{"recipients": []any{suitest.OtherAddress}, "amounts": []any{"1 SUI"}},
+			wantCommand: "pay-sui",
+			wantInputs:  []string{threeSUI.CoinObjectID},
+		},
+		{
+			name:        "pay-sui adds coins until they cover amount and gas",
+			coins:       []sui.Coin{dustSUI, oneSUI, threeSUI},
+			tool:        "sui-pay-sui",
+			args:        map[string]any{"recipients": []any{suitest.OtherAddress}, "amounts": []any{"3.5 SUI"}},
+			wantCommand: "pay-sui",
+			wantInputs:  []string{threeSUI.CoinObjectID, oneSUI.CoinObjectID},
+		},
+		{
+			name:        "pay-sui keeps the default gas reserve on top of the amount",
+			coins:       []sui.Coin{threeSUI, oneSUI},
+			tool:        "sui-pay-sui",
+			args:        map[string]any{"recipients": []any{suitest.OtherAddress}, "amounts": []any{"3 SUI"}},
+			wantCommand: "pay-sui",
+			wantInputs:  []string{threeSUI.CoinObjectID, oneSUI.CoinObjectID},
+		},
+		{
+			name:        "pay-sui reserves the gas budget when one is given",
+			coins:       []sui.Coin{threeSUI, oneSUI},
+			tool:        "sui-pay-sui",
+			args:        map[string]any{"recipients": []any{suitest.OtherAddress}, "amounts": []any{"2999999000"}, "gas-budget": "1000"},
+			wantCommand: "pay-sui",
+			wantInputs:  []string{threeSUI.CoinObjectID},
+		},
+		{
+			name:    "pay-sui with too little SUI",
+			coins:   []sui.Coin{threeSUI, oneSUI, dustSUI},
+			tool:    "sui-pay-sui",
+			args:    map[string]any{"recipients": []any{suitest.OtherAddress}, "amounts": []any{"4 SUI"}},
+			wantErr: "insufficient 0x2::sui::SUI balance: need 4050000000, have 4010000000 across 3 coins",
+		},
+		{
+			name:          "pay-sui spends the given input coins",
+			coins:         []sui.Coin{threeSUI, oneSUI},
+			tool:          "sui-pay-sui",
+			args:          map[string]any{"recipients": []any{suitest.OtherAddress}, "amounts": []any{"1 SUI"}, "input-coins": []any{dustSUI.CoinObjectID}},
+			wantCommand:   "pay-sui",
+			wantInputs:    []string{dustSUI.CoinObjectID},
+			wantNoListing: true,
+		},
+		{
+			name:        "transfer-sui prefers the smallest single coin covering amount and gas",
+			coins:       []sui.Coin{threeSUI, oneSUI, dustSUI},
+			tool:        "sui-transfer-sui",
+			args:        map[string]any{"to": suitest.OtherAddress, "amount": "0.5 SUI"},
+			wantCommand: "transfer-sui",
+			wantInputs:  []string{oneSUI.CoinObjectID},
+		},
+		{
+			name:        "transfer-sui falls back to pay-sui when no single coin covers it",
+			coins:       []sui.Coin{threeSUI, oneSUI},
+			tool:        "sui-transfer-sui",
+			args:        map[string]any{"to": suitest.OtherAddress, "amount": "3.5 SUI"},
+			wantCommand: "pay-sui",
+			wantInputs:  []string{threeSUI.CoinObjectID, oneSUI.CoinObjectID},
+		},
+		{
+			name:        "transfer-sui counts gas when choosing a single coin",
+			coins:       []sui.Coin{threeSUI, oneSUI},
+			tool:        "sui-transfer-sui",
+			args:        map[string]any{"to": suitest.OtherAddress, "amount": "1 SUI"},
+			wantCommand: "transfer-sui",
+			wantInputs:  []string{threeSUI.CoinObjectID},
+		},
+		{
+			name:    "transfer-sui with too little SUI",
+			coins:   []sui.Coin{threeSUI, oneSUI},
+			tool:    "sui-transfer-sui",
+			args:    map[string]any{"to": suitest.OtherAddress, "amount": "4 SUI"},
+			wantErr: "insufficient 0x2::sui::SUI balance",
+		},
+		{
+			name:          "transfer-sui spends the given coin",
+			coins:         []sui.Coin{threeSUI, oneSUI},
+			tool:          "sui-transfer-sui",
+			args:          map[string]any{"to": suitest.OtherAddress, "amount": "1 SUI", "sui-coin-object-id": dustSUI.CoinObjectID},
+			wantCommand:   "transfer-sui",
+			wantInputs:    []string{dustSUI.CoinObjectID},
+			wantNoListing: true,
+		},
+		{
+			name:        "pay in another coin selects only that coin, leaving gas to a SUI coin",
+			coins:       []sui.Coin{threeSUI, usdcSmall, usdcLarge},
+			tool:        "sui-pay",
+			args:        map[string]any{"recipients": []any{suitest.OtherAddress}, "amounts": []any{"250"}, "coin-type": usdcType},
+			wantCommand: "pay",
+			wantInputs:  []string{usdcLarge.CoinObjectID, usdcSmall.CoinObjectID},
+		},
+		{
+			name:    "pay in another coin with too little of it",
+			coins:   []sui.Coin{threeSUI, usdcSmall},
+			tool:    "sui-pay",
+			args:    map[string]any{"recipients": []any{suitest.OtherAddress}, "amounts": []any{"250"}, "coin-type": usdcType},
+			wantErr: "insufficient " + usdcType + " balance: need 250, have 100 across 1 coins",
+		},
+		{
+			name:        "pay in SUI leaves a coin for gas",
+			coins:       []sui.Coin{threeSUI, oneSUI},
+			tool:        "sui-pay",
+			args:        map[string]any{"recipients": []any{suitest.OtherAddress}, "amounts": []any{"1 SUI"}, "coin-type": sui.SUICoinType},
+			wantCommand: "pay",
+			wantInputs:  []string{threeSUI.CoinObjectID},
+		},
+		{
+			name:    "pay in SUI without a coin left for gas",
+			coins:   []sui.Coin{threeSUI, oneSUI, dustSUI},
+			tool:    "sui-pay",
+			args:    map[string]any{"recipients": []any{suitest.OtherAddress}, "amounts": []any{"3.5 SUI"}, "coin-type": sui.SUICoinType},
+			wantErr: "no SUI coin is left over to pay for gas",
+		},
+		{
+			name:    "pay without input coins or a coin type",
+			coins:   []sui.Coin{usdcLarge},
+			tool:    "sui-pay",
+			args:    map[string]any{"recipients": []any{suitest.OtherAddress}, "amounts": []any{"100"}},
+			wantErr: "coin-type is required when input-coins is omitted",
+		},
+		{
+			name:          "pay spends the given input coins",
+			coins:         []sui.Coin{usdcLarge, usdcSmall},
+			tool:          "sui-pay",
+			args:          map[string]any{"recipients": []any{suitest.OtherAddress}, "amounts": []any{"100"}, "coin-type": usdcType, "input-coins": []any{usdcSmall.CoinObjectID}},
+			wantCommand:   "pay",
+			wantInputs:    []string{usdcSmall.CoinObjectID},
+			wantNoListing: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ts := newTestServer(t, &config.Config{}, true)
+			ts.exec.Fallback(suitest.Response{Output: "Raw tx_bytes to execute:\nAAACAAgA4fUFAAAAAAAg"})
+			ts.rpc.OnFunc("suix_getCoins", func(params []json.RawMessage) any {
+				var coinType string
+				if err := json.Unmarshal(params[1], &coinType); err != nil {
+					t.Errorf("suix_getCoins coin type %s: %v", params[1], err)
+				}
+				page := map[string]any{"data": []sui.Coin{}, "hasNextPage": false}
+				for _, coin := range tc.coins {
+					if coin.CoinType == coinType {
+						page["data"] = append(page["data"].([]sui.Coin), coin)
+					}
+				}
+				return page
+			})
+			tc.args["dry-run"] = true
+
+			text, failed := ts.tryCall(tc.tool, tc.args)
+			var run []string
+			for _, call := range ts.exec.Calls() {
+				if len(call.Args) > 1 && call.Args[0] == "client" && slices.Contains([]string{"pay-sui", "pay", "transfer-sui"}, call.Args[1]) {
+					run = call.Args
+				}
+			}
+			if tc.wantErr != "" {
+				if !failed || !strings.Contains(text, tc.wantErr) {
+					t.Errorf("result = %q (failed %v), want an error containing %q", text, failed, tc.wantErr)
+				}
+				if run != nil {
+					t.Errorf("ran %v after refusing", run)
+				}
+				return
+			}
+			if failed {
+				t.Fatalf("%s failed: %s", tc.tool, text)
+			}
+			if run == nil || run[1] != tc.wantCommand {
+				t.Fatalf("ran %v, want sui client %s", run, tc.wantCommand)
+			}
+			inputs := flagValues(run, "--input-coins")
+			if tc.wantCommand == "transfer-sui" {
+				inputs = flagValues(run, "--sui-coin-object-id")
+			}
+			if !slices.Equal(inputs, tc.wantInputs) {
+				t.Errorf("spent %v, want %v", inputs, tc.wantInputs)
+			}
+			listed := slices.ContainsFunc(ts.rpc.Requests(), func(r suitest.RPCRequest) bool { return r.Method == "suix_getCoins" })
+			if listed == tc.wantNoListing {
+				t.Errorf("owned coins listed = %v, want %v", listed, !tc.wantNoListing)
+			}
+		})
+	}
+}
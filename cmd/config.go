@@ -0,0 +1,196 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/krli/go-sui-mcp/internal/approval"
+	"github.com/krli/go-sui-mcp/internal/auth"
+	"github.com/krli/go-sui-mcp/internal/config"
+	"github.com/krli/go-sui-mcp/internal/secrets"
+	"github.com/krli/go-sui-mcp/internal/services"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+var showEffective bool
+
+// configCmd groups the commands that inspect the configuration
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect and validate the configuration",
+}
+
+// configValidateCmd loads the configuration exactly as the server would and reports every problem
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check the config file, environment and flags for errors",
+	Run: func(cmd *cobra.Command, args []string) {
+		if _, err := loadConfig(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		fmt.Printf("%s: configuration is valid\n", configSource())
+	},
+}
+
+// configShowCmd prints the config file with tokens masked, or with --effective the merged result of defaults, file, environment and flags
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Print the configuration",
+	Run: func(cmd *cobra.Command, args []string) {
+		if !showEffective {
+			path := viper.ConfigFileUsed()
+			if _, err := os.Stat(path); err != nil {
+				fmt.Fprintf(os.Stderr, "no config file loaded: %v\n", err)
+				os.Exit(1)
+			}
+			out, err := redactConfigFile(path)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			fmt.Printf("# %s\n%s", path, out)
+			return
+		}
+
+		if _, err := loadConfig(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		settings := viper.AllSettings()
+		redactTokens(settings)
+		out, err := yaml.Marshal(settings)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		fmt.Printf("# effective configuration (defaults < %s < GOSUI_* environment < flags)\n%s", configSource(), out)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configValidateCmd)
+	configCmd.AddCommand(configShowCmd)
+
+	configShowCmd.Flags().BoolVar(&showEffective, "effective", false, "Print the merged defaults, file, environment and flags instead of the file")
+}
+
+// loadConfig loads the validated configuration and runs the checks owned by
+// the subsystems that consume it, so a bad tool name or role fails up front
+func loadConfig() (*config.Config, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	var errs []error
+	if _, err := services.NewToolFilter(cfg.Tools, cfg.Server.ReadOnly); err != nil {
+		errs = append(errs, fmt.Errorf("tools: %w", err))
+	}
+	if _, err := services.NewRoleGuard(cfg.Server.Auth.Roles); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := auth.New(cfg.Server.Auth); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := approval.New(cfg.Approval); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := secrets.New(cfg.Secrets); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := newTLSConfig(cfg.Server.TLS); err != nil {
+		errs = append(errs, err)
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, fmt.Errorf("invalid config:\n%w", err)
+	}
+	return cfg, nil
+}
+
+// configSource names where the file settings came from
+func configSource() string {
+	if path := viper.ConfigFileUsed(); path != "" {
+		return path
+	}
+	return "no config file"
+}
+
+// redactTokens hides auth tokens from printed settings
+func redactTokens(settings map[string]any) {
+	serverSettings, _ := settings["server"].(map[string]any)
+	authSettings, _ := serverSettings["auth"].(map[string]any)
+	tokens, _ := authSettings["tokens"].([]any)
+	for _, t := range tokens {
+		if token, ok := t.(map[string]any); ok && token["token"] != nil {
+			token["token"] = "********"
+		}
+	}
+}
+
+// redactConfigFile returns the config file at path with auth tokens hidden.
+// YAML and JSON files keep their layout and comments; other formats are
+// printed as the YAML of the settings viper reads from them.
+func redactConfigFile(path string) ([]byte, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml", ".json":
+	default:
+		v := viper.New()
+		v.SetConfigFile(path)
+		if err := v.ReadInConfig(); err != nil {
+			return nil, err
+		}
+		settings := v.AllSettings()
+		redactTokens(settings)
+		return yaml.Marshal(settings)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if len(doc.Content) == 0 {
+		return data, nil
+	}
+	tokens := mappingValue(mappingValue(mappingValue(doc.Content[0], "server"), "auth"), "tokens")
+	if tokens != nil && tokens.Kind == yaml.SequenceNode {
+		for _, token := range tokens.Content {
+			if value := mappingValue(token, "token"); value != nil && value.Kind == yaml.ScalarNode {
+				value.SetString("********")
+			}
+		}
+	}
+
+	var out bytes.Buffer
+	enc := yaml.NewEncoder(&out)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// mappingValue returns the value of key in a YAML mapping, matching keys
+// case-insensitively as viper does; it returns nil when there is none
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if strings.EqualFold(node.Content[i].Value, key) {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRedactConfigFile(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		data string
+		// want must appear in the output
+		want []string
+	}{
+		{
+			name: "yaml",
+			file: "config.yaml",
+			data: `# network access
+server:
+  transport: http
+  auth:
+    tokens:
+      - name: ci
+        token: s3cret-ci # rotated monthly
+        role: reader
+      - name: ops
+        token: "s3cret-ops"
+`,
+			want: []string{"# network access", "name: ci", "token: '********'", "role: reader", "transport: http"},
+		},
+		{
+			name: "yaml keys in another case",
+			file: "config.yml",
+			data: "Server:\n  Auth:\n    Tokens:\n      - Token: s3cret-ci\n",
+			want: []string{"Token: '********'"},
+		},
+		{
+			name: "json",
+			file: "config.json",
+			data: `{"server": {"auth": {"tokens": [{"name": "ci", "token": "s3cret-ci"}, {"name": "ops", "token": "s3cret-ops"}]}}}`,
+			want: []string{`"name": "ci"`, `"token": "********"`},
+		},
+		{
+			name: "toml",
+			file: "config.toml",
+			data: "[server]\ntransport = \"http\"\n\n[[server.auth.tokens]]\nname = \"ci\"\ntoken = \"s3cret-ci\"\n",
+			want: []string{"transport: http", "name: ci", "token: '********'"},
+		},
+		{
+			name: "no tokens",
+			file: "config.yaml",
+			data: "sui:\n  env: testnet # default network\n",
+			want: []string{"env: testnet # default network"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tc.file)
+			if err := os.WriteFile(path, []byte(tc.data), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			out, err := redactConfigFile(path)
+			if err != nil {
+				t.Fatalf("redactConfigFile: %v", err)
+			}
+			if strings.Contains(string(out), "s3cret") {
+				t.Errorf("token printed:\n%s", out)
+			}
+			for _, want := range tc.want {
+				if !strings.Contains(string(out), want) {
+					t.Errorf("output lacks %q:\n%s", want, out)
+				}
+			}
+		})
+	}
+}
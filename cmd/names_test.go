@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/krli/go-sui-mcp/internal/config"
+	"github.com/krli/go-sui-mcp/internal/sui/suitest"
+)
+
+// carolAddress is where @carol points; it appears nowhere in the arguments
+const carolAddress = "0x00000000000000000000000000000000000000000000000000000000000ca401"
+
+// registeredNames scripts SuiNS on the stub: alice.sui points at
+// suitest.OtherAddress, returned in short upper-case form, @carol at
+// carolAddress, and every other name is unregistered
+func registeredNames(stub *suitest.RPCStub) {
+	stub.OnFunc("suix_resolveNameServiceAddress", func(params []json.RawMessage) any {
+		var name string
+		json.Unmarshal(params[0], &name)
+		switch name {
+		case "alice.sui":
+			return "0x" + strings.ToUpper(suitest.OtherAddress[2:])
+		case "@carol":
+			return strings.Replace(carolAddress, "0x000000000000000000000000000000000000000000000000000000000", "0x", 1)
+		}
+		return nil
+	})
+}
+
+func TestNameResolution(t *testing.T) {
+	tests := []struct {
+		name string
+		tool string
+		args map[string]any
+		cfg  config.Config
+		// wantArgv are the address arguments sui must be passed, in order
+		wantArgv []string
+		// wantResolved are the names the result must echo with their addresses
+		wantResolved map[string]string
+		// wantErr must appear in the refusal
+		wantErr string
+	}{
+		{
+			name:         "transfer recipient",
+			tool:         "sui-transfer",
+			args:         map[string]any{"to": "alice.sui", "object-id": suitest.CoinObjectID},
+			wantArgv:     []string{"--to", suitest.OtherAddress},
+			wantResolved: map[string]string{"alice.sui": suitest.OtherAddress},
+		},
+		{
+			name:         "recipients mixing names and addresses",
+			tool:         "sui-pay-sui",
+			args:         map[string]any{"input-coins": []any{suitest.CoinObjectID}, "recipients": []any{"@carol", suitest.ActiveAddress, "Alice.sui"}, "amounts": []any{"1", "2", "3"}},
+			wantArgv:     []string{"--recipients", carolAddress, "--recipients", suitest.ActiveAddress, "--recipients", suitest.OtherAddress},
+			wantResolved: map[string]string{"@carol": carolAddress, "Alice.sui": suitest.OtherAddress},
+		},
+		{
+			name:    "unregistered name",
+			tool:    "sui-transfer",
+			args:    map[string]any{"to": "bob.sui", "object-id": suitest.CoinObjectID},
+			wantErr: "cannot resolve to bob.sui: name bob.sui is not registered",
+		},
+		{
+			name:    "unregistered name in a list",
+			tool:    "sui-pay-all-sui",
+			args:    map[string]any{"input-coins": []any{suitest.CoinObjectID}, "recipient": "@dave"},
+			wantErr: "cannot resolve recipient @dave",
+		},
+		{
+			name:    "policy checks the resolved address",
+			tool:    "sui-transfer",
+			args:    map[string]any{"to": "alice.sui", "object-id": suitest.CoinObjectID},
+			cfg:     config.Config{Policy: config.PolicyConfig{Enabled: true, BlockedRecipients: []string{suitest.OtherAddress}}},
+			wantErr: "recipient " + suitest.OtherAddress + " is blocked",
+		},
+		{
+			name:    "argument that takes no names",
+			tool:    "sui-transfer",
+			args:    map[string]any{"to": suitest.OtherAddress, "object-id": "alice.sui"},
+			wantErr: "invalid object-id",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ts := newTestServer(t, &tc.cfg, true)
+			registeredNames(ts.rpc)
+			ts.exec.Fallback(suitest.Response{Output: suitest.Fixture("dry_run.json")})
+
+			args := map[string]any{"dry-run": true}
+			if tc.cfg.Policy.Enabled {
+				// The policy only checks payments that are about to be signed
+				args = map[string]any{}
+			}
+			for k, v := range tc.args {
+				args[k] = v
+			}
+
+			text, failed := ts.tryCall(tc.tool, args)
+			if tc.wantErr != "" {
+				if !failed || !strings.Contains(text, tc.wantErr) {
+					t.Errorf("result = %q (failed %v), want a refusal containing %q", text, failed, tc.wantErr)
+				}
+				if hasCall(ts.exec.Calls(), "client") {
+					t.Errorf("sui ran: %v", ts.exec.Calls())
+				}
+				return
+			}
+			if failed {
+				t.Fatalf("%s failed: %s", tc.tool, text)
+			}
+
+			call, _ := ts.exec.LastCall()
+			if !containsRun(call.Args, tc.wantArgv) {
+				t.Errorf("ran %v, want %v in it", call.Args, tc.wantArgv)
+			}
+			for name, address := range tc.wantResolved {
+				if !strings.Contains(text, name+" -> "+address) {
+					t.Errorf("result does not show %s -> %s:\n%s", name, address, text)
+				}
+				if strings.Contains(strings.Join(call.Args, " "), name) {
+					t.Errorf("name %s reached sui: %v", name, call.Args)
+				}
+			}
+		})
+	}
+}
+
+// containsRun reports whether want appears in args as a contiguous run
+func containsRun(args, want []string) bool {
+	for i := range args {
+		if len(args)-i >= len(want) && slices.Equal(args[i:i+len(want)], want) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestResolvedNamesInStructuredResult(t *testing.T) {
+	ts := newTestServer(t, &config.Config{}, true)
+	registeredNames(ts.rpc)
+	ts.exec.Fallback(suitest.Response{Output: suitest.Fixture("dry_run.json")})
+
+	res := ts.call(t, "sui-transfer", map[string]any{"to": "alice.sui", "object-id": suitest.CoinObjectID, "dry-run": true})
+	if res.IsError {
+		t.Fatalf("sui-transfer failed: %s", resultText(res))
+	}
+	resolved, _ := structured(t, res)["resolvedNames"].(map[string]any)
+	if resolved["alice.sui"] != suitest.OtherAddress {
+		t.Errorf("resolvedNames = %v, want alice.sui -> %s", resolved, suitest.OtherAddress)
+	}
+}
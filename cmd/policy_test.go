@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"errors"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/krli/go-sui-mcp/internal/auth"
+	"github.com/krli/go-sui-mcp/internal/config"
+	"github.com/krli/go-sui-mcp/internal/sui/suitest"
+	"github.com/mark3labs/mcp-go/client"
+	mcptransport "github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// The recorded dry run sends 1 SUI to suitest.OtherAddress
+var (
+	transferArgs = map[string]any{"to": suitest.OtherAddress, "object-id": suitest.CoinObjectID}
+	transferArgv = []string{"client", "transfer", "--to", suitest.OtherAddress, "--object-id", suitest.CoinObjectID}
+)
+
+// signedTransfer is the argv of the transfer that is signed and submitted
+func signedTransfer() []string {
+	return append(append([]string(nil), transferArgv...), "--json")
+}
+
+// dryRunTransfer is the argv of the dry run the policy measures the transfer with
+func dryRunTransfer() []string {
+	return append(append([]string(nil), transferArgv...), "--dry-run", "--json")
+}
+
+// newAuthTestServer serves cfg over streamable HTTP and connects with token,
+// so calls carry the identity and role the token was granted
+func newAuthTestServer(t *testing.T, cfg *config.Config, token string, withRPC bool) *testServer {
+	t.Helper()
+	ts, s := buildTestServer(t, cfg, withRPC)
+	authenticator, err := auth.New(cfg.Server.Auth)
+	if err != nil {
+		t.Fatalf("auth.New: %v", err)
+	}
+	srv := httptest.NewServer(authenticator.Middleware(server.NewStreamableHTTPServer(s, server.WithHTTPContextFunc(authenticator.ContextFunc))))
+	t.Cleanup(srv.Close)
+
+	c, err := client.NewStreamableHttpClient(srv.URL, mcptransport.WithHTTPHeaders(map[string]string{"Authorization": "Bearer " + token}))
+	if err != nil {
+		t.Fatalf("NewStreamableHttpClient: %v", err)
+	}
+	ts.connect(t, c)
+	return ts
+}
+
+func TestPolicyReleasesFailedPayments(t *testing.T) {
+	aborted := strings.Replace(suitest.Fixture("dry_run.json"), `"status": "success"`, `"status": "failure", "error": "MoveAbort"`, 1)
+	tests := []struct {
+		name string
+		// script makes the first transfer fail
+		script func(exec *suitest.FakeExecutor)
+	}{
+		{"dry run fails", func(exec *suitest.FakeExecutor) {
+			exec.OnError(errors.New("object is locked"), dryRunTransfer()...)
+		}},
+		{"execution fails", func(exec *suitest.FakeExecutor) {
+			exec.OnError(errors.New("insufficient gas"), signedTransfer()...)
+		}},
+		{"transaction aborts", func(exec *suitest.FakeExecutor) {
+			exec.On(aborted, signedTransfer()...)
+		}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Room for one 1 SUI transfer a day, but not two
+			ts := newTestServer(t, &config.Config{Policy: config.PolicyConfig{Enabled: true, MaxPerDayMist: 1_500_000_000}}, false)
+			ts.exec.Fallback(suitest.Response{Output: suitest.Fixture("dry_run.json")})
+
+			tc.script(ts.exec)
+			if text, failed := ts.tryCall("sui-transfer", transferArgs); !failed && !strings.Contains(text, "failure") {
+				t.Fatalf("first transfer did not fail:\n%s", text)
+			}
+
+			ts.exec.On(suitest.Fixture("dry_run.json"), dryRunTransfer()...)
+			ts.exec.On(suitest.Fixture("dry_run.json"), signedTransfer()...)
+			if res := ts.call(t, "sui-transfer", transferArgs); res.IsError {
+				t.Fatalf("the failed transfer still counts against the limit: %s", resultText(res))
+			}
+
+			res := ts.call(t, "sui-transfer", transferArgs)
+			if !res.IsError || !strings.Contains(resultText(res), "max_per_day_mist") {
+				t.Errorf("transfer over the daily limit was not refused: %s", resultText(res))
+			}
+		})
+	}
+}
+
+func TestPolicyLedgerSurvivesRestart(t *testing.T) {
+	cfg := &config.Config{Policy: config.PolicyConfig{
+		Enabled:       true,
+		MaxPerDayMist: 1_500_000_000,
+		StateFile:     filepath.Join(t.TempDir(), "spending.json"),
+	}}
+
+	before := newTestServer(t, cfg, false)
+	before.exec.Fallback(suitest.Response{Output: suitest.Fixture("dry_run.json")})
+	if res := before.call(t, "sui-transfer", transferArgs); res.IsError {
+		t.Fatalf("first transfer refused: %s", resultText(res))
+	}
+
+	after := newTestServer(t, cfg, false)
+	after.exec.Fallback(suitest.Response{Output: suitest.Fixture("dry_run.json")})
+	res := after.call(t, "sui-transfer", transferArgs)
+	if !res.IsError || !strings.Contains(resultText(res), "max_per_day_mist") {
+		t.Errorf("spending before the restart was forgotten: %s", resultText(res))
+	}
+	if hasCall(after.exec.Calls(), signedTransfer()...) {
+		t.Error("refused transfer was submitted")
+	}
+}
+
+func TestRoleLimitsWithoutPolicy(t *testing.T) {
+	// policy.enabled stays false; only the role limits apply
+	cfg := &config.Config{Server: config.ServerConfig{Auth: config.AuthConfig{
+		Tokens: []config.TokenConfig{
+			{Name: "bot", Token: "bot-token", Role: "agent"},
+			{Name: "ops", Token: "ops-token", Role: "operator"},
+		},
+		Roles: map[string]config.RoleConfig{
+			"agent":    {MaxPerTxMist: 500_000_000},
+			"operator": {},
+		},
+	}}}
+
+	tests := []struct {
+		token string
+		// wantRule is the rule the 1 SUI transfer breaks, or "" if it passes
+		wantRule string
+	}{
+		{"bot-token", "server.auth.roles.agent.max_per_tx_mist"},
+		{"ops-token", ""},
+	}
+	for _, tc := range tests {
+		t.Run(tc.token, func(t *testing.T) {
+			ts := newAuthTestServer(t, cfg, tc.token, false)
+			ts.exec.Fallback(suitest.Response{Output: suitest.Fixture("dry_run.json")})
+
+			res := ts.call(t, "sui-transfer", transferArgs)
+			submitted := hasCall(ts.exec.Calls(), signedTransfer()...)
+			if tc.wantRule == "" {
+				if res.IsError || !submitted {
+					t.Errorf("transfer was refused: %s", resultText(res))
+				}
+				return
+			}
+			if !res.IsError || !strings.Contains(resultText(res), tc.wantRule) {
+				t.Errorf("result = %q, want a refusal by %s", resultText(res), tc.wantRule)
+			}
+			if submitted {
+				t.Error("refused transfer was submitted")
+			}
+		})
+	}
+}
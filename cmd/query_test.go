@@ -0,0 +1,475 @@
+package cmd
+
+import (
+	"encoding/json"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/krli/go-sui-mcp/internal/config"
+	"github.com/krli/go-sui-mcp/internal/sui/suitest"
+)
+
+// ledgerStart is the timestamp of the first entry of a test ledger; entry i
+// follows a second later
+const ledgerStart = int64(1745923611234)
+
+// ledgerTime is the timestamp of ledger entry i, as the since/until arguments take it
+func ledgerTime(i int) string {
+	return strconv.FormatInt(ledgerStart+int64(i)*1000, 10)
+}
+
+// ledgerDigest is a distinct valid transaction digest for ledger entry i
+func ledgerDigest(i int) string {
+	const alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+	return suitest.TxDigest[:42] + string(alphabet[i/58]) + string(alphabet[i%58])
+}
+
+// ledgerIndex is the entry whose digest is d, or -1
+func ledgerIndex(n int, d string) int {
+	for i := range n {
+		if ledgerDigest(i) == d {
+			return i
+		}
+	}
+	return -1
+}
+
+// ledger pages through n entries, oldest first, the way a fullnode does and
+// records the page size of every request
+type ledger struct {
+	n      int
+	mu     sync.Mutex
+	limits []int
+}
+
+// page returns the entries after the one at cursor (the first page when -1),
+// in the listing order, with the index to continue after
+func (l *ledger) page(cursor, limit int, descending bool) (entries []int, next int, more bool) {
+	l.mu.Lock()
+	l.limits = append(l.limits, limit)
+	l.mu.Unlock()
+
+	order := make([]int, l.n)
+	for i := range order {
+		order[i] = i
+	}
+	if descending {
+		slices.Reverse(order)
+	}
+	start := 0
+	if cursor >= 0 {
+		start = slices.Index(order, cursor) + 1
+	}
+	end := min(start+limit, len(order))
+	entries = order[start:end]
+	if len(entries) == 0 {
+		return entries, -1, false
+	}
+	return entries, entries[len(entries)-1], end < len(order)
+}
+
+func (l *ledger) requested() []int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return slices.Clone(l.limits)
+}
+
+// pageParams reads the cursor-independent params shared by the fullnode query methods
+func pageParams(params []json.RawMessage) (limit int, descending bool) {
+	json.Unmarshal(params[2], &limit)
+	json.Unmarshal(params[3], &descending)
+	return limit, descending
+}
+
+// serveTransactions answers suix_queryTransactionBlocks from a ledger of n transactions
+func serveTransactions(stub *suitest.RPCStub, n int) *ledger {
+	l := &ledger{n: n}
+	stub.OnFunc("suix_queryTransactionBlocks", func(params []json.RawMessage) any {
+		var cursor string
+		json.Unmarshal(params[1], &cursor)
+		limit, descending := pageParams(params)
+		entries, next, more := l.page(ledgerIndex(n, cursor), limit, descending)
+
+		data := []map[string]any{}
+		for _, i := range entries {
+			data = append(data, map[string]any{
+				"digest":      ledgerDigest(i),
+				"timestampMs": ledgerTime(i),
+				"checkpoint":  strconv.Itoa(1000 + i),
+			})
+		}
+		var nextCursor any
+		if next >= 0 {
+			nextCursor = ledgerDigest(next)
+		}
+		return map[string]any{"data": data, "nextCursor": nextCursor, "hasNextPage": more}
+	})
+	return l
+}
+
+// listed returns the ledger indexes of the entries a query result lists
+func listed(t *testing.T, n int, entries any, field string) []int {
+	t.Helper()
+	items, _ := entries.([]any)
+	indexes := []int{}
+	for _, item := range items {
+		digest, _ := item.(map[string]any)[field].(string)
+		indexes = append(indexes, ledgerIndex(n, digest))
+	}
+	return indexes
+}
+
+// span lists the indexes from first to last inclusive, in either direction
+func span(first, last int) []int {
+	var s []int
+	for i := first; ; {
+		s = append(s, i)
+		if i == last {
+			return s
+		}
+		if first < last {
+			i++
+		} else {
+			i--
+		}
+	}
+}
+
+func TestQueryTransactionsPaging(t *testing.T) {
+	tests := []struct {
+		name string
+		// size is the number of transactions on the fullnode
+		size int
+		args map[string]any
+		// want are the ledger indexes listed, in order
+		want []int
+		// wantNext is the ledger index nextCursor names, or -1 when the listing ended
+		wantNext int
+		// wantLimits are the page sizes requested from the fullnode
+		wantLimits []int
+	}{
+		{
+			name:       "no time range fetches one page of limit",
+			size:       120,
+			args:       map[string]any{"limit": 5},
+			want:       span(119, 115),
+			wantNext:   115,
+			wantLimits: []int{5},
+		},
+		{
+			name:       "range found on the second page",
+			size:       120,
+			args:       map[string]any{"limit": 5, "since": ledgerTime(20), "until": ledgerTime(30)},
+			want:       span(29, 25),
+			wantNext:   25,
+			wantLimits: []int{50, 50},
+		},
+		{
+			name:       "range continued from a cursor",
+			size:       120,
+			args:       map[string]any{"limit": 10, "since": ledgerTime(20), "until": ledgerTime(30), "cursor": ledgerDigest(25)},
+			want:       span(24, 20),
+			wantNext:   -1,
+			wantLimits: []int{50},
+		},
+		{
+			name:       "listing stops once it has passed since",
+			size:       120,
+			args:       map[string]any{"limit": 30, "since": ledgerTime(100)},
+			want:       span(119, 100),
+			wantNext:   -1,
+			wantLimits: []int{50},
+		},
+		{
+			name:       "ascending range",
+			size:       120,
+			args:       map[string]any{"limit": 10, "since": ledgerTime(55), "order": "ascending"},
+			want:       span(55, 64),
+			wantNext:   64,
+			wantLimits: []int{50, 50},
+		},
+		{
+			name:       "range filling a page exactly",
+			size:       100,
+			args:       map[string]any{"limit": 50, "until": ledgerTime(50), "order": "ascending"},
+			want:       span(0, 49),
+			wantNext:   49,
+			wantLimits: []int{50},
+		},
+		{
+			name:       "range ending with the listing",
+			size:       30,
+			args:       map[string]any{"limit": 20, "until": ledgerTime(10)},
+			want:       span(9, 0),
+			wantNext:   -1,
+			wantLimits: []int{50},
+		},
+		{
+			name:       "scan stops after the page cap",
+			size:       600,
+			args:       map[string]any{"limit": 5, "until": ledgerTime(5)},
+			want:       []int{},
+			wantNext:   100,
+			wantLimits: slices.Repeat([]int{50}, 10),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ts := newTestServer(t, &config.Config{}, true)
+			l := serveTransactions(ts.rpc, tc.size)
+
+			args := map[string]any{"from-address": suitest.ActiveAddress}
+			for k, v := range tc.args {
+				args[k] = v
+			}
+			res := ts.call(t, "sui-query-transactions", args)
+			if res.IsError {
+				t.Fatalf("sui-query-transactions failed: %s", resultText(res))
+			}
+			result := structured(t, res)
+
+			if got := listed(t, tc.size, result["transactions"], "digest"); !slices.Equal(got, tc.want) {
+				t.Errorf("listed %v, want %v", got, tc.want)
+			}
+			next, _ := result["nextCursor"].(string)
+			more, _ := result["hasNextPage"].(bool)
+			switch {
+			case tc.wantNext < 0 && (more || next != ""):
+				t.Errorf("nextCursor = %q (hasNextPage %v), want the listing to end", next, more)
+			case tc.wantNext >= 0 && (!more || next != ledgerDigest(tc.wantNext)):
+				t.Errorf("nextCursor = %q (hasNextPage %v), want entry %d", next, more, tc.wantNext)
+			}
+			if got := l.requested(); !slices.Equal(got, tc.wantLimits) {
+				t.Errorf("requested pages of %v, want %v", got, tc.wantLimits)
+			}
+		})
+	}
+}
+
+func TestQueryTransactionsArguments(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    map[string]any
+		wantErr string
+	}{
+		{name: "no filter", args: map[string]any{}, wantErr: "give exactly one of"},
+		{name: "two filters", args: map[string]any{"object": suitest.CoinObjectID, "package": "0x2"}, wantErr: "give exactly one of"},
+		{name: "function without module", args: map[string]any{"package": "0x2", "function": "join"}, wantErr: "function needs module"},
+		{name: "module without package", args: map[string]any{"from-address": suitest.ActiveAddress, "module": "coin"}, wantErr: "module and function need package"},
+		{name: "limit above the fullnode page", args: map[string]any{"from-address": suitest.ActiveAddress, "limit": 51}, wantErr: "limit must be a whole number from 1 to 50"},
+		{name: "fractional limit", args: map[string]any{"from-address": suitest.ActiveAddress, "limit": 2.5}, wantErr: "limit must be"},
+		{name: "unknown order", args: map[string]any{"from-address": suitest.ActiveAddress, "order": "newest"}, wantErr: "order must be ascending or descending"},
+		{name: "bad time", args: map[string]any{"from-address": suitest.ActiveAddress, "since": "yesterday"}, wantErr: "since must be an RFC 3339 time"},
+		{name: "empty range", args: map[string]any{"from-address": suitest.ActiveAddress, "since": "2025-02-01", "until": "2025-01-31"}, wantErr: "since must be before until"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ts := newTestServer(t, &config.Config{}, true)
+			l := serveTransactions(ts.rpc, 10)
+
+			text, failed := ts.tryCall("sui-query-transactions", tc.args)
+			if !failed || !strings.Contains(text, tc.wantErr) {
+				t.Errorf("result = %q (failed %v), want an error containing %q", text, failed, tc.wantErr)
+			}
+			if got := l.requested(); len(got) > 0 {
+				t.Errorf("fullnode was queried: %v", got)
+			}
+		})
+	}
+}
+
+// eventSeq is the sequence number of the event of ledger entry i within its transaction
+func eventSeq(i int) string {
+	return strconv.Itoa(i % 3)
+}
+
+// serveEvents answers suix_queryEvents from a ledger of n events, one per
+// transaction, and records the filter of every request
+func serveEvents(stub *suitest.RPCStub, n int) (*ledger, *[]map[string]any) {
+	l := &ledger{n: n}
+	var filters []map[string]any
+	stub.OnFunc("suix_queryEvents", func(params []json.RawMessage) any {
+		var filter map[string]any
+		json.Unmarshal(params[0], &filter)
+		l.mu.Lock()
+		filters = append(filters, filter)
+		l.mu.Unlock()
+
+		var cursor *struct{ TxDigest string }
+		json.Unmarshal(params[1], &cursor)
+		at := -1
+		if cursor != nil {
+			at = ledgerIndex(n, cursor.TxDigest)
+		}
+		limit, descending := pageParams(params)
+		entries, next, more := l.page(at, limit, descending)
+
+		data := []map[string]any{}
+		for _, i := range entries {
+			data = append(data, map[string]any{
+				"id":                map[string]string{"txDigest": ledgerDigest(i), "eventSeq": eventSeq(i)},
+				"packageId":         "0x2",
+				"transactionModule": "market",
+				"sender":            suitest.ActiveAddress,
+				"type":              "0x2::market::Listed",
+				"parsedJson":        map[string]any{"entry": strconv.Itoa(i)},
+				"timestampMs":       ledgerTime(i),
+			})
+		}
+		var nextCursor any
+		if next >= 0 {
+			nextCursor = map[string]string{"txDigest": ledgerDigest(next), "eventSeq": eventSeq(next)}
+		}
+		return map[string]any{"data": data, "nextCursor": nextCursor, "hasNextPage": more}
+	})
+	return l, &filters
+}
+
+// eventCursor is the nextCursor naming the event of ledger entry i
+func eventCursor(i int) string {
+	return ledgerDigest(i) + ":" + eventSeq(i)
+}
+
+func TestQueryEventsPaging(t *testing.T) {
+	tests := []struct {
+		name string
+		args map[string]any
+		// want are the ledger indexes listed, in order
+		want []int
+		// wantNext is the ledger index nextCursor names, or -1 when the listing ended
+		wantNext int
+		// wantLimits are the page sizes requested from the fullnode
+		wantLimits []int
+		// wantFilter is the filter kind sent to the fullnode
+		wantFilter string
+	}{
+		{
+			name:       "sender without a range",
+			args:       map[string]any{"from-address": suitest.ActiveAddress, "limit": 5},
+			want:       span(119, 115),
+			wantNext:   115,
+			wantLimits: []int{5},
+			wantFilter: "Sender",
+		},
+		{
+			name:       "sender with a range found on the second page",
+			args:       map[string]any{"from-address": suitest.ActiveAddress, "limit": 5, "since": ledgerTime(20), "until": ledgerTime(30)},
+			want:       span(29, 25),
+			wantNext:   25,
+			wantLimits: []int{50, 50},
+			wantFilter: "Sender",
+		},
+		{
+			name:       "range continued from a cursor",
+			args:       map[string]any{"event-type": "0x2::market::Listed", "limit": 10, "since": ledgerTime(20), "until": ledgerTime(30), "cursor": eventCursor(25)},
+			want:       span(24, 20),
+			wantNext:   -1,
+			wantLimits: []int{50},
+			wantFilter: "MoveEventType",
+		},
+		{
+			name:       "ascending range",
+			args:       map[string]any{"package": "0x2", "module": "market", "limit": 3, "until": ledgerTime(2), "order": "ascending"},
+			want:       span(0, 1),
+			wantNext:   -1,
+			wantLimits: []int{50},
+			wantFilter: "MoveEventModule",
+		},
+		{
+			// The fullnode applies a range given on its own, so it is asked for limit
+			name:       "range alone",
+			args:       map[string]any{"limit": 4, "since": ledgerTime(100)},
+			want:       span(119, 116),
+			wantNext:   116,
+			wantLimits: []int{4},
+			wantFilter: "TimeRange",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ts := newTestServer(t, &config.Config{}, true)
+			l, filters := serveEvents(ts.rpc, 120)
+
+			res := ts.call(t, "sui-query-events", tc.args)
+			if res.IsError {
+				t.Fatalf("sui-query-events failed: %s", resultText(res))
+			}
+			result := structured(t, res)
+
+			if got := listed(t, 120, result["events"], "txDigest"); !slices.Equal(got, tc.want) {
+				t.Errorf("listed %v, want %v", got, tc.want)
+			}
+			next, _ := result["nextCursor"].(string)
+			more, _ := result["hasNextPage"].(bool)
+			switch {
+			case tc.wantNext < 0 && (more || next != ""):
+				t.Errorf("nextCursor = %q (hasNextPage %v), want the listing to end", next, more)
+			case tc.wantNext >= 0 && (!more || next != eventCursor(tc.wantNext)):
+				t.Errorf("nextCursor = %q (hasNextPage %v), want %s", next, more, eventCursor(tc.wantNext))
+			}
+			if got := l.requested(); !slices.Equal(got, tc.wantLimits) {
+				t.Errorf("requested pages of %v, want %v", got, tc.wantLimits)
+			}
+			for _, filter := range *filters {
+				if _, ok := filter[tc.wantFilter]; !ok || len(filter) != 1 {
+					t.Errorf("queried with filter %v, want a %s filter", filter, tc.wantFilter)
+				}
+			}
+			if tc.wantFilter == "TimeRange" {
+				sent, _ := (*filters)[0]["TimeRange"].(map[string]any)
+				if sent["startTime"] != tc.args["since"] {
+					t.Errorf("TimeRange = %v, want startTime %v", sent, tc.args["since"])
+				}
+			}
+
+			events, _ := result["events"].([]any)
+			if len(events) > 0 {
+				first := events[0].(map[string]any)
+				fields, _ := first["fields"].(map[string]any)
+				if fields["entry"] != strconv.Itoa(tc.want[0]) {
+					t.Errorf("fields = %v, want the decoded parsedJson of entry %d", first["fields"], tc.want[0])
+				}
+			}
+		})
+	}
+}
+
+func TestQueryEventsArguments(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    map[string]any
+		wantErr string
+	}{
+		{name: "nothing to list by", args: map[string]any{}, wantErr: "give one of from-address, tx-digest, event-type, package/module or a time range"},
+		{name: "two filters", args: map[string]any{"from-address": suitest.ActiveAddress, "tx-digest": suitest.TxDigest}, wantErr: "give only one of"},
+		{name: "package without module", args: map[string]any{"package": "0x2"}, wantErr: "package and module must be given together"},
+		{name: "module that is not an identifier", args: map[string]any{"package": "0x2", "module": "a.b"}, wantErr: "is not a Move identifier"},
+		{name: "event type that is not a Move type", args: map[string]any{"event-type": "Listed"}, wantErr: "is not a Move type"},
+		{name: "cursor without a sequence", args: map[string]any{"tx-digest": suitest.TxDigest, "cursor": suitest.TxDigest}, wantErr: "is not a nextCursor"},
+		{name: "cursor with a bad sequence", args: map[string]any{"tx-digest": suitest.TxDigest, "cursor": suitest.TxDigest + ":x"}, wantErr: "is not a nextCursor"},
+		{name: "cursor with a negative sequence", args: map[string]any{"tx-digest": suitest.TxDigest, "cursor": suitest.TxDigest + ":-1"}, wantErr: "is not a nextCursor"},
+		{name: "cursor with a bad digest", args: map[string]any{"tx-digest": suitest.TxDigest, "cursor": "0x12:0"}, wantErr: "is not a nextCursor"},
+		{name: "empty range", args: map[string]any{"since": ledgerTime(5), "until": ledgerTime(5)}, wantErr: "since must be before until"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ts := newTestServer(t, &config.Config{}, true)
+			l, _ := serveEvents(ts.rpc, 10)
+
+			text, failed := ts.tryCall("sui-query-events", tc.args)
+			if !failed || !strings.Contains(text, tc.wantErr) {
+				t.Errorf("result = %q (failed %v), want an error containing %q", text, failed, tc.wantErr)
+			}
+			if got := l.requested(); len(got) > 0 {
+				t.Errorf("fullnode was queried: %v", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/krli/go-sui-mcp/internal/config"
+	"github.com/krli/go-sui-mcp/internal/secrets"
+	"github.com/krli/go-sui-mcp/internal/sui/suitest"
+)
+
+// pastedKey is a private key mistakenly given as an argument
+const pastedKey = "suiprivkey1qzdlfxn2qa2lj5uprl8pyhexs02sg2wrhdy7qaq50cqgnffw4c2477kg9h3"
+
+func TestRefusalsAreRedacted(t *testing.T) {
+	tests := []struct {
+		name string
+		tool string
+		args map[string]any
+	}{
+		{name: "validation", tool: "sui-transfer", args: map[string]any{"to": pastedKey, "object-id": suitest.CoinObjectID}},
+		{name: "validation of a flag", tool: "sui-gas", args: map[string]any{"address": "-" + pastedKey}},
+		{name: "sender", tool: "sui-transfer", args: map[string]any{"to": suitest.OtherAddress, "object-id": suitest.CoinObjectID, "sender": pastedKey}},
+		{name: "handler", tool: "sui-split-coin", args: map[string]any{"coin-id": suitest.CoinObjectID, "amounts": []any{pastedKey}, "dry-run": true}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ts := newTestServer(t, &config.Config{}, false)
+			ts.exec.Fallback(suitest.Response{Output: suitest.Fixture("dry_run.json")})
+
+			text, failed := ts.tryCall(tc.tool, tc.args)
+			if !failed {
+				t.Fatalf("%s accepted a private key as an argument: %s", tc.tool, text)
+			}
+			if strings.Contains(text, pastedKey) || !strings.Contains(text, secrets.Redacted) {
+				t.Errorf("refusal echoes the key unredacted: %s", text)
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/krli/go-sui-mcp/internal/config"
+	"github.com/krli/go-sui-mcp/internal/sui/suitest"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	// packageID is the package of the recorded Move module
+	packageID = "0x5d4b302506645c37ff133b98c4b50a5ae14841659738d6d733d59d0d217a93bf"
+	moduleURI = "sui://package/" + packageID + "/module/marketplace"
+	objectURI = "sui://object/" + suitest.CoinObjectID
+)
+
+// readResource reads uri and returns its text, or the error when the read fails
+func (ts *testServer) readResource(uri string) (text string, failed bool) {
+	req := mcp.ReadResourceRequest{}
+	req.Params.URI = uri
+	res, err := ts.client.ReadResource(context.Background(), req)
+	if err != nil {
+		return err.Error(), true
+	}
+	var parts []string
+	for _, c := range res.Contents {
+		if text, ok := c.(mcp.TextResourceContents); ok {
+			parts = append(parts, text.Text)
+		}
+	}
+	return strings.Join(parts, "\n"), false
+}
+
+// subscribe subscribes to uri and returns the session's subscriptions as listed by sui-subscriptions
+func (ts *testServer) subscribe(t *testing.T, uri string) string {
+	t.Helper()
+	req := mcp.SubscribeRequest{}
+	req.Params.URI = uri
+	if err := ts.client.Subscribe(context.Background(), req); err != nil {
+		t.Fatalf("subscribing to %s: %v", uri, err)
+	}
+	return resultText(ts.call(t, "sui-subscriptions", nil))
+}
+
+func TestReadResources(t *testing.T) {
+	tests := []struct {
+		uri string
+		// want must appear in the resource, or in the error when wantErr is set
+		want    string
+		wantErr bool
+	}{
+		{uri: objectURI, want: suitest.CoinObjectID},
+		{uri: "sui://address/" + suitest.ActiveAddress + "/balances", want: "0x2::sui::SUI"},
+		{uri: "sui://tx/" + suitest.TxDigest, want: suitest.TxDigest},
+		{uri: moduleURI, want: "ItemListed"},
+		{uri: "sui://object/not-an-id", want: "invalid object ID", wantErr: true},
+		{uri: "sui://tx/0xabc", want: "invalid transaction digest", wantErr: true},
+		{uri: "sui://package/" + packageID + "/module/market-place", want: "not a Move identifier", wantErr: true},
+	}
+
+	ts := newTestServer(t, &config.Config{}, true)
+	for _, tc := range tests {
+		t.Run(tc.uri, func(t *testing.T) {
+			text, failed := ts.readResource(tc.uri)
+			if failed != tc.wantErr || !strings.Contains(text, tc.want) {
+				t.Errorf("read = %q (failed %v), want %q (failed %v)", text, failed, tc.want, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestSubscribeResources(t *testing.T) {
+	ts := newTestServer(t, &config.Config{}, true)
+	ts.rpc.On("sui_getLatestCheckpointSequenceNumber", "100")
+
+	if listed := ts.subscribe(t, objectURI); !strings.Contains(listed, suitest.CoinObjectID) {
+		t.Errorf("subscribing to an object is not watched:\n%s", listed)
+	}
+	before := resultText(ts.call(t, "sui-subscriptions", nil))
+	// Modules never change, so there is nothing to watch
+	if listed := ts.subscribe(t, moduleURI); listed != before {
+		t.Errorf("subscribing to a module changed the subscriptions:\n%s", listed)
+	}
+
+	req := mcp.UnsubscribeRequest{}
+	req.Params.URI = objectURI
+	if err := ts.client.Unsubscribe(context.Background(), req); err != nil {
+		t.Fatalf("Unsubscribe: %v", err)
+	}
+	if listed := resultText(ts.call(t, "sui-subscriptions", nil)); strings.Contains(listed, suitest.CoinObjectID) {
+		t.Errorf("object still watched after unsubscribing:\n%s", listed)
+	}
+}
+
+func TestResourceRoles(t *testing.T) {
+	cfg := &config.Config{Server: config.ServerConfig{Auth: config.AuthConfig{
+		Tokens: []config.TokenConfig{
+			{Name: "objects", Token: "objects-token", Role: "objects"},
+			{Name: "packages", Token: "packages-token", Role: "packages"},
+		},
+		Roles: map[string]config.RoleConfig{
+			"objects":  {Include: []string{"sui-object", "sui-subscriptions"}},
+			"packages": {Include: []string{"sui-move-module", "sui-subscriptions"}},
+		},
+	}}}
+	tests := []struct {
+		token string
+		// allowed maps a resource to whether the role may read it
+		allowed map[string]bool
+	}{
+		{token: "objects-token", allowed: map[string]bool{objectURI: true, moduleURI: false}},
+		{token: "packages-token", allowed: map[string]bool{objectURI: false, moduleURI: true}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.token, func(t *testing.T) {
+			ts := newAuthTestServer(t, cfg, tc.token, true)
+			ts.rpc.On("sui_getLatestCheckpointSequenceNumber", "100")
+			for uri, allowed := range tc.allowed {
+				text, failed := ts.readResource(uri)
+				if failed == allowed {
+					t.Errorf("reading %s: %q (failed %v), want allowed %v", uri, text, failed, allowed)
+				}
+				if !allowed && !strings.Contains(text, "not permitted") {
+					t.Errorf("reading %s refused with %q", uri, text)
+				}
+			}
+			// A subscription to a resource outside the role watches nothing
+			if !tc.allowed[objectURI] {
+				if listed := ts.subscribe(t, objectURI); strings.Contains(listed, suitest.CoinObjectID) {
+					t.Errorf("subscribed to an object outside the role:\n%s", listed)
+				}
+			}
+		})
+	}
+}
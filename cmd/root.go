@@ -57,8 +57,13 @@ func initConfig() {
 	// Read environment variables
 	viper.AutomaticEnv()
 
-	// If a config file is found, read it in.
+	// If a config file is found, read it in. A file that exists but cannot be
+	// parsed is fatal rather than silently ignored. Messages go to stderr
+	// because stdout carries the stdio transport.
 	if err := viper.ReadInConfig(); err == nil {
-		fmt.Println("Using config file:", viper.ConfigFileUsed())
+		fmt.Fprintln(os.Stderr, "Using config file:", viper.ConfigFileUsed())
+	} else if _, notFound := err.(viper.ConfigFileNotFoundError); !notFound {
+		fmt.Fprintln(os.Stderr, "Config file error:", err)
+		os.Exit(1)
 	}
 }
@@ -1,21 +1,34 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"os"
+	"os/signal"
+	"syscall"
 
+	"github.com/krli/go-sui-mcp/internal/approval"
+	"github.com/krli/go-sui-mcp/internal/audit"
+	"github.com/krli/go-sui-mcp/internal/auth"
+	"github.com/krli/go-sui-mcp/internal/config"
+	"github.com/krli/go-sui-mcp/internal/policy"
+	"github.com/krli/go-sui-mcp/internal/secrets"
 	"github.com/krli/go-sui-mcp/internal/services"
+	"github.com/krli/go-sui-mcp/internal/subscriptions"
 	"github.com/krli/go-sui-mcp/internal/sui"
+	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 
-	// "github.com/mark3labs/mcp-go/mcp"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
 var (
-	port int
-	sse  bool
+	port      int
+	sse       bool
+	transport string
+	readOnly  bool
 )
 
 // serverCmd represents the server command
@@ -24,7 +37,10 @@ var serverCmd = &cobra.Command{
 	Short: "Start the MCP server",
 	Long:  `Start the Management Control Plane server to handle Sui client operations.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		startServer(port, sse)
+		if sse {
+			viper.Set("server.transport", transportSSE)
+		}
+		startServer()
 	},
 }
 
@@ -33,78 +49,260 @@ func init() {
 
 	// Local flags for the server command
 	serverCmd.Flags().IntVar(&port, "port", 8080, "Port to run the server on")
+	serverCmd.Flags().StringVar(&transport, "transport", transportStdio, "Transport to serve: stdio, sse or http (streamable HTTP)")
 	serverCmd.Flags().BoolVar(&sse, "sse", false, "Enable SSE")
+	serverCmd.Flags().MarkDeprecated("sse", "use --transport sse instead")
+	serverCmd.Flags().BoolVar(&readOnly, "read-only", false, "Expose only query tools; every signing or keystore-mutating tool is refused")
 	viper.BindPFlag("server.port", serverCmd.Flags().Lookup("port"))
-	viper.BindPFlag("server.sse", serverCmd.Flags().Lookup("sse"))
+	viper.BindPFlag("server.transport", serverCmd.Flags().Lookup("transport"))
+	viper.BindPFlag("server.read_only", serverCmd.Flags().Lookup("read-only"))
 }
 
-func registerHandlers(s *server.MCPServer, suiTools *services.SuiTools, suiService *services.SuiService) {
+// registerHandlers adds every tool for which allow returns true
+func registerHandlers(s *server.MCPServer, suiTools *services.SuiTools, suiService *services.SuiService, allow func(name string) bool) {
+	add := func(tool mcp.Tool, handler server.ToolHandlerFunc) {
+		if allow(tool.Name) {
+			s.AddTool(tool, handler)
+		}
+	}
+
 	// Original tools
-	s.AddTool(suiTools.GetFormattedVersion(), suiService.GetFormattedVersion)
-	s.AddTool(suiTools.GetSuiPath(), suiService.GetSuiPath)
-	s.AddTool(suiTools.GetBalanceSummary(), suiService.GetBalanceSummary)
-	s.AddTool(suiTools.GetObjectsSummary(), suiService.GetObjectsSummary)
-	s.AddTool(suiTools.GetObject(), suiService.GetObject)
-	s.AddTool(suiTools.ProcessTransaction(), suiService.ProcessTransaction)
-	s.AddTool(suiTools.PaySUI(), suiService.PaySUI)
+	add(suiTools.GetFormattedVersion(), suiService.GetFormattedVersion)
+	add(suiTools.GetSuiPath(), suiService.GetSuiPath)
+	add(suiTools.GetBalanceSummary(), suiService.GetBalanceSummary)
+	add(suiTools.GetObjectsSummary(), suiService.GetObjectsSummary)
+	add(suiTools.GetObject(), suiService.GetObject)
+	add(suiTools.ResolveName(), suiService.ResolveName)
+	add(suiTools.ProcessTransaction(), suiService.ProcessTransaction)
+	add(suiTools.QueryTransactions(), suiService.QueryTransactions)
+	add(suiTools.QueryEvents(), suiService.QueryEvents)
+	add(suiTools.PaySUI(), suiService.PaySUI)
 
 	// Address and Environment Management
-	s.AddTool(suiTools.GetActiveAddress(), suiService.GetActiveAddress)
-	s.AddTool(suiTools.GetAddresses(), suiService.GetAddresses)
-	s.AddTool(suiTools.GetActiveEnv(), suiService.GetActiveEnv)
-	s.AddTool(suiTools.GetEnvs(), suiService.GetEnvs)
-	s.AddTool(suiTools.GetChainIdentifier(), suiService.GetChainIdentifier)
+	add(suiTools.GetActiveAddress(), suiService.GetActiveAddress)
+	add(suiTools.GetAddresses(), suiService.GetAddresses)
+	add(suiTools.GetActiveEnv(), suiService.GetActiveEnv)
+	add(suiTools.GetEnvs(), suiService.GetEnvs)
+	add(suiTools.GetChainIdentifier(), suiService.GetChainIdentifier)
+	add(suiTools.SwitchAddress(), suiService.SwitchAddress)
+	add(suiTools.SwitchEnv(), suiService.SwitchEnv)
 
 	// Gas Management
-	s.AddTool(suiTools.GetGas(), suiService.GetGas)
-	s.AddTool(suiTools.RequestFromFaucet(), suiService.RequestFromFaucet)
+	add(suiTools.GetGas(), suiService.GetGas)
+	add(suiTools.RequestFromFaucet(), suiService.RequestFromFaucet)
 
 	// Transaction Operations
-	s.AddTool(suiTools.Transfer(), suiService.Transfer)
-	s.AddTool(suiTools.TransferSUI(), suiService.TransferSUI)
-	s.AddTool(suiTools.SplitCoin(), suiService.SplitCoin)
-	s.AddTool(suiTools.MergeCoin(), suiService.MergeCoin)
-	s.AddTool(suiTools.Pay(), suiService.Pay)
-	s.AddTool(suiTools.PayAllSUI(), suiService.PayAllSUI)
+	add(suiTools.Transfer(), suiService.Transfer)
+	add(suiTools.TransferSUI(), suiService.TransferSUI)
+	add(suiTools.SplitCoin(), suiService.SplitCoin)
+	add(suiTools.MergeCoin(), suiService.MergeCoin)
+	add(suiTools.Pay(), suiService.Pay)
+	add(suiTools.PayAllSUI(), suiService.PayAllSUI)
 
 	// Contract Interaction
-	s.AddTool(suiTools.Call(), suiService.Call)
-	s.AddTool(suiTools.Publish(), suiService.Publish)
-	s.AddTool(suiTools.GetDynamicField(), suiService.GetDynamicField)
+	add(suiTools.Call(), suiService.Call)
+	add(suiTools.Publish(), suiService.Publish)
+	add(suiTools.GetDynamicField(), suiService.GetDynamicField)
+	add(suiTools.GetMoveModule(), suiService.GetMoveModule)
 
 	// Move Development
-	s.AddTool(suiTools.MoveBuild(), suiService.MoveBuild)
-	s.AddTool(suiTools.MoveTest(), suiService.MoveTest)
-	s.AddTool(suiTools.MoveNew(), suiService.MoveNew)
+	add(suiTools.MoveBuild(), suiService.MoveBuild)
+	add(suiTools.MoveTest(), suiService.MoveTest)
+	add(suiTools.MoveNew(), suiService.MoveNew)
 
 	// Keytool Management
-	s.AddTool(suiTools.KeytoolList(), suiService.KeytoolList)
-	s.AddTool(suiTools.KeytoolGenerate(), suiService.KeytoolGenerate)
-	s.AddTool(suiTools.KeytoolExport(), suiService.KeytoolExport)
+	add(suiTools.KeytoolList(), suiService.KeytoolList)
+	add(suiTools.KeytoolGenerate(), suiService.KeytoolGenerate)
+	add(suiTools.KeytoolExport(), suiService.KeytoolExport)
+
+	// Subscriptions
+	add(suiTools.Subscribe(), suiService.Subscribe)
+	add(suiTools.Unsubscribe(), suiService.Unsubscribe)
+	add(suiTools.ListSubscriptions(), suiService.ListSubscriptions)
 }
 
-func startServer(port int, sse bool) {
-	// Create a new Sui client
+// registerResources adds every resource template whose tool allow accepts
+func registerResources(s *server.MCPServer, suiTools *services.SuiTools, suiService *services.SuiService, allow func(name string) bool) {
+	add := func(template mcp.ResourceTemplate, handler server.ResourceTemplateHandlerFunc) {
+		if allow(services.ResourceTool(template.URITemplate.Raw())) {
+			s.AddResourceTemplate(template, handler)
+		}
+	}
 
-	// Create a new Sui client
-	suiClient := sui.NewClient()
+	add(suiTools.ObjectResource(), suiService.ReadObject)
+	add(suiTools.BalancesResource(), suiService.ReadBalances)
+	add(suiTools.TransactionResource(), suiService.ReadTransaction)
+	add(suiTools.ModuleResource(), suiService.ReadModule)
+}
+
+// newMCPServer builds the MCP server with every tool backed by the given Sui
+// client. shutdown stops the background work it started and closes the audit
+// log once serving is over.
+func newMCPServer(cfg *config.Config, suiClient *sui.Client) (s *server.MCPServer, shutdown func(), err error) {
+	approver, err := approval.New(cfg.Approval)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	allow, err := services.NewToolFilter(cfg.Tools, cfg.Server.ReadOnly)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid tools config: %w", err)
+	}
+
+	guard, err := services.NewRoleGuard(cfg.Server.Auth.Roles)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var spending *policy.Engine
+	if cfg.Policy.Enabled {
+		if spending, err = policy.New(cfg.Policy); err != nil {
+			return nil, nil, err
+		}
+	} else if hasRoleLimits(cfg.Server.Auth) {
+		// Only the per-role limits apply, which need no persisted totals
+		if spending, err = policy.New(config.PolicyConfig{}); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	secretSink, err := secrets.New(cfg.Secrets)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var auditLog *audit.Logger
+	if cfg.Audit.Enabled {
+		if auditLog, err = audit.Open(cfg.Audit.Path); err != nil {
+			return nil, nil, err
+		}
+	}
 
 	// Create service layer
 	suiService := services.NewSuiService(suiClient)
+	suiService.UseSecretSink(secretSink)
+	var watcher *subscriptions.Manager
+	hooks := &server.Hooks{}
+	// A session's subscriptions end with it
+	hooks.AddOnUnregisterSession(func(ctx context.Context, session server.ClientSession) {
+		watcher.CloseSession(session.SessionID())
+	})
+	// Subscribed objects and balances are watched like sui-subscribe watches
+	// them, on the default env since resource URIs carry none
+	hooks.AddAfterSubscribe(func(ctx context.Context, id any, message *mcp.SubscribeRequest, result *mcp.EmptyResult) {
+		uri := message.Params.URI
+		if !guard.AllowedResource(ctx, uri) {
+			return
+		}
+		ctx, err := services.WithDefaultEnv(ctx, suiClient, cfg.Sui.Env)
+		if err == nil {
+			err = suiService.SubscribeResource(ctx, uri)
+		}
+		if err != nil {
+			log.Printf("cannot watch resource %s: %v", uri, err)
+		}
+	})
+	hooks.AddAfterUnsubscribe(func(ctx context.Context, id any, message *mcp.UnsubscribeRequest, result *mcp.EmptyResult) {
+		suiService.UnsubscribeResource(ctx, message.Params.URI)
+	})
 	suiTools := services.NewSuiTools()
-	s := server.NewMCPServer(
+	opts := []server.ServerOption{
+		// The audit log sees every call exactly as the client does, including policy and approval refusals
+		server.WithToolHandlerMiddleware(services.AuditMiddleware(auditLog)),
+		// Redaction sits just inside the audit log so no tool output, error or refusal from the
+		// middleware below it can carry key material, such as a key pasted in as an address
+		server.WithToolHandlerMiddleware(services.RedactionMiddleware()),
+		server.WithToolHandlerMiddleware(guard.Middleware()),
+		// Arguments are validated and normalized before anything acts on them
+		server.WithToolHandlerMiddleware(services.ValidationMiddleware()),
+		// The env and sender are selected before any middleware that runs a dry run so previews
+		// hit the same network and sign as the same address
+		server.WithToolHandlerMiddleware(services.EnvMiddleware(suiClient, cfg.Sui.Env)),
+		server.WithToolHandlerMiddleware(services.SenderMiddleware(suiClient)),
+		// SuiNS names are resolved on the selected network before the policy checks recipients
+		server.WithToolHandlerMiddleware(services.NameResolutionMiddleware(suiClient)),
+		// The spending policy runs first so a human is never asked to approve a payment it would refuse;
+		// the approval gate runs outside the timeout so that waiting for a human does not count against it.
+		// Their dry runs have a deadline of their own, timeouts.preview
+		server.WithToolHandlerMiddleware(services.PolicyMiddleware(spending, cfg.Timeouts.Preview)),
+		server.WithToolHandlerMiddleware(services.ApprovalMiddleware(approver, cfg.Approval.Timeout, cfg.Timeouts.Preview)),
+		server.WithToolHandlerMiddleware(services.TimeoutMiddleware(cfg.Timeouts)),
+		server.WithToolHandlerMiddleware(services.ResolvedNamesMiddleware()),
+		server.WithToolFilter(guard.FilterTools),
+		server.WithResourceHandlerMiddleware(guard.ResourceMiddleware()),
+		server.WithResourceHandlerMiddleware(services.ResourceEnvMiddleware(suiClient, cfg.Sui.Env)),
+		server.WithResourceCapabilities(true, false),
+		server.WithHooks(hooks),
+		server.WithLogging(),
+	}
+	if cfg.Approval.Mode == approval.ModeElicitation {
+		opts = append(opts, server.WithElicitation())
+	}
+	s = server.NewMCPServer(
 		"SUI MCP",
 		"1.0.0",
+		opts...,
 	)
-	registerHandlers(s, suiTools, suiService)
-	if sse {
-		sseServer := server.NewSSEServer(s, server.WithBaseURL(fmt.Sprintf("http://localhost:%d", port)))
-		if err := sseServer.Start(fmt.Sprintf(":%d", port)); err != nil {
-			log.Fatalf("Server error: %v", err)
+	watcher = subscriptions.New(suiClient, subscriptions.SessionNotifier(s), cfg.Subscriptions)
+	suiService.UseSubscriptions(watcher)
+	registerHandlers(s, suiTools, suiService, allow)
+	registerResources(s, suiTools, suiService, allow)
+	shutdown = func() {
+		watcher.Close()
+		if auditLog != nil {
+			if err := auditLog.Close(); err != nil {
+				log.Printf("audit: %v", err)
+			}
 		}
-	} else {
-		if err := server.ServeStdio(s); err != nil {
-			log.Fatalf("Server error: %v", err)
+	}
+	return s, shutdown, nil
+}
+
+// hasRoleLimits reports whether any auth role carries a payment limit
+func hasRoleLimits(cfg config.AuthConfig) bool {
+	for _, role := range cfg.Roles {
+		if role.MaxPerTxMist > 0 {
+			return true
 		}
 	}
+	return false
+}
+
+func startServer() {
+	cfg, err := loadConfig()
+	if err != nil {
+		log.Fatalf("Config error: %v", err)
+	}
+
+	// Create a new Sui client
+	suiClient, err := sui.NewClient(cfg.Sui)
+	if err != nil {
+		log.Fatalf("Sui client error: %v", err)
+	}
+
+	authenticator, err := auth.New(cfg.Server.Auth)
+	if err != nil {
+		log.Fatalf("Config error: %v", err)
+	}
+
+	s, shutdown, err := newMCPServer(cfg, suiClient)
+	if err != nil {
+		log.Fatalf("Server error: %v", err)
+	}
+
+	// ServeStdio handles these signals itself; the network transports shut down on them
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	switch cfg.Server.Transport {
+	case transportStdio:
+		err = server.ServeStdio(s)
+	case transportSSE, transportHTTP:
+		err = serveNetwork(ctx, s, cfg.Server, authenticator)
+	default:
+		err = fmt.Errorf("unknown transport %q (expected %s, %s or %s)", cfg.Server.Transport, transportStdio, transportSSE, transportHTTP)
+	}
+	stop()
+	shutdown()
+	if err != nil {
+		log.Fatalf("Server error: %v", err)
+	}
 }
@@ -0,0 +1,341 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/krli/go-sui-mcp/internal/config"
+	"github.com/krli/go-sui-mcp/internal/sui/suitest"
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// testServer is an in-process MCP client session against newMCPServer
+type testServer struct {
+	client *client.Client
+	exec   *suitest.FakeExecutor
+	rpc    *suitest.RPCStub
+}
+
+// newTestServer builds the server from cfg over the recorded executor; with
+// withRPC, reads go to the recorded fullnode stub instead of the CLI
+func newTestServer(t *testing.T, cfg *config.Config, withRPC bool) *testServer {
+	t.Helper()
+	ts, s := buildTestServer(t, cfg, withRPC)
+	c, err := client.NewInProcessClient(s)
+	if err != nil {
+		t.Fatalf("NewInProcessClient: %v", err)
+	}
+	ts.connect(t, c)
+	return ts
+}
+
+// buildTestServer builds the server from cfg over the recorded fixtures
+func buildTestServer(t *testing.T, cfg *config.Config, withRPC bool) (*testServer, *server.MCPServer) {
+	t.Helper()
+	ts := &testServer{exec: suitest.NewRecordedExecutor(), rpc: suitest.NewRecordedRPCStub()}
+	suiClient := suitest.NewClient(ts.exec)
+	if withRPC {
+		rpc, srv := suitest.NewRPCClient(ts.rpc)
+		t.Cleanup(srv.Close)
+		suiClient.UseRPC(rpc)
+	}
+
+	s, shutdown, err := newMCPServer(cfg, suiClient)
+	if err != nil {
+		t.Fatalf("newMCPServer: %v", err)
+	}
+	t.Cleanup(shutdown)
+	return ts, s
+}
+
+// connect starts and initializes the client session c
+func (ts *testServer) connect(t *testing.T, c *client.Client) {
+	t.Helper()
+	t.Cleanup(func() { c.Close() })
+	ctx := context.Background()
+	if err := c.Start(ctx); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	init := mcp.InitializeRequest{}
+	init.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	if _, err := c.Initialize(ctx, init); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+	ts.client = c
+}
+
+// call runs a tool and fails the test on a protocol error
+func (ts *testServer) call(t *testing.T, name string, args map[string]any) *mcp.CallToolResult {
+	t.Helper()
+	req := mcp.CallToolRequest{}
+	req.Params.Name = name
+	req.Params.Arguments = args
+	res, err := ts.client.CallTool(context.Background(), req)
+	if err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+	return res
+}
+
+// tryCall runs a tool and returns its text and whether it failed, either as a
+// tool error or as a protocol error from the handler
+func (ts *testServer) tryCall(name string, args map[string]any) (text string, failed bool) {
+	req := mcp.CallToolRequest{}
+	req.Params.Name = name
+	req.Params.Arguments = args
+	res, err := ts.client.CallTool(context.Background(), req)
+	if err != nil {
+		return err.Error(), true
+	}
+	return resultText(res), res.IsError
+}
+
+// resultText joins the text content of a result
+func resultText(res *mcp.CallToolResult) string {
+	var parts []string
+	for _, c := range res.Content {
+		if text, ok := c.(mcp.TextContent); ok {
+			parts = append(parts, text.Text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// structured decodes the structured content of a result
+func structured(t *testing.T, res *mcp.CallToolResult) map[string]any {
+	t.Helper()
+	raw, err := json.Marshal(res.StructuredContent)
+	if err != nil {
+		t.Fatalf("cannot encode structured content: %v", err)
+	}
+	var v map[string]any
+	if err := json.Unmarshal(raw, &v); err != nil {
+		t.Fatalf("structured content is not an object: %s", raw)
+	}
+	return v
+}
+
+// hasCall reports whether the executor ran a sui command starting with prefix
+func hasCall(calls []suitest.Call, prefix ...string) bool {
+	for _, call := range calls {
+		if call.Name == suitest.Executable && len(call.Args) >= len(prefix) && slices.Equal(call.Args[:len(prefix)], prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// toolCase is one call of a registered tool and what it must produce
+type toolCase struct {
+	tool string
+	args map[string]any
+	// rpc serves reads from the fullnode stub
+	rpc bool
+	// reply answers every command that has no recorded fixture
+	reply string
+	// wantText must appear in the text result
+	wantText string
+	// wantField must be a key of the structured result
+	wantField string
+	// wantCall is the prefix of a sui command the tool must run
+	wantCall []string
+}
+
+func TestRegisteredTools(t *testing.T) {
+	txBlock := suitest.Fixture("tx_block.json")
+	tests := []toolCase{
+		{tool: "sui-formatted-version", wantText: "sui 1.47.0", wantCall: []string{"--version"}},
+		{tool: "sui-path", wantText: "/usr/local/bin/sui"},
+		{tool: "sui-balance-summary", wantText: "0x2::sui::SUI", wantField: "balances", wantCall: []string{"client", "balance"}},
+		{tool: "sui-objects-summary", wantField: "objects", wantCall: []string{"client", "objects"}},
+		{tool: "sui-object", args: map[string]any{"objectID": suitest.CoinObjectID}, wantText: suitest.CoinObjectID, wantField: "objectId", wantCall: []string{"client", "object", suitest.CoinObjectID}},
+		{tool: "sui-resolve-name", args: map[string]any{"address": suitest.ActiveAddress}, rpc: true, wantText: "alice.sui"},
+		{tool: "sui-process-transaction", args: map[string]any{"txID": suitest.TxDigest}, wantText: suitest.TxDigest, wantField: "digest", wantCall: []string{"client", "tx-block", suitest.TxDigest}},
+		{tool: "sui-query-transactions", args: map[string]any{"from-address": suitest.ActiveAddress}, rpc: true, wantText: suitest.TxDigest, wantField: "transactions"},
+		{tool: "sui-query-events", args: map[string]any{"tx-digest": suitest.TxDigest}, rpc: true, wantText: "ItemListed", wantField: "events"},
+		{tool: "sui-pay-sui", args: map[string]any{"recipients": []any{suitest.OtherAddress}, "amounts": []any{"1000"}, "input-coins": []any{suitest.CoinObjectID}}, reply: txBlock, wantField: "digest", wantCall: []string{"client", "pay-sui"}},
+		{tool: "sui-active-address", wantText: suitest.ActiveAddress, wantCall: []string{"client", "active-address"}},
+		{tool: "sui-addresses", wantText: suitest.OtherAddress, wantCall: []string{"client", "addresses"}},
+		{tool: "sui-active-env", wantText: "testnet", wantCall: []string{"client", "active-env"}},
+		{tool: "sui-envs", wantText: "fullnode.devnet.sui.io", wantCall: []string{"client", "envs"}},
+		{tool: "sui-chain-identifier", wantText: "4c78adac", wantCall: []string{"client", "chain-identifier"}},
+		{tool: "sui-switch-address", args: map[string]any{"address": suitest.OtherAddress}, reply: "Active address switched to " + suitest.OtherAddress, wantText: "switched", wantCall: []string{"client", "switch", "--address", suitest.OtherAddress}},
+		{tool: "sui-switch-env", args: map[string]any{"env": "devnet"}, reply: "Active environment switched to [devnet]", wantText: "devnet", wantCall: []string{"client", "switch", "--env", "devnet"}},
+		{tool: "sui-gas", wantField: "gasCoins", wantCall: []string{"client", "gas"}},
+		{tool: "sui-faucet", reply: "Request successful. It can take up to 1 minute to get the coin.", wantText: "Request successful", wantCall: []string{"client", "faucet"}},
+		{tool: "sui-transfer", args: map[string]any{"to": suitest.OtherAddress, "object-id": suitest.CoinObjectID}, reply: txBlock, wantField: "digest", wantCall: []string{"client", "transfer", "--to", suitest.OtherAddress}},
+		{tool: "sui-transfer-sui", args: map[string]any{"to": suitest.OtherAddress, "sui-coin-object-id": suitest.CoinObjectID, "amount": "1000"}, reply: txBlock, wantField: "digest", wantCall: []string{"client", "transfer-sui", "--to", suitest.OtherAddress}},
+		{tool: "sui-split-coin", args: map[string]any{"coin-id": suitest.CoinObjectID, "amounts": []any{"1000"}}, reply: txBlock, wantField: "digest", wantCall: []string{"client", "split-coin", "--coin-id", suitest.CoinObjectID}},
+		{tool: "sui-merge-coin", args: map[string]any{"primary-coin": suitest.CoinObjectID, "coin-to-merge": suitest.SmallCoinObjectID}, reply: txBlock, wantField: "digest", wantCall: []string{"client", "merge-coin", "--primary-coin", suitest.CoinObjectID, "--coin-to-merge", suitest.SmallCoinObjectID}},
+		{tool: "sui-pay", args: map[string]any{"input-coins": []any{suitest.CoinObjectID}, "recipients": []any{suitest.OtherAddress}, "amounts": []any{"1000"}}, reply: txBlock, wantField: "digest", wantCall: []string{"client", "pay"}},
+		{tool: "sui-pay-all-sui", args: map[string]any{"input-coins": []any{suitest.CoinObjectID}, "recipient": suitest.OtherAddress}, reply: txBlock, wantField: "digest", wantCall: []string{"client", "pay-all-sui"}},
+		{tool: "sui-call", args: map[string]any{"package": "0x2", "module": "coin", "function": "join"}, reply: txBlock, wantField: "digest", wantCall: []string{"client", "call"}},
+		{tool: "sui-publish", args: map[string]any{"package-path": "./move/pkg"}, reply: txBlock, wantField: "digest", wantCall: []string{"client", "publish"}},
+		{tool: "sui-dynamic-field", args: map[string]any{"parent-object-id": suitest.CoinObjectID}, reply: `{"data": [], "hasNextPage": false}`, wantText: "hasNextPage", wantCall: []string{"client", "dynamic-field", suitest.CoinObjectID}},
+		{tool: "sui-move-module", args: map[string]any{"package-id": "0x5d4b302506645c37ff133b98c4b50a5ae14841659738d6d733d59d0d217a93bf", "module": "marketplace"}, rpc: true, wantText: "ItemListed"},
+		{tool: "sui-move-build", args: map[string]any{"package-path": "./move/pkg"}, reply: "BUILDING pkg", wantText: "BUILDING pkg", wantCall: []string{"move", "build"}},
+		{tool: "sui-move-test", args: map[string]any{"package-path": "./move/pkg"}, reply: "Test result: OK. Total tests: 3; passed: 3; failed: 0", wantText: "Test result: OK", wantCall: []string{"move", "test"}},
+		{tool: "sui-move-new", args: map[string]any{"name": "pkg"}, reply: "", wantCall: []string{"move", "new", "pkg"}},
+		{tool: "sui-keytool-list", wantText: "sharp-chrysoberyl", wantCall: []string{"keytool", "list"}},
+		{tool: "sui-keytool-generate", args: map[string]any{"key-scheme": "ed25519"}, reply: "Keys saved as Base64", wantText: "stored at", wantCall: []string{"keytool", "generate", "ed25519"}},
+		{tool: "sui-keytool-export", args: map[string]any{"address": suitest.ActiveAddress}, reply: "suiprivkey1qqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqq", wantText: "stored at", wantCall: []string{"keytool", "export", suitest.ActiveAddress}},
+		{tool: "sui-subscribe", args: map[string]any{"kind": "address", "address": suitest.ActiveAddress}, rpc: true, wantText: "Subscribed as sub-1", wantField: "subscriptions"},
+		{tool: "sui-unsubscribe", args: map[string]any{"all": true}, wantText: "Stopped 0 subscriptions", wantField: "subscriptions"},
+		{tool: "sui-subscriptions", wantText: "- none", wantField: "subscriptions"},
+	}
+
+	cfg := &config.Config{}
+	cfg.Secrets = config.SecretsConfig{Enabled: true, Sink: "file", Dir: t.TempDir()}
+
+	// Every registered tool has a case
+	ts := newTestServer(t, cfg, false)
+	listed, err := ts.client.ListTools(context.Background(), mcp.ListToolsRequest{})
+	if err != nil {
+		t.Fatalf("ListTools: %v", err)
+	}
+	for _, tool := range listed.Tools {
+		if !slices.ContainsFunc(tests, func(tc toolCase) bool { return tc.tool == tool.Name }) {
+			t.Errorf("registered tool %s has no test case", tool.Name)
+		}
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.tool, func(t *testing.T) {
+			ts := newTestServer(t, cfg, tc.rpc)
+			ts.exec.Fallback(suitest.Response{Output: tc.reply})
+			ts.rpc.On("suix_resolveNameServiceNames", map[string]any{"data": []string{"alice.sui"}, "hasNextPage": false})
+			ts.rpc.On("sui_getLatestCheckpointSequenceNumber", "100")
+
+			res := ts.call(t, tc.tool, tc.args)
+			text := resultText(res)
+			if res.IsError {
+				t.Fatalf("%s failed: %s", tc.tool, text)
+			}
+			if !strings.Contains(text, tc.wantText) {
+				t.Errorf("%s text result does not contain %q:\n%s", tc.tool, tc.wantText, text)
+			}
+			if tc.wantField != "" {
+				if _, ok := structured(t, res)[tc.wantField]; !ok {
+					t.Errorf("%s structured result has no %s: %v", tc.tool, tc.wantField, res.StructuredContent)
+				}
+			}
+			if tc.wantCall != nil && !hasCall(ts.exec.Calls(), tc.wantCall...) {
+				t.Errorf("%s did not run sui %s; ran %v", tc.tool, strings.Join(tc.wantCall, " "), ts.exec.Calls())
+			}
+		})
+	}
+}
+
+func TestFakeExecutorKeysOnProgram(t *testing.T) {
+	exec := suitest.NewRecordedExecutor()
+	// "which sui" and "sui sui" have the same arguments but are different commands
+	if _, err := exec.Execute(context.Background(), suitest.Executable, suitest.Executable); err == nil {
+		t.Error("sui sui answered with the fixture of which sui")
+	}
+	out, err := exec.Execute(context.Background(), "which", suitest.Executable)
+	if err != nil || !strings.Contains(out, "/usr/local/bin/sui") {
+		t.Errorf("which sui = %q, %v", out, err)
+	}
+}
+
+func TestDryRun(t *testing.T) {
+	tests := []struct {
+		tool string
+		args map[string]any
+	}{
+		{"sui-transfer", map[string]any{"to": suitest.OtherAddress, "object-id": suitest.CoinObjectID}},
+		{"sui-transfer-sui", map[string]any{"to": suitest.OtherAddress, "sui-coin-object-id": suitest.CoinObjectID, "amount": "1000"}},
+		{"sui-split-coin", map[string]any{"coin-id": suitest.CoinObjectID, "amounts": []any{"1000"}}},
+		{"sui-merge-coin", map[string]any{"primary-coin": suitest.CoinObjectID, "coin-to-merge": suitest.SmallCoinObjectID}},
+		{"sui-pay", map[string]any{"input-coins": []any{suitest.CoinObjectID}, "recipients": []any{suitest.OtherAddress}, "amounts": []any{"1000"}}},
+		{"sui-pay-sui", map[string]any{"input-coins": []any{suitest.CoinObjectID}, "recipients": []any{suitest.OtherAddress}, "amounts": []any{"1000"}}},
+		{"sui-pay-all-sui", map[string]any{"input-coins": []any{suitest.CoinObjectID}, "recipient": suitest.OtherAddress}},
+		{"sui-call", map[string]any{"package": "0x2", "module": "coin", "function": "join"}},
+		{"sui-publish", map[string]any{"package-path": "./move/pkg"}},
+	}
+
+	for _, backend := range []string{"cli", "rpc"} {
+		for _, tc := range tests {
+			t.Run(backend+"/"+tc.tool, func(t *testing.T) {
+				ts := newTestServer(t, &config.Config{}, backend == "rpc")
+				reply := suitest.Fixture("dry_run.json")
+				if backend == "rpc" {
+					// The CLI only builds the transaction; the fullnode simulates it
+					reply = "Raw tx_bytes to execute:\nAAACAAgA4fUFAAAAAAAg"
+				}
+				ts.exec.Fallback(suitest.Response{Output: reply})
+
+				args := maps.Clone(tc.args)
+				args["dry-run"] = true
+				res := ts.call(t, tc.tool, args)
+				if res.IsError {
+					t.Fatalf("%s failed: %s", tc.tool, resultText(res))
+				}
+				if dryRun, _ := structured(t, res)["dryRun"].(bool); !dryRun {
+					t.Errorf("%s result is not marked dryRun: %v", tc.tool, res.StructuredContent)
+				}
+				if !strings.Contains(resultText(res), "dry run") && !strings.Contains(resultText(res), "Dry run") {
+					t.Errorf("%s text does not say it was a dry run:\n%s", tc.tool, resultText(res))
+				}
+
+				flag := "--dry-run"
+				if backend == "rpc" {
+					flag = "--serialize-unsigned-transaction"
+				}
+				calls := ts.exec.Calls()
+				last := calls[len(calls)-1]
+				if !slices.Contains(last.Args, flag) {
+					t.Errorf("%s ran %v, want %s", tc.tool, last.Args, flag)
+				}
+				if backend == "rpc" && !slices.ContainsFunc(ts.rpc.Requests(), func(r suitest.RPCRequest) bool { return r.Method == "sui_dryRunTransactionBlock" }) {
+					t.Errorf("%s did not call sui_dryRunTransactionBlock", tc.tool)
+				}
+			})
+		}
+	}
+}
+
+func TestObjectResultIsStable(t *testing.T) {
+	var obj map[string]any
+	if err := json.Unmarshal([]byte(suitest.Fixture("object.json")), &obj); err != nil {
+		t.Fatal(err)
+	}
+	fields := obj["content"].(map[string]any)["fields"].(map[string]any)
+	for _, name := range strings.Fields("owner name url price kind level expires") {
+		fields[name] = name + "-value"
+	}
+	raw, err := json.Marshal(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := newTestServer(t, &config.Config{}, false)
+	ts.exec.On(string(raw), "client", "object", suitest.CoinObjectID, "--json")
+
+	var first string
+	for i := range 10 {
+		text := resultText(ts.call(t, "sui-object", map[string]any{"objectID": suitest.CoinObjectID}))
+		if i == 0 {
+			first = text
+			continue
+		}
+		if text != first {
+			t.Fatalf("call %d rendered\n%s\nafter\n%s", i, text, first)
+		}
+	}
+
+	_, listing, _ := strings.Cut(first, "Fields:\n")
+	var names []string
+	for line := range strings.Lines(listing) {
+		name, _, _ := strings.Cut(strings.TrimSpace(line), ":")
+		names = append(names, name)
+	}
+	if !slices.IsSorted(names) || len(names) != len(fields) {
+		t.Errorf("fields listed as %v, want all %d in name order", names, len(fields))
+	}
+}
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/krli/go-sui-mcp/internal/config"
+	"github.com/krli/go-sui-mcp/internal/sui/suitest"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestToolTimeouts(t *testing.T) {
+	const limit = 50 * time.Millisecond
+	approver, asked := approvalEndpoint(t, http.StatusOK, `{"approved": true}`)
+
+	tests := []struct {
+		name string
+		cfg  config.Config
+		tool string
+		args map[string]any
+		// argv is the command that hangs
+		argv []string
+		// wantErr must appear in the error
+		wantErr string
+	}{
+		{
+			name:    "per-tool timeout",
+			cfg:     config.Config{Timeouts: config.TimeoutConfig{Default: time.Hour, Tools: map[string]time.Duration{"sui-gas": limit}}},
+			tool:    "sui-gas",
+			argv:    []string{"client", "gas", "--json"},
+			wantErr: "sui-gas timed out after 50ms",
+		},
+		{
+			name:    "default timeout",
+			cfg:     config.Config{Timeouts: config.TimeoutConfig{Default: limit, Tools: map[string]time.Duration{"sui-gas": time.Hour}}},
+			tool:    "sui-active-address",
+			argv:    []string{"client", "active-address"},
+			wantErr: "sui-active-address timed out after 50ms",
+		},
+		{
+			name: "approval preview",
+			cfg: config.Config{
+				Timeouts: config.TimeoutConfig{Preview: limit},
+				Approval: config.ApprovalConfig{Mode: "http", URL: approver.URL},
+			},
+			tool:    "sui-transfer",
+			args:    transferArgs,
+			argv:    dryRunTransfer(),
+			wantErr: "sui-transfer dry run failed, not asking for approval: timed out after 50ms",
+		},
+		{
+			name: "policy preview",
+			cfg: config.Config{
+				Timeouts: config.TimeoutConfig{Preview: limit},
+				Policy:   config.PolicyConfig{Enabled: true},
+			},
+			tool:    "sui-transfer",
+			args:    transferArgs,
+			argv:    dryRunTransfer(),
+			wantErr: "sui-transfer dry run failed, cannot check spending policy: timed out after 50ms",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ts := newTestServer(t, &tc.cfg, false)
+			killed := make(chan error, 1)
+			ts.exec.OnResponse(suitest.Response{Block: make(chan struct{}), Killed: killed}, tc.argv...)
+
+			text, failed := ts.tryCall(tc.tool, tc.args)
+			if !failed || !strings.Contains(text, tc.wantErr) {
+				t.Errorf("result = %q (failed %v), want an error containing %q", text, failed, tc.wantErr)
+			}
+			select {
+			case err := <-killed:
+				if !errors.Is(err, context.DeadlineExceeded) {
+					t.Errorf("command stopped with %v, want a deadline", err)
+				}
+			default:
+				t.Error("the hung command was not killed")
+			}
+			if hasCall(ts.exec.Calls(), signedTransfer()...) {
+				t.Error("the transfer was signed after its preview timed out")
+			}
+			if len(*asked) > 0 {
+				t.Errorf("approver was asked about a preview that timed out: %v", *asked)
+			}
+		})
+	}
+}
+
+func TestCancellationKillsCommand(t *testing.T) {
+	ts := newTestServer(t, &config.Config{}, false)
+	killed := make(chan error, 1)
+	ts.exec.OnResponse(suitest.Response{Block: make(chan struct{}), Killed: killed}, "client", "gas", "--json")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		req := mcp.CallToolRequest{}
+		req.Params.Name = "sui-gas"
+		ts.client.CallTool(ctx, req)
+	}()
+	for deadline := time.Now().Add(5 * time.Second); !hasCall(ts.exec.Calls(), "client", "gas"); {
+		if time.Now().After(deadline) {
+			t.Fatal("sui client gas never ran")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	cancel()
+	select {
+	case err := <-killed:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("command stopped with %v, want cancellation", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("cancelling the call did not kill the command")
+	}
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/krli/go-sui-mcp/internal/config"
+	"github.com/krli/go-sui-mcp/internal/services"
+	"github.com/krli/go-sui-mcp/internal/sui/suitest"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// listedTools returns the names of the tools the server lists, sorted
+func listedTools(t *testing.T, ts *testServer) []string {
+	t.Helper()
+	listed, err := ts.client.ListTools(context.Background(), mcp.ListToolsRequest{})
+	if err != nil {
+		t.Fatalf("ListTools: %v", err)
+	}
+	var names []string
+	for _, tool := range listed.Tools {
+		names = append(names, tool.Name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// changesState reports whether a tool signs a transaction, spends from the
+// faucet, changes the keystore or client config, or writes files
+func changesState(name string) bool {
+	switch services.ToolGroup(name) {
+	case services.GroupPayments, services.GroupContracts, services.GroupKeytool, services.GroupClient:
+		return true
+	}
+	return services.IsSigningTool(name) || name == "sui-move-new"
+}
+
+func TestReadOnlyMode(t *testing.T) {
+	all := listedTools(t, newTestServer(t, &config.Config{}, false))
+	ro := newTestServer(t, &config.Config{Server: config.ServerConfig{ReadOnly: true}}, false)
+	listed := listedTools(t, ro)
+
+	var want []string
+	for _, name := range all {
+		if !changesState(name) {
+			want = append(want, name)
+		}
+	}
+	if !slices.Equal(listed, want) {
+		t.Errorf("read-only mode lists %v, want exactly the tools that change nothing: %v", listed, want)
+	}
+	for _, name := range []string{"sui-transfer", "sui-pay-sui", "sui-call", "sui-publish", "sui-switch-address", "sui-switch-env", "sui-keytool-generate", "sui-keytool-export", "sui-faucet", "sui-move-new"} {
+		if slices.Contains(listed, name) {
+			t.Errorf("read-only mode lists %s", name)
+		}
+	}
+
+	ro.exec.Fallback(suitest.Response{Output: suitest.Fixture("dry_run.json")})
+	if _, failed := ro.tryCall("sui-switch-address", map[string]any{"address": suitest.OtherAddress}); !failed {
+		t.Error("sui-switch-address ran in read-only mode")
+	}
+	if len(ro.exec.Calls()) > 0 {
+		t.Errorf("sui ran in read-only mode: %v", ro.exec.Calls())
+	}
+}
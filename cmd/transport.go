@@ -0,0 +1,227 @@
+package cmd
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/krli/go-sui-mcp/internal/auth"
+	"github.com/krli/go-sui-mcp/internal/config"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Transports accepted by --transport and server.transport
+const (
+	transportStdio = "stdio"
+	// transportSSE is the legacy HTTP+SSE transport
+	transportSSE = "sse"
+	// transportHTTP is the MCP streamable HTTP transport
+	transportHTTP = "http"
+)
+
+// shutdownTimeout bounds how long a network transport waits for open requests when it stops
+const shutdownTimeout = 10 * time.Second
+
+// serveNetwork serves s over the SSE or streamable HTTP transport on the
+// configured host and port, behind TLS and token auth when configured, until
+// ctx is done
+func serveNetwork(ctx context.Context, s *server.MCPServer, cfg config.ServerConfig, authenticator *auth.Authenticator) error {
+	handler, baseURL, err := newNetworkHandler(s, cfg, authenticator)
+	if err != nil {
+		return err
+	}
+	tlsConfig, err := newTLSConfig(cfg.TLS)
+	if err != nil {
+		return err
+	}
+
+	httpServer := &http.Server{
+		Addr:      net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port)),
+		Handler:   handler,
+		TLSConfig: tlsConfig,
+	}
+	shutDown := make(chan struct{})
+	stopServing := context.AfterFunc(ctx, func() {
+		defer close(shutDown)
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := httpServer.Shutdown(shutdownCtx); err != nil {
+			log.Printf("Server shutdown: %v", err)
+		}
+	})
+	defer stopServing()
+
+	log.Printf("Serving MCP over %s on %s (external URL %s)", cfg.Transport, httpServer.Addr, baseURL)
+	if tlsConfig != nil {
+		err = httpServer.ListenAndServeTLS(cfg.TLS.CertFile, cfg.TLS.KeyFile)
+	} else {
+		err = httpServer.ListenAndServe()
+	}
+	if errors.Is(err, http.ErrServerClosed) {
+		// Serving stops as Shutdown starts; wait for it to drain the open requests
+		<-shutDown
+		return nil
+	}
+	return err
+}
+
+// newNetworkHandler builds the HTTP handler of the configured network transport
+// and returns it with the external URL it is served at. Without tokens or client
+// certificates it only serves a loopback host, unless
+// server.auth.allow_unauthenticated says otherwise.
+func newNetworkHandler(s *server.MCPServer, cfg config.ServerConfig, authenticator *auth.Authenticator) (http.Handler, *url.URL, error) {
+	if authenticator == nil && cfg.TLS.ClientCAFile == "" && !isLoopbackHost(cfg.Host) && !cfg.Auth.AllowUnauthenticated {
+		return nil, nil, fmt.Errorf("refusing to serve %s on %q without server.auth.tokens: anyone who can reach it could use every tool; "+
+			"configure tokens, bind server.host to 127.0.0.1, or set server.auth.allow_unauthenticated", cfg.Transport, cfg.Host)
+	}
+	baseURL, err := externalBaseURL(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// mcpMux carries the MCP endpoints, which sit behind token auth
+	mcpMux := http.NewServeMux()
+	switch cfg.Transport {
+	case transportSSE:
+		sseOpts := []server.SSEOption{
+			// Clients are told to post messages under the external URL, which may carry a proxy path prefix
+			server.WithBaseURL(strings.TrimSuffix(baseURL.String(), "/")),
+			server.WithSSEDisableLocalhostProtection(true),
+		}
+		if authenticator != nil {
+			sseOpts = append(sseOpts, server.WithSSEContextFunc(authenticator.ContextFunc))
+		}
+		sseServer := server.NewSSEServer(s, sseOpts...)
+		// Mounted explicitly so a proxy that strips the base URL's path prefix still reaches them
+		mcpMux.Handle("/sse", sseServer.SSEHandler())
+		mcpMux.Handle("/message", sseServer.MessageHandler())
+	case transportHTTP:
+		httpOpts := []server.StreamableHTTPOption{
+			// Messages on each session's streams are kept so a client can resume with Last-Event-ID
+			server.WithEventStore(server.NewInMemoryEventStore()),
+			server.WithHeartbeatInterval(cfg.HTTP.HeartbeatInterval),
+			server.WithSessionIdleTTL(cfg.HTTP.SessionIdleTTL),
+			server.WithDisableLocalhostProtection(true),
+		}
+		if authenticator != nil {
+			httpOpts = append(httpOpts, server.WithHTTPContextFunc(authenticator.ContextFunc))
+		}
+		endpoint := "/" + strings.Trim(cfg.HTTP.EndpointPath, "/")
+		if endpoint == "/" {
+			endpoint = "/mcp"
+		}
+		mcpMux.Handle(endpoint, server.NewStreamableHTTPServer(s, httpOpts...))
+	}
+
+	mux := http.NewServeMux()
+	// Lets load balancers probe the server without a token or an MCP session
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintln(w, "ok")
+	})
+	if authenticator != nil {
+		mux.Handle("/", authenticator.Middleware(mcpMux))
+	} else {
+		mux.Handle("/", mcpMux)
+		log.Printf("WARNING: no server.auth.tokens configured, anyone who can reach %s can use every tool", baseURL)
+	}
+
+	return rejectForeignHosts(mux, baseURL.Hostname()), baseURL, nil
+}
+
+// externalBaseURL is the URL clients reach the server at: server.base_url when
+// set (e.g. behind a reverse proxy), otherwise derived from host, port and TLS
+func externalBaseURL(cfg config.ServerConfig) (*url.URL, error) {
+	if cfg.BaseURL != "" {
+		u, err := url.Parse(cfg.BaseURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.RawQuery != "" {
+			return nil, fmt.Errorf("server.base_url %q must be an absolute http(s) URL without a query", cfg.BaseURL)
+		}
+		return u, nil
+	}
+
+	scheme := "http"
+	if cfg.TLS.CertFile != "" {
+		scheme = "https"
+	}
+	host := cfg.Host
+	if ip := net.ParseIP(host); host == "" || (ip != nil && ip.IsUnspecified()) {
+		host = "localhost"
+	}
+	return &url.URL{Scheme: scheme, Host: net.JoinHostPort(host, strconv.Itoa(cfg.Port))}, nil
+}
+
+// newTLSConfig builds the server TLS settings; it returns nil when TLS is off.
+// Setting a client CA turns on mutual TLS: clients must present a certificate it signed.
+func newTLSConfig(cfg config.TLSConfig) (*tls.Config, error) {
+	if cfg.CertFile == "" && cfg.KeyFile == "" {
+		if cfg.ClientCAFile != "" {
+			return nil, errors.New("server.tls.client_ca_file requires server.tls.cert_file and key_file")
+		}
+		return nil, nil
+	}
+	if cfg.CertFile == "" || cfg.KeyFile == "" {
+		return nil, errors.New("server.tls.cert_file and server.tls.key_file must be set together")
+	}
+	if _, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile); err != nil {
+		return nil, fmt.Errorf("invalid TLS certificate: %w", err)
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if cfg.ClientCAFile != "" {
+		pem, err := os.ReadFile(cfg.ClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read client CA: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", cfg.ClientCAFile)
+		}
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return tlsConfig, nil
+}
+
+// rejectForeignHosts guards against DNS rebinding: a request that arrived on a
+// loopback interface must name localhost or the external host in its Host header.
+// It replaces mcp-go's built-in check, which would also refuse a reverse proxy
+// on the same machine that forwards the public host name.
+func rejectForeignHosts(next http.Handler, externalHost string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		localAddr, ok := r.Context().Value(http.LocalAddrContextKey).(net.Addr)
+		if ok && isLoopbackHost(localAddr.String()) && !isLoopbackHost(r.Host) && !strings.EqualFold(hostOnly(r.Host), externalHost) {
+			http.Error(w, fmt.Sprintf("Forbidden: invalid Host header %q", r.Host), http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isLoopbackHost reports whether a host or host:port names the local machine
+func isLoopbackHost(hostport string) bool {
+	host := hostOnly(hostport)
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// hostOnly strips the port and IPv6 brackets from a host header value
+func hostOnly(hostport string) string {
+	if host, _, err := net.SplitHostPort(hostport); err == nil {
+		return host
+	}
+	return strings.Trim(hostport, "[]")
+}
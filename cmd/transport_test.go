@@ -0,0 +1,490 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/krli/go-sui-mcp/internal/auth"
+	"github.com/krli/go-sui-mcp/internal/config"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func TestServeNetworkStopsWithContext(t *testing.T) {
+	for _, transport := range []string{transportSSE, transportHTTP} {
+		t.Run(transport, func(t *testing.T) {
+			_, s := buildTestServer(t, &config.Config{}, false)
+			ctx, cancel := context.WithCancel(context.Background())
+			served := make(chan error, 1)
+			go func() {
+				served <- serveNetwork(ctx, s, config.ServerConfig{Transport: transport, Host: "127.0.0.1"}, nil)
+			}()
+
+			time.Sleep(50 * time.Millisecond)
+			cancel()
+			select {
+			case err := <-served:
+				if err != nil {
+					t.Errorf("serveNetwork = %v, want nil after shutdown", err)
+				}
+			case <-time.After(shutdownTimeout + 5*time.Second):
+				t.Fatal("serveNetwork did not return after its context was cancelled")
+			}
+		})
+	}
+}
+
+func TestNetworkRequiresAuthOffLoopback(t *testing.T) {
+	authCfg := config.AuthConfig{
+		Tokens: []config.TokenConfig{{Name: "ci", Token: "ci-token", Role: "reader"}},
+		Roles:  map[string]config.RoleConfig{"reader": {}},
+	}
+	tests := []struct {
+		name    string
+		cfg     config.ServerConfig
+		wantErr bool
+	}{
+		{name: "all interfaces", cfg: config.ServerConfig{Host: "0.0.0.0"}, wantErr: true},
+		{name: "empty host", cfg: config.ServerConfig{Host: ""}, wantErr: true},
+		{name: "public address", cfg: config.ServerConfig{Host: "192.0.2.10"}, wantErr: true},
+		{name: "IPv4 loopback", cfg: config.ServerConfig{Host: "127.0.0.1"}},
+		{name: "IPv6 loopback", cfg: config.ServerConfig{Host: "::1"}},
+		{name: "localhost", cfg: config.ServerConfig{Host: "localhost"}},
+		{name: "tokens", cfg: config.ServerConfig{Host: "0.0.0.0", Auth: authCfg}},
+		{name: "explicit opt-out", cfg: config.ServerConfig{Host: "0.0.0.0", Auth: config.AuthConfig{AllowUnauthenticated: true}}},
+	}
+	for _, transport := range []string{transportSSE, transportHTTP} {
+		for _, tc := range tests {
+			t.Run(transport+"/"+tc.name, func(t *testing.T) {
+				_, s := buildTestServer(t, &config.Config{}, false)
+				authenticator, err := auth.New(tc.cfg.Auth)
+				if err != nil {
+					t.Fatal(err)
+				}
+				tc.cfg.Transport = transport
+				_, _, err = newNetworkHandler(s, tc.cfg, authenticator)
+				if gotErr := err != nil; gotErr != tc.wantErr {
+					t.Errorf("newNetworkHandler error = %v, want error %v", err, tc.wantErr)
+				}
+				if err != nil && !strings.Contains(err.Error(), "server.auth.tokens") {
+					t.Errorf("error %q does not name server.auth.tokens", err)
+				}
+			})
+		}
+	}
+}
+
+func TestNetworkRejectsBadTokens(t *testing.T) {
+	cfg := config.ServerConfig{
+		Host: "127.0.0.1",
+		Auth: config.AuthConfig{
+			Tokens: []config.TokenConfig{{Name: "ci", Token: "ci-token", Role: "reader"}},
+			Roles:  map[string]config.RoleConfig{"reader": {}},
+		},
+	}
+	authenticator, err := auth.New(cfg.Auth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	initialize := `{"jsonrpc": "2.0", "id": 1, "method": "initialize", "params": {"protocolVersion": "2025-06-18", "capabilities": {}, "clientInfo": {"name": "test", "version": "1"}}}`
+
+	tests := []struct {
+		transport string
+		method    string
+		path      string
+		body      string
+	}{
+		{transport: transportSSE, method: http.MethodGet, path: "/sse"},
+		{transport: transportSSE, method: http.MethodPost, path: "/message?sessionId=abc", body: initialize},
+		{transport: transportHTTP, method: http.MethodPost, path: "/mcp", body: initialize},
+		{transport: transportHTTP, method: http.MethodGet, path: "/mcp"},
+	}
+	for _, tc := range tests {
+		_, s := buildTestServer(t, &config.Config{}, false)
+		cfg.Transport = tc.transport
+		handler, _, err := newNetworkHandler(s, cfg, authenticator)
+		if err != nil {
+			t.Fatal(err)
+		}
+		srv := httptest.NewServer(handler)
+		t.Cleanup(srv.Close)
+
+		for _, header := range []map[string]string{
+			{},
+			{"Authorization": "Bearer wrong-token"},
+			{"X-API-Key": "wrong-token"},
+			{"Authorization": "ci-token"},
+			{"Authorization": "Bearer ci-token"},
+		} {
+			t.Run(tc.method+" "+tc.path, func(t *testing.T) {
+				// The SSE stream stays open once a valid token gets through
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+				req, err := http.NewRequestWithContext(ctx, tc.method, srv.URL+tc.path, strings.NewReader(tc.body))
+				if err != nil {
+					t.Fatal(err)
+				}
+				req.Header.Set("Content-Type", "application/json")
+				req.Header.Set("Accept", "application/json, text/event-stream")
+				for k, v := range header {
+					req.Header.Set(k, v)
+				}
+				resp, err := http.DefaultClient.Do(req)
+				if err != nil {
+					t.Fatal(err)
+				}
+				resp.Body.Close()
+				valid := header["Authorization"] == "Bearer ci-token"
+				if unauthorized := resp.StatusCode == http.StatusUnauthorized; unauthorized == valid {
+					t.Errorf("with headers %v got %d, want 401 only without a valid token", header, resp.StatusCode)
+				}
+			})
+		}
+	}
+}
+
+// sseEvent is one event read from an SSE stream
+type sseEvent struct {
+	id   string
+	data string
+}
+
+// readEvent reads the next event carrying data from an SSE stream
+func readEvent(t *testing.T, r *bufio.Reader) sseEvent {
+	t.Helper()
+	var event sseEvent
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("reading SSE stream: %v", err)
+		}
+		line = strings.TrimRight(line, "\r\n")
+		switch {
+		case strings.HasPrefix(line, "id:"):
+			event.id = strings.TrimSpace(strings.TrimPrefix(line, "id:"))
+		case strings.HasPrefix(line, "data:"):
+			event.data += strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		case line == "" && event.data != "":
+			return event
+		}
+	}
+}
+
+func TestStreamableHTTPResumesWithLastEventID(t *testing.T) {
+	_, s := buildTestServer(t, &config.Config{}, false)
+	handler, _, err := newNetworkHandler(s, config.ServerConfig{Transport: transportHTTP, Host: "127.0.0.1"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	send := func(streamCtx context.Context, method, sessionID, body string, header map[string]string) *http.Response {
+		t.Helper()
+		req, err := http.NewRequestWithContext(streamCtx, method, srv.URL+"/mcp", strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/json, text/event-stream")
+		if sessionID != "" {
+			req.Header.Set(server.HeaderKeySessionID, sessionID)
+		}
+		for k, v := range header {
+			req.Header.Set(k, v)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s /mcp: %v", method, err)
+		}
+		if resp.StatusCode >= 300 {
+			t.Fatalf("%s /mcp: %s", method, resp.Status)
+		}
+		return resp
+	}
+
+	resp := send(ctx, http.MethodPost, "", `{"jsonrpc": "2.0", "id": 1, "method": "initialize", "params": {"protocolVersion": "2025-06-18", "capabilities": {}, "clientInfo": {"name": "test", "version": "1"}}}`, nil)
+	resp.Body.Close()
+	sessionID := resp.Header.Get(server.HeaderKeySessionID)
+	if sessionID == "" {
+		t.Fatal("initialize did not issue a session ID")
+	}
+	send(ctx, http.MethodPost, sessionID, `{"jsonrpc": "2.0", "method": "notifications/initialized"}`, nil).Body.Close()
+
+	updated := func(uri string) {
+		if err := s.SendNotificationToSpecificClient(sessionID, "notifications/resources/updated", map[string]any{"uri": uri}); err != nil {
+			t.Fatalf("notifying %s: %v", uri, err)
+		}
+	}
+	uriOf := func(event sseEvent) string {
+		var message struct {
+			Params struct {
+				URI string `json:"uri"`
+			} `json:"params"`
+		}
+		if err := json.Unmarshal([]byte(event.data), &message); err != nil {
+			t.Fatalf("event %q: %v", event.data, err)
+		}
+		return message.Params.URI
+	}
+
+	// Receive one message on the session's stream, then drop the connection
+	streamCtx, dropStream := context.WithCancel(ctx)
+	stream := send(streamCtx, http.MethodGet, sessionID, "", nil)
+	updated("sui://object/0x1")
+	first := readEvent(t, bufio.NewReader(stream.Body))
+	dropStream()
+	stream.Body.Close()
+	if first.id == "" || uriOf(first) != "sui://object/0x1" {
+		t.Fatalf("first event = %+v, want an ID and sui://object/0x1", first)
+	}
+
+	// Messages sent while the client is away are kept for it
+	updated("sui://object/0x2")
+	updated("sui://object/0x3")
+
+	resumed := send(ctx, http.MethodGet, sessionID, "", map[string]string{"Last-Event-ID": first.id})
+	defer resumed.Body.Close()
+	r := bufio.NewReader(resumed.Body)
+	for _, want := range []string{"sui://object/0x2", "sui://object/0x3"} {
+		if got := uriOf(readEvent(t, r)); got != want {
+			t.Errorf("resumed stream delivered %s, want %s", got, want)
+		}
+	}
+
+	// The resumed connection carries the stream on
+	updated("sui://object/0x4")
+	if got := uriOf(readEvent(t, r)); got != "sui://object/0x4" {
+		t.Errorf("after resuming got %s, want sui://object/0x4", got)
+	}
+}
+
+func TestExternalBaseURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.ServerConfig
+		want    string
+		wantErr bool
+	}{
+		{name: "base URL with a path prefix", cfg: config.ServerConfig{Host: "0.0.0.0", Port: 8080, BaseURL: "https://mcp.example.com/sui"}, want: "https://mcp.example.com/sui"},
+		{name: "all interfaces", cfg: config.ServerConfig{Host: "0.0.0.0", Port: 8080}, want: "http://localhost:8080"},
+		{name: "empty host", cfg: config.ServerConfig{Port: 8080}, want: "http://localhost:8080"},
+		{name: "IPv6 host", cfg: config.ServerConfig{Host: "::1", Port: 8080}, want: "http://[::1]:8080"},
+		{name: "TLS", cfg: config.ServerConfig{Host: "127.0.0.1", Port: 8443, TLS: config.TLSConfig{CertFile: "server.pem", KeyFile: "server.key"}}, want: "https://127.0.0.1:8443"},
+		{name: "relative base URL", cfg: config.ServerConfig{BaseURL: "/sui"}, wantErr: true},
+		{name: "base URL with a query", cfg: config.ServerConfig{BaseURL: "https://mcp.example.com/sui?x=1"}, wantErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			u, err := externalBaseURL(tc.cfg)
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("externalBaseURL = %s, want an error", u)
+				}
+				return
+			}
+			if err != nil || u.String() != tc.want {
+				t.Errorf("externalBaseURL = %v, %v, want %s", u, err, tc.want)
+			}
+		})
+	}
+}
+
+func TestSSEEndpointUnderBaseURLPrefix(t *testing.T) {
+	_, s := buildTestServer(t, &config.Config{}, false)
+	cfg := config.ServerConfig{Transport: transportSSE, Host: "127.0.0.1", BaseURL: "https://mcp.example.com/sui"}
+	handler, _, err := newNetworkHandler(s, cfg, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	// The proxy strips /sui before forwarding
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/sse", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if endpoint := readEvent(t, bufio.NewReader(resp.Body)).data; !strings.HasPrefix(endpoint, "https://mcp.example.com/sui/message?sessionId=") {
+		t.Errorf("SSE endpoint event = %q, want the message endpoint under the base URL", endpoint)
+	}
+}
+
+func TestRejectForeignHosts(t *testing.T) {
+	loopback := &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 8080}
+	public := &net.TCPAddr{IP: net.IPv4(192, 0, 2, 10), Port: 8080}
+	tests := []struct {
+		name  string
+		local net.Addr
+		host  string
+		want  int
+	}{
+		{name: "localhost", local: loopback, host: "localhost:8080", want: http.StatusOK},
+		{name: "loopback IP", local: loopback, host: "127.0.0.1:8080", want: http.StatusOK},
+		{name: "IPv6 loopback", local: loopback, host: "[::1]:8080", want: http.StatusOK},
+		{name: "external host from a local proxy", local: loopback, host: "MCP.example.com", want: http.StatusOK},
+		{name: "external host with a port", local: loopback, host: "mcp.example.com:443", want: http.StatusOK},
+		{name: "rebound name", local: loopback, host: "attacker.example:8080", want: http.StatusForbidden},
+		{name: "suffix of the external host", local: loopback, host: "evil-mcp.example.com", want: http.StatusForbidden},
+		{name: "public interface", local: public, host: "attacker.example:8080", want: http.StatusOK},
+	}
+	handler := rejectForeignHosts(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}), "mcp.example.com")
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/sse", nil)
+			req.Host = tc.host
+			req = req.WithContext(context.WithValue(req.Context(), http.LocalAddrContextKey, tc.local))
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tc.want {
+				t.Errorf("Host %s on %s got %d, want %d", tc.host, tc.local, rec.Code, tc.want)
+			}
+		})
+	}
+}
+
+// writeCert writes a PEM certificate and key for a new ECDSA key to dir,
+// signed by parent (self-signed when nil), and returns the certificate with its key
+func writeCert(t *testing.T, dir, name string, template *x509.Certificate, parent *tls.Certificate) tls.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template.SerialNumber = big.NewInt(time.Now().UnixNano())
+	template.Subject = pkix.Name{CommonName: name}
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(time.Hour)
+	signer, signerKey := template, any(key)
+	if parent != nil {
+		signer, signerKey = parent.Leaf, parent.PrivateKey
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	if err := os.WriteFile(filepath.Join(dir, name+".pem"), certPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name+".key"), keyPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+func TestMutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	caTemplate := func() *x509.Certificate {
+		return &x509.Certificate{IsCA: true, BasicConstraintsValid: true, KeyUsage: x509.KeyUsageCertSign}
+	}
+	ca := writeCert(t, dir, "ca", caTemplate(), nil)
+	otherCA := writeCert(t, dir, "other-ca", caTemplate(), nil)
+	writeCert(t, dir, "server", &x509.Certificate{IPAddresses: []net.IP{net.IPv4(127, 0, 0, 1)}, ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}}, &ca)
+	clientTemplate := func() *x509.Certificate {
+		return &x509.Certificate{ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}}
+	}
+	client := writeCert(t, dir, "client", clientTemplate(), &ca)
+	stranger := writeCert(t, dir, "stranger", clientTemplate(), &otherCA)
+
+	cfg := config.TLSConfig{
+		CertFile:     filepath.Join(dir, "server.pem"),
+		KeyFile:      filepath.Join(dir, "server.key"),
+		ClientCAFile: filepath.Join(dir, "ca.pem"),
+	}
+	tlsConfig, err := newTLSConfig(cfg)
+	if err != nil {
+		t.Fatalf("newTLSConfig: %v", err)
+	}
+	serverCert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tlsConfig.Certificates = []tls.Certificate{serverCert}
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	srv.TLS = tlsConfig
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.Leaf)
+	tests := []struct {
+		name   string
+		certs  []tls.Certificate
+		wantOK bool
+	}{
+		{name: "no client certificate"},
+		{name: "certificate from another CA", certs: []tls.Certificate{stranger}},
+		{name: "certificate from the client CA", certs: []tls.Certificate{client}, wantOK: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			c := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots, Certificates: tc.certs}}}
+			resp, err := c.Get(srv.URL)
+			if err == nil {
+				resp.Body.Close()
+			}
+			if ok := err == nil && resp.StatusCode == http.StatusOK; ok != tc.wantOK {
+				t.Errorf("request succeeded %v (%v), want %v", ok, err, tc.wantOK)
+			}
+		})
+	}
+}
+
+func TestNewTLSConfigRejectsIncompleteSettings(t *testing.T) {
+	dir := t.TempDir()
+	ca := writeCert(t, dir, "ca", &x509.Certificate{IsCA: true, BasicConstraintsValid: true, KeyUsage: x509.KeyUsageCertSign}, nil)
+	writeCert(t, dir, "server", &x509.Certificate{}, &ca)
+	if err := os.WriteFile(filepath.Join(dir, "empty.pem"), []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	serverFiles := config.TLSConfig{CertFile: filepath.Join(dir, "server.pem"), KeyFile: filepath.Join(dir, "server.key")}
+
+	if c, err := newTLSConfig(config.TLSConfig{}); c != nil || err != nil {
+		t.Errorf("newTLSConfig without files = %v, %v, want TLS off", c, err)
+	}
+	for name, cfg := range map[string]config.TLSConfig{
+		"client CA without a certificate": {ClientCAFile: filepath.Join(dir, "ca.pem")},
+		"certificate without a key":       {CertFile: serverFiles.CertFile},
+		"key of another certificate":      {CertFile: serverFiles.CertFile, KeyFile: filepath.Join(dir, "ca.key")},
+		"client CA without certificates":  {CertFile: serverFiles.CertFile, KeyFile: serverFiles.KeyFile, ClientCAFile: filepath.Join(dir, "empty.pem")},
+	} {
+		if _, err := newTLSConfig(cfg); err == nil {
+			t.Errorf("%s: newTLSConfig accepted it", name)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/krli/go-sui-mcp/internal/config"
+	"github.com/krli/go-sui-mcp/internal/sui/suitest"
+)
+
+func TestArgumentValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		tool string
+		args map[string]any
+		// wantErr must appear in the refusal, or "" when the call must run
+		wantErr string
+		// wantArg must be in the argv of the sui command that ran
+		wantArg string
+	}{
+		{
+			name: "flag as address", tool: "sui-gas",
+			args:    map[string]any{"address": "--help"},
+			wantErr: `invalid address: "--help" starts with "-"`,
+		},
+		{
+			name: "flag behind whitespace", tool: "sui-gas",
+			args:    map[string]any{"address": "  -h"},
+			wantErr: `starts with "-"`,
+		},
+		{
+			name: "flag in a free-form argument", tool: "sui-call",
+			args:    map[string]any{"package": "0x2", "module": "--gas-budget", "function": "join"},
+			wantErr: `invalid module: "--gas-budget" starts with "-"`,
+		},
+		{
+			name: "flag in an array element", tool: "sui-pay-sui",
+			args:    map[string]any{"input-coins": []any{suitest.CoinObjectID, "-x"}, "recipients": []any{suitest.OtherAddress}, "amounts": []any{"1"}},
+			wantErr: `invalid input-coins[1]: "-x" starts with "-"`,
+		},
+		{
+			name: "flag as sender", tool: "sui-transfer",
+			args:    map[string]any{"to": suitest.OtherAddress, "object-id": suitest.CoinObjectID, "sender": "-y"},
+			wantErr: `invalid sender`,
+		},
+		{
+			name: "digest with a non-base58 character", tool: "sui-process-transaction",
+			args:    map[string]any{"txID": "0" + suitest.TxDigest[1:]},
+			wantErr: `invalid txID: "0` + suitest.TxDigest[1:] + `" contains the non-base58 character '0'; expected a base58 transaction digest`,
+		},
+		{
+			name: "digest of the wrong length", tool: "sui-process-transaction",
+			args:    map[string]any{"txID": suitest.TxDigest[:30]},
+			wantErr: "decodes to",
+		},
+		{
+			name: "digest as query cursor", tool: "sui-query-transactions",
+			args:    map[string]any{"cursor": "not-a-digest"},
+			wantErr: "invalid cursor",
+		},
+		{
+			name: "object ID that is too long", tool: "sui-object",
+			args:    map[string]any{"objectID": "0x" + strings.Repeat("a", 65)},
+			wantErr: "invalid objectID",
+		},
+		{
+			name: "address without 0x", tool: "sui-transfer",
+			args:    map[string]any{"to": suitest.OtherAddress[2:], "object-id": suitest.CoinObjectID},
+			wantErr: `invalid to: "` + suitest.OtherAddress[2:] + `" must start with 0x; expected a 0x-prefixed address of up to 64 hex digits or a SuiNS name such as alice.sui`,
+		},
+		{
+			name: "short object ID is padded", tool: "sui-object",
+			args:    map[string]any{"objectID": "0x5"},
+			wantArg: "0x" + strings.Repeat("0", 63) + "5",
+		},
+		{
+			name: "upper-case address is lowered", tool: "sui-gas",
+			args:    map[string]any{"address": "0x" + strings.ToUpper(suitest.ActiveAddress[2:])},
+			wantArg: suitest.ActiveAddress,
+		},
+		{
+			name: "keystore alias is kept", tool: "sui-gas",
+			args:    map[string]any{"address": "main-key"},
+			wantArg: "main-key",
+		},
+		{
+			name: "dash inside a value is fine", tool: "sui-call",
+			args:    map[string]any{"package": "0x2", "module": "coin", "function": "join", "args": []any{"a-b"}},
+			wantArg: "a-b",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ts := newTestServer(t, &config.Config{}, false)
+			ts.exec.Fallback(suitest.Response{Output: suitest.Fixture("dry_run.json")})
+
+			text, failed := ts.tryCall(tc.tool, tc.args)
+			var ran [][]string
+			for _, call := range ts.exec.Calls() {
+				if call.Name == suitest.Executable {
+					ran = append(ran, call.Args)
+				}
+			}
+
+			if tc.wantErr != "" {
+				if !failed || !strings.Contains(text, tc.wantErr) {
+					t.Errorf("result = %q (failed %v), want a refusal containing %q", text, failed, tc.wantErr)
+				}
+				if !strings.Contains(text, tc.tool+" was not executed") {
+					t.Errorf("refusal does not come from the validation layer: %q", text)
+				}
+				if len(ran) > 0 {
+					t.Errorf("sui ran with an invalid argument: %v", ran)
+				}
+				return
+			}
+			if !slices.ContainsFunc(ran, func(args []string) bool { return slices.Contains(args, tc.wantArg) }) {
+				t.Errorf("no sui command was passed %q; ran %v (result %q)", tc.wantArg, ran, text)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+// Package approval asks a human to confirm a transaction before it is signed.
+package approval
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/krli/go-sui-mcp/internal/config"
+)
+
+// Modes accepted by the approval.mode setting
+const (
+	// ModeNone executes signing tools without asking
+	ModeNone = "none"
+	// ModeElicitation asks the user through the MCP client via elicitation
+	ModeElicitation = "elicitation"
+	// ModeHTTP posts the request to a local approval endpoint
+	ModeHTTP = "http"
+)
+
+// Request describes a pending transaction awaiting approval
+type Request struct {
+	Tool      string         `json:"tool"`
+	Arguments map[string]any `json:"arguments"`
+	// Preview is the readable dry-run rendering of the transaction
+	Preview string `json:"preview"`
+}
+
+// Decision is the human's answer to a Request
+type Decision struct {
+	Approved bool   `json:"approved"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// Approver obtains a Decision for a pending transaction. Implementations must
+// fail closed: any error is treated as a rejection by the caller.
+type Approver interface {
+	Approve(ctx context.Context, req Request) (Decision, error)
+}
+
+// New creates the approver selected by cfg; it returns nil when approval is disabled
+func New(cfg config.ApprovalConfig) (Approver, error) {
+	switch cfg.Mode {
+	case "", ModeNone:
+		return nil, nil
+	case ModeElicitation:
+		return NewElicitationApprover(), nil
+	case ModeHTTP:
+		if cfg.URL == "" {
+			return nil, fmt.Errorf("approval.url is required when approval.mode is %s", ModeHTTP)
+		}
+		return NewHTTPApprover(cfg.URL, &http.Client{Timeout: cfg.Timeout}), nil
+	default:
+		return nil, fmt.Errorf("unknown approval.mode %q (expected %s, %s or %s)", cfg.Mode, ModeNone, ModeElicitation, ModeHTTP)
+	}
+}
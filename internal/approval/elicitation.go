@@ -0,0 +1,59 @@
+package approval
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ElicitationApprover asks the user of the calling MCP session through elicitation/create
+type ElicitationApprover struct{}
+
+// NewElicitationApprover creates an approver that uses the session found in the request context
+func NewElicitationApprover() *ElicitationApprover {
+	return &ElicitationApprover{}
+}
+
+// Approve shows the preview to the user and waits for an explicit yes
+func (a *ElicitationApprover) Approve(ctx context.Context, req Request) (Decision, error) {
+	srv := server.ServerFromContext(ctx)
+	if srv == nil {
+		return Decision{}, errors.New("no MCP server in context")
+	}
+
+	result, err := srv.RequestElicitation(ctx, mcp.ElicitationRequest{
+		Params: mcp.ElicitationParams{
+			Message: fmt.Sprintf("%s wants to submit a transaction.\n\n%s\nApprove and sign it?", req.Tool, req.Preview),
+			RequestedSchema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"approve": map[string]any{
+						"type":        "boolean",
+						"title":       "Approve",
+						"description": "Sign and submit this transaction",
+					},
+				},
+				"required": []string{"approve"},
+			},
+		},
+	})
+	if err != nil {
+		return Decision{}, fmt.Errorf("failed to request approval: %w", err)
+	}
+
+	switch result.Action {
+	case mcp.ElicitationResponseActionAccept:
+		content, _ := result.Content.(map[string]any)
+		if approved, _ := content["approve"].(bool); approved {
+			return Decision{Approved: true}, nil
+		}
+		return Decision{Reason: "user did not tick approve"}, nil
+	case mcp.ElicitationResponseActionDecline:
+		return Decision{Reason: "user declined"}, nil
+	default:
+		return Decision{Reason: "user cancelled"}, nil
+	}
+}
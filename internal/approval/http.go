@@ -0,0 +1,60 @@
+package approval
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// HTTPApprover posts each Request as JSON to an approval endpoint, typically a
+// small local service that prompts an operator. The endpoint must answer 200
+// with a JSON Decision; the call blocks until it does.
+type HTTPApprover struct {
+	url        string
+	httpClient *http.Client
+}
+
+// NewHTTPApprover creates an approver for the endpoint at url
+func NewHTTPApprover(url string, httpClient *http.Client) *HTTPApprover {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &HTTPApprover{
+		url:        url,
+		httpClient: httpClient,
+	}
+}
+
+// Approve posts the request and decodes the endpoint's decision
+func (a *HTTPApprover) Approve(ctx context.Context, req Request) (Decision, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return Decision{}, fmt.Errorf("failed to encode approval request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, a.url, bytes.NewReader(body))
+	if err != nil {
+		return Decision{}, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := a.httpClient.Do(httpReq)
+	if err != nil {
+		return Decision{}, fmt.Errorf("approval endpoint unreachable: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return Decision{}, fmt.Errorf("approval endpoint returned %s: %s", resp.Status, bytes.TrimSpace(data))
+	}
+
+	var decision Decision
+	if err := json.NewDecoder(resp.Body).Decode(&decision); err != nil {
+		return Decision{}, fmt.Errorf("invalid approval response: %w", err)
+	}
+	return decision, nil
+}
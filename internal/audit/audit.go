@@ -0,0 +1,250 @@
+// Package audit writes a tamper-evident log of every tool invocation.
+//
+// Each record is one JSON line carrying the hash of the record before it, and
+// its own hash is the SHA-256 of that previous hash followed by the record's
+// JSON without the hash field. Editing, removing or reordering any line breaks
+// the chain from that point on, which Verify reports. Dropping records from the
+// end of the log leaves a shorter chain that is still valid, so it is only
+// detected against a Head recorded earlier and kept outside the log.
+package audit
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Statuses of a tool invocation
+const (
+	StatusOK = "ok"
+	// StatusToolError is a call that returned an MCP tool error such as a policy denial
+	StatusToolError = "tool_error"
+	// StatusError is a call whose handler failed
+	StatusError = "error"
+)
+
+// Command is one sui process run while serving the call
+type Command struct {
+	Argv     []string `json:"argv"`
+	ExitCode int      `json:"exitCode"`
+}
+
+// Record is one audited tool invocation
+type Record struct {
+	Seq     uint64    `json:"seq"`
+	Time    time.Time `json:"time"`
+	Session string    `json:"session,omitempty"`
+	// Caller and Role identify the authenticated token; both are empty on stdio
+	Caller string `json:"caller,omitempty"`
+	Role   string `json:"role,omitempty"`
+	Tool   string `json:"tool"`
+	// Args are the call arguments after secret redaction
+	Args       json.RawMessage `json:"args,omitempty"`
+	Commands   []Command       `json:"commands,omitempty"`
+	Status     string          `json:"status"`
+	Error      string          `json:"error,omitempty"`
+	Digest     string          `json:"digest,omitempty"`
+	DurationMs int64           `json:"durationMs"`
+	PrevHash   string          `json:"prevHash"`
+	Hash       string          `json:"hash,omitempty"`
+}
+
+// computeHash returns the chain hash of r, ignoring any hash already set
+func computeHash(r Record) (string, error) {
+	r.Hash = ""
+	data, err := json.Marshal(r)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.New()
+	sum.Write([]byte(r.PrevHash))
+	sum.Write(data)
+	return hex.EncodeToString(sum.Sum(nil)), nil
+}
+
+// Logger appends records to a JSON-lines file, continuing the chain of any existing records
+type Logger struct {
+	mu       sync.Mutex
+	file     *os.File
+	seq      uint64
+	lastHash string
+}
+
+// Open opens or creates the log at path and picks up the chain where it left off
+func Open(path string) (*Logger, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create audit log directory: %w", err)
+	}
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+
+	l := &Logger{file: file}
+	scanner := newScanner(file)
+	for scanner.Scan() {
+		var r Record
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			file.Close()
+			return nil, fmt.Errorf("audit log %s is corrupt at record %d: %w", path, l.seq+1, err)
+		}
+		l.seq, l.lastHash = r.Seq, r.Hash
+	}
+	if err := scanner.Err(); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	return l, nil
+}
+
+// Log chains r onto the log and writes it durably; Seq, PrevHash and Hash are filled in
+func (l *Logger) Log(r Record) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return errors.New("audit log is closed")
+	}
+	r.Seq = l.seq + 1
+	r.PrevHash = l.lastHash
+	hash, err := computeHash(r)
+	if err != nil {
+		return err
+	}
+	r.Hash = hash
+
+	line, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	if _, err := l.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write audit record: %w", err)
+	}
+	if err := l.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync audit log: %w", err)
+	}
+	l.seq, l.lastHash = r.Seq, r.Hash
+	return nil
+}
+
+// Close flushes the last record to disk and closes the log; records logged
+// afterwards are refused
+func (l *Logger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Sync()
+	if closeErr := l.file.Close(); err == nil {
+		err = closeErr
+	}
+	l.file = nil
+	if err != nil {
+		return fmt.Errorf("failed to close audit log: %w", err)
+	}
+	return nil
+}
+
+// Head is the last record of a chain. Kept outside the log, it anchors the
+// chain so that records removed from the end can be detected.
+type Head struct {
+	Seq  uint64
+	Hash string
+}
+
+// String formats h as "seq:hash", the form ParseHead accepts
+func (h Head) String() string {
+	return fmt.Sprintf("%d:%s", h.Seq, h.Hash)
+}
+
+// ParseHead parses a head printed by Head.String
+func ParseHead(s string) (Head, error) {
+	seq, hash, ok := strings.Cut(strings.TrimSpace(s), ":")
+	n, err := strconv.ParseUint(seq, 10, 64)
+	if !ok || err != nil || n == 0 || len(hash) != sha256.Size*2 {
+		return Head{}, fmt.Errorf("invalid audit head %q: expected <seq>:<sha256 hex>", s)
+	}
+	return Head{Seq: n, Hash: strings.ToLower(hash)}, nil
+}
+
+// Verify walks the chain in r and returns its head. The error names the first
+// record whose sequence, link or hash does not match. With a non-nil anchor,
+// the chain must also still contain the anchor record unchanged.
+func Verify(r io.Reader, anchor *Head) (Head, error) {
+	scanner := newScanner(r)
+	var (
+		count    int
+		prevHash string
+		head     Head
+	)
+	for scanner.Scan() {
+		line := count + 1
+		var rec Record
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			return head, fmt.Errorf("line %d: not a valid record: %w", line, err)
+		}
+		if rec.Seq != uint64(line) {
+			return head, fmt.Errorf("line %d: sequence is %d, expected %d (records removed or reordered)", line, rec.Seq, line)
+		}
+		if rec.PrevHash != prevHash {
+			return head, fmt.Errorf("line %d: previous hash does not match line %d (records removed or reordered)", line, line-1)
+		}
+		hash, err := computeHash(rec)
+		if err != nil {
+			return head, fmt.Errorf("line %d: %w", line, err)
+		}
+		if hash != rec.Hash {
+			return head, fmt.Errorf("line %d: hash mismatch (record modified)", line)
+		}
+		if anchor != nil && rec.Seq == anchor.Seq && rec.Hash != anchor.Hash {
+			return head, fmt.Errorf("line %d: hash does not match the anchor %s (log rewritten)", line, anchor)
+		}
+		prevHash = rec.Hash
+		head = Head{Seq: rec.Seq, Hash: rec.Hash}
+		count++
+	}
+	if err := scanner.Err(); err != nil {
+		return head, err
+	}
+	if anchor != nil && head.Seq < anchor.Seq {
+		return head, fmt.Errorf("log ends at record %d but the anchor is record %d (records removed from the end)", head.Seq, anchor.Seq)
+	}
+	return head, nil
+}
+
+// ErrEmpty is returned by VerifyFile for a log with no records
+var ErrEmpty = errors.New("audit log is empty")
+
+// VerifyFile verifies the log at path against an optional anchor
+func VerifyFile(path string, anchor *Head) (Head, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return Head{}, err
+	}
+	defer file.Close()
+
+	head, err := Verify(file, anchor)
+	if err == nil && head.Seq == 0 {
+		return head, ErrEmpty
+	}
+	return head, err
+}
+
+// newScanner reads JSON lines, allowing records larger than bufio's default token size
+func newScanner(r io.Reader) *bufio.Scanner {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	return scanner
+}
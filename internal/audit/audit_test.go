@@ -0,0 +1,125 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeLog logs n records to a new log, reopening it halfway so the chain spans restarts
+func writeLog(t *testing.T, n int) (path string, lines []string) {
+	t.Helper()
+	path = filepath.Join(t.TempDir(), "audit.jsonl")
+	for _, count := range []int{n / 2, n - n/2} {
+		l, err := Open(path)
+		if err != nil {
+			t.Fatalf("Open: %v", err)
+		}
+		for range count {
+			if err := l.Log(Record{Tool: "sui-gas", Status: StatusOK, Caller: "bot", Role: "agent"}); err != nil {
+				t.Fatalf("Log: %v", err)
+			}
+		}
+		l.Close()
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path, strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+}
+
+func TestVerify(t *testing.T) {
+	_, lines := writeLog(t, 5)
+	head, err := Verify(strings.NewReader(strings.Join(lines, "\n")), nil)
+	if err != nil {
+		t.Fatalf("intact log: %v", err)
+	}
+	if head.Seq != 5 {
+		t.Fatalf("head = %v, want record 5", head)
+	}
+	mid, err := Verify(strings.NewReader(strings.Join(lines[:3], "\n")), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	join := func(ls ...string) string { return strings.Join(ls, "\n") }
+	tests := []struct {
+		name   string
+		log    string
+		anchor *Head
+		// wantErr is text the error must contain, or "" for a valid log
+		wantErr string
+	}{
+		{"intact with its own head", join(lines...), &head, ""},
+		{"intact with an older anchor", join(lines...), &mid, ""},
+		{"record modified", join(lines[0], strings.Replace(lines[1], "sui-gas", "sui-pay", 1), lines[2]), nil, "line 2: hash mismatch"},
+		{"record removed", join(lines[0], lines[2], lines[3]), nil, "line 2: sequence is 3"},
+		{"records reordered", join(lines[0], lines[2], lines[1]), nil, "line 2: sequence is 3"},
+		{"first record removed", join(lines[1:]...), nil, "line 1: sequence is 2"},
+		{"end truncated without an anchor", join(lines[:3]...), nil, ""},
+		{"end truncated past the anchor", join(lines[:3]...), &head, "records removed from the end"},
+		{"log rewritten under the anchor", join(lines[:3]...), &Head{Seq: 3, Hash: head.Hash}, "does not match the anchor"},
+		{"not JSON", join(lines[0], "{"), nil, "line 2: not a valid record"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Verify(strings.NewReader(tc.log), tc.anchor)
+			switch {
+			case tc.wantErr == "" && err != nil:
+				t.Errorf("unexpected error: %v", err)
+			case tc.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tc.wantErr)):
+				t.Errorf("err = %v, want %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerifyFile(t *testing.T) {
+	path, _ := writeLog(t, 3)
+	head, err := VerifyFile(path, nil)
+	if err != nil || head.Seq != 3 {
+		t.Fatalf("VerifyFile = %v, %v", head, err)
+	}
+
+	empty := filepath.Join(t.TempDir(), "empty.jsonl")
+	if err := os.WriteFile(empty, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := VerifyFile(empty, nil); err != ErrEmpty {
+		t.Errorf("empty log: err = %v, want ErrEmpty", err)
+	}
+	// An emptied log is caught by the anchor before it is reported as empty
+	if _, err := VerifyFile(empty, &head); err == nil || err == ErrEmpty {
+		t.Errorf("emptied log with anchor: err = %v", err)
+	}
+}
+
+func TestParseHead(t *testing.T) {
+	hash := strings.Repeat("ab", 32)
+	tests := []struct {
+		in      string
+		want    Head
+		wantErr bool
+	}{
+		{in: "12:" + hash, want: Head{Seq: 12, Hash: hash}},
+		{in: " 12:" + strings.ToUpper(hash) + "\n", want: Head{Seq: 12, Hash: hash}},
+		{in: "0:" + hash, wantErr: true},
+		{in: "12:" + hash[:10], wantErr: true},
+		{in: "x:" + hash, wantErr: true},
+		{in: hash, wantErr: true},
+	}
+	for _, tc := range tests {
+		got, err := ParseHead(tc.in)
+		if (err != nil) != tc.wantErr || got != tc.want {
+			t.Errorf("ParseHead(%q) = %v, %v", tc.in, got, err)
+		}
+	}
+
+	// Head.String round-trips
+	h := Head{Seq: 7, Hash: hash}
+	if got, err := ParseHead(h.String()); err != nil || got != h {
+		t.Errorf("ParseHead(%q) = %v, %v", h, got, err)
+	}
+}
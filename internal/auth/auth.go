@@ -0,0 +1,119 @@
+// Package auth authenticates network MCP clients by bearer token or API key.
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/krli/go-sui-mcp/internal/config"
+)
+
+// Identity is the authenticated caller of a request
+type Identity struct {
+	// Name is the configured name of the token, used in logs
+	Name string
+	// Role is the role the token was granted
+	Role string
+	// MaxPerTxMist caps the SUI a single payment by this caller may send; zero means no role limit
+	MaxPerTxMist uint64
+}
+
+type identityKey struct{}
+
+// WithIdentity returns a context carrying id
+func WithIdentity(ctx context.Context, id Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, id)
+}
+
+// FromContext returns the caller identity; ok is false for unauthenticated transports such as stdio
+func FromContext(ctx context.Context) (id Identity, ok bool) {
+	id, ok = ctx.Value(identityKey{}).(Identity)
+	return id, ok
+}
+
+type token struct {
+	// digest is the SHA-256 of the token so comparisons run in constant time regardless of length
+	digest [sha256.Size]byte
+	id     Identity
+}
+
+// Authenticator checks the Authorization: Bearer or X-API-Key header of every HTTP request
+type Authenticator struct {
+	tokens []token
+}
+
+// New creates an authenticator for the configured tokens; it returns nil when none are configured
+func New(cfg config.AuthConfig) (*Authenticator, error) {
+	if len(cfg.Tokens) == 0 {
+		return nil, nil
+	}
+
+	a := &Authenticator{}
+	for i, t := range cfg.Tokens {
+		name := t.Name
+		if name == "" {
+			name = fmt.Sprintf("token #%d", i+1)
+		}
+		if t.Token == "" {
+			return nil, fmt.Errorf("server.auth.tokens: %s has an empty token", name)
+		}
+		role, ok := cfg.Roles[t.Role]
+		if !ok {
+			return nil, fmt.Errorf("server.auth.tokens: %s has unknown role %q", name, t.Role)
+		}
+		a.tokens = append(a.tokens, token{
+			digest: sha256.Sum256([]byte(t.Token)),
+			id:     Identity{Name: name, Role: t.Role, MaxPerTxMist: role.MaxPerTxMist},
+		})
+	}
+	return a, nil
+}
+
+// Authenticate returns the identity of the token presented by r
+func (a *Authenticator) Authenticate(r *http.Request) (Identity, bool) {
+	presented := r.Header.Get("X-API-Key")
+	if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		presented = strings.TrimSpace(bearer)
+	}
+	if presented == "" {
+		return Identity{}, false
+	}
+
+	digest := sha256.Sum256([]byte(presented))
+	var (
+		found Identity
+		ok    bool
+	)
+	// Compare against every token so timing does not reveal which one matched
+	for _, t := range a.tokens {
+		if subtle.ConstantTimeCompare(digest[:], t.digest[:]) == 1 {
+			found, ok = t.id, true
+		}
+	}
+	return found, ok
+}
+
+// Middleware rejects requests without a valid token and passes the caller identity down in the request context
+func (a *Authenticator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, ok := a.Authenticate(r)
+		if !ok {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="go-sui-mcp"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(WithIdentity(r.Context(), id)))
+	})
+}
+
+// ContextFunc copies the identity placed by Middleware into the MCP request context
+func (a *Authenticator) ContextFunc(ctx context.Context, r *http.Request) context.Context {
+	if id, ok := FromContext(r.Context()); ok {
+		return WithIdentity(ctx, id)
+	}
+	return ctx
+}
@@ -0,0 +1,82 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/krli/go-sui-mcp/internal/config"
+)
+
+var testConfig = config.AuthConfig{
+	Tokens: []config.TokenConfig{
+		{Name: "analyst", Token: "reader-token", Role: "reader"},
+		{Token: "payer-token", Role: "payer"},
+	},
+	Roles: map[string]config.RoleConfig{"reader": {}, "payer": {MaxPerTxMist: 1_000_000_000}},
+}
+
+func TestNew(t *testing.T) {
+	if a, err := New(config.AuthConfig{}); a != nil || err != nil {
+		t.Errorf("New without tokens = %v, %v, want no authenticator", a, err)
+	}
+	if _, err := New(config.AuthConfig{Tokens: []config.TokenConfig{{Name: "ci", Role: "reader"}}, Roles: testConfig.Roles}); err == nil {
+		t.Error("New accepted an empty token")
+	}
+	if _, err := New(config.AuthConfig{Tokens: []config.TokenConfig{{Name: "ci", Token: "t", Role: "admin"}}, Roles: testConfig.Roles}); err == nil {
+		t.Error("New accepted an unknown role")
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	a, err := New(testConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name   string
+		header map[string]string
+		// want is the identity passed on; a zero Identity means the request is refused
+		want Identity
+	}{
+		{name: "no token"},
+		{name: "wrong bearer token", header: map[string]string{"Authorization": "Bearer writer-token"}},
+		{name: "not a bearer token", header: map[string]string{"Authorization": "Basic reader-token"}},
+		{name: "wrong API key", header: map[string]string{"X-API-Key": "reader"}},
+		{name: "bearer token", header: map[string]string{"Authorization": "Bearer reader-token"}, want: Identity{Name: "analyst", Role: "reader"}},
+		{name: "API key", header: map[string]string{"X-API-Key": "payer-token"}, want: Identity{Name: "token #2", Role: "payer", MaxPerTxMist: 1_000_000_000}},
+		{
+			name:   "bearer token wins over API key",
+			header: map[string]string{"Authorization": "Bearer reader-token", "X-API-Key": "payer-token"},
+			want:   Identity{Name: "analyst", Role: "reader"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var got *Identity
+			h := a.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				id, _ := FromContext(a.ContextFunc(t.Context(), r))
+				got = &id
+			}))
+			req := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+			for k, v := range tc.header {
+				req.Header.Set(k, v)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if tc.want == (Identity{}) {
+				if rec.Code != http.StatusUnauthorized || got != nil {
+					t.Errorf("status %d, handler reached %v, want 401", rec.Code, got != nil)
+				}
+				if rec.Header().Get("WWW-Authenticate") == "" {
+					t.Error("401 without WWW-Authenticate")
+				}
+				return
+			}
+			if got == nil || *got != tc.want {
+				t.Errorf("status %d, identity %v, want %v", rec.Code, got, tc.want)
+			}
+		})
+	}
+}
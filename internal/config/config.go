@@ -1,45 +1,280 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"time"
 
 	"github.com/spf13/viper"
 )
 
 // Config contains all the configuration for the application
 type Config struct {
-	Server ServerConfig `mapstructure:"server"`
-	Sui    SuiConfig    `mapstructure:"sui"`
+	Server   ServerConfig   `mapstructure:"server"`
+	Sui      SuiConfig      `mapstructure:"sui"`
+	Timeouts TimeoutConfig  `mapstructure:"timeouts"`
+	Approval ApprovalConfig `mapstructure:"approval"`
+	Policy   PolicyConfig   `mapstructure:"policy"`
+	Tools    ToolsConfig    `mapstructure:"tools"`
+	Secrets  SecretsConfig  `mapstructure:"secrets"`
+	Audit    AuditConfig    `mapstructure:"audit"`
+	// Subscriptions tunes the live chain subscriptions of sui-subscribe
+	Subscriptions SubscriptionsConfig `mapstructure:"subscriptions"`
 }
 
 // ServerConfig contains settings for the HTTP server
 type ServerConfig struct {
-	Port int    `mapstructure:"port"`
+	Port int `mapstructure:"port"`
+	// Host is the interface the network transports bind to
 	Host string `mapstructure:"host"`
+	// BaseURL is the external URL clients use, e.g. behind a reverse proxy; derived from host and port when empty
+	BaseURL string `mapstructure:"base_url"`
+	// TLS serves the network transports over HTTPS, optionally requiring client certificates
+	TLS TLSConfig `mapstructure:"tls"`
+	// Transport is "stdio" (default), "sse" or "http" (streamable HTTP)
+	Transport string `mapstructure:"transport"`
+	// HTTP tunes the streamable HTTP transport
+	HTTP HTTPTransportConfig `mapstructure:"http"`
+	// ReadOnly registers only query tools so nothing can sign or touch the keystore
+	ReadOnly bool `mapstructure:"read_only"`
+	// Auth protects the network transports; stdio is never authenticated
+	Auth AuthConfig `mapstructure:"auth"`
+}
+
+// TLSConfig holds the server certificate and, for mutual TLS, the CA that signs client certificates
+type TLSConfig struct {
+	CertFile string `mapstructure:"cert_file"`
+	KeyFile  string `mapstructure:"key_file"`
+	// ClientCAFile turns on mutual TLS: every client must present a certificate signed by this CA
+	ClientCAFile string `mapstructure:"client_ca_file"`
+}
+
+// HTTPTransportConfig tunes the streamable HTTP transport
+type HTTPTransportConfig struct {
+	// EndpointPath is where the MCP endpoint is mounted; defaults to /mcp
+	EndpointPath string `mapstructure:"endpoint_path"`
+	// HeartbeatInterval keeps idle GET streams open through proxies; zero disables heartbeats
+	HeartbeatInterval time.Duration `mapstructure:"heartbeat_interval"`
+	// SessionIdleTTL ends sessions that have been idle this long; zero keeps them until deleted
+	SessionIdleTTL time.Duration `mapstructure:"session_idle_ttl"`
+}
+
+// AuthConfig lists the tokens accepted by the network transports and what each may do
+type AuthConfig struct {
+	// Tokens are accepted as "Authorization: Bearer <token>" or "X-API-Key: <token>"; none means no auth
+	Tokens []TokenConfig `mapstructure:"tokens"`
+	// Roles maps a role name to the tools its tokens may call
+	Roles map[string]RoleConfig `mapstructure:"roles"`
+	// AllowUnauthenticated serves the network transports without tokens on a host other than loopback
+	AllowUnauthenticated bool `mapstructure:"allow_unauthenticated"`
+}
+
+// TokenConfig is one accepted token
+type TokenConfig struct {
+	Name  string `mapstructure:"name"`
+	Token string `mapstructure:"token"`
+	Role  string `mapstructure:"role"`
+}
+
+// RoleConfig limits a role to a set of tools, using the same names and groups as ToolsConfig
+type RoleConfig struct {
+	// Include lists the tools or groups the role may call; empty means all registered tools
+	Include []string `mapstructure:"include"`
+	// Exclude removes tools or groups from Include
+	Exclude []string `mapstructure:"exclude"`
+	// MaxPerTxMist caps the SUI a single payment by this role may send; zero means no role limit
+	MaxPerTxMist uint64 `mapstructure:"max_per_tx_mist"`
 }
 
 // SuiConfig contains settings for the Sui client
 type SuiConfig struct {
 	ExecutablePath string `mapstructure:"executable_path"`
+	// Backend selects how reads are served: "cli" (default) or "rpc"
+	Backend string `mapstructure:"backend"`
+	// RPCURL is the fullnode JSON-RPC endpoint used by the rpc backend
+	RPCURL string `mapstructure:"rpc_url"`
+	// Env is the environment used when a call has no env argument; empty means the CLI's active env
+	Env string `mapstructure:"env"`
+	// ClientConfig is the sui client.yaml that environments are read from
+	ClientConfig string `mapstructure:"client_config"`
+	// EnvConfigDir holds the per-environment copies of ClientConfig
+	EnvConfigDir string `mapstructure:"env_config_dir"`
 }
 
-// Load loads the configuration from viper
+// ToolsConfig selects which tools are registered. Include and Exclude take tool
+// names or the groups query, payments, contracts, move-dev, keytool,
+// subscriptions and client.
+type ToolsConfig struct {
+	// Include limits the server to these tools or groups; empty means all of them
+	Include []string `mapstructure:"include"`
+	// Exclude removes tools or groups after Include is applied
+	Exclude []string `mapstructure:"exclude"`
+	// Enabled forces single tools on or off regardless of Include and Exclude
+	Enabled map[string]bool `mapstructure:"enabled"`
+}
+
+// TimeoutConfig bounds how long a single tool call may run before it is cancelled
+type TimeoutConfig struct {
+	// Default applies to every tool without an explicit entry; zero disables the limit
+	Default time.Duration `mapstructure:"default"`
+	// Tools overrides the default per tool name, e.g. "sui-move-test": 10m
+	Tools map[string]time.Duration `mapstructure:"tools"`
+	// Preview bounds each dry run the policy and approval gates make before
+	// letting a call through, whatever the tool's own limit; zero disables it
+	Preview time.Duration `mapstructure:"preview"`
+}
+
+// ApprovalConfig controls the human approval gate in front of signing tools
+type ApprovalConfig struct {
+	// Mode is "none" (default), "elicitation" or "http"
+	Mode string `mapstructure:"mode"`
+	// URL is the approval endpoint used by the http mode
+	URL string `mapstructure:"url"`
+	// Timeout bounds how long to wait for a decision; an unanswered request is rejected
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+// PolicyConfig limits what the payment tools may send and to whom
+type PolicyConfig struct {
+	// Enabled turns the spending policy on; it is off by default
+	Enabled bool `mapstructure:"enabled"`
+	// MaxPerTxMist caps the SUI sent by a single payment; zero means no limit
+	MaxPerTxMist uint64 `mapstructure:"max_per_tx_mist"`
+	// MaxPerDayMist caps the SUI sent over any rolling 24 hours; zero means no limit
+	MaxPerDayMist uint64 `mapstructure:"max_per_day_mist"`
+	// CoinCaps sets limits per coin type in the coin's smallest unit and overrides the MIST limits for SUI
+	CoinCaps map[string]CoinCap `mapstructure:"coin_caps"`
+	// AllowedRecipients, when not empty, is the only set of addresses payments may go to
+	AllowedRecipients []string `mapstructure:"allowed_recipients"`
+	// BlockedRecipients are never paid
+	BlockedRecipients []string `mapstructure:"blocked_recipients"`
+	// StateFile persists the rolling totals across restarts
+	StateFile string `mapstructure:"state_file"`
+}
+
+// CoinCap holds the limits for one coin type; zero means no limit
+type CoinCap struct {
+	PerTx  uint64 `mapstructure:"per_tx"`
+	PerDay uint64 `mapstructure:"per_day"`
+}
+
+// SecretsConfig controls the tools that produce private keys or recovery phrases
+type SecretsConfig struct {
+	// Enabled allows sui-keytool-export and sui-keytool-generate to run; off by default
+	Enabled bool `mapstructure:"enabled"`
+	// Sink is where secrets are written: "file" (default) or "http"
+	Sink string `mapstructure:"sink"`
+	// Dir holds the secret files of the file sink; defaults to ~/.go-sui-mcp/secrets
+	Dir string `mapstructure:"dir"`
+	// URL is the endpoint of the http sink
+	URL string `mapstructure:"url"`
+}
+
+// AuditConfig controls the hash-chained log of tool invocations
+type AuditConfig struct {
+	// Enabled turns the audit log on; it is off by default
+	Enabled bool `mapstructure:"enabled"`
+	// Path is the JSON-lines log file; defaults to ~/.go-sui-mcp/audit.jsonl
+	Path string `mapstructure:"path"`
+}
+
+// SubscriptionsConfig tunes how subscribed sessions are kept up to date
+type SubscriptionsConfig struct {
+	// PollInterval is how often the fullnode is checked for a new checkpoint
+	PollInterval time.Duration `mapstructure:"poll_interval"`
+	// MaxPerSession caps the subscriptions one client session may hold
+	MaxPerSession int `mapstructure:"max_per_session"`
+}
+
+// defaultToolTimeouts are used for long-running tools that have no configured override
+var defaultToolTimeouts = map[string]time.Duration{
+	"sui-faucet":     2 * time.Minute,
+	"sui-call":       2 * time.Minute,
+	"sui-publish":    5 * time.Minute,
+	"sui-move-build": 5 * time.Minute,
+	"sui-move-test":  10 * time.Minute,
+}
+
+// For returns the timeout that applies to the named tool
+func (t TimeoutConfig) For(tool string) time.Duration {
+	if d, ok := t.Tools[tool]; ok {
+		return d
+	}
+	if d, ok := defaultToolTimeouts[tool]; ok {
+		return d
+	}
+	return t.Default
+}
+
+// Load loads the configuration from viper and validates it. Keys that do not
+// belong to Config, such as typos in the config file, are rejected.
 func Load() (*Config, error) {
 	var config Config
 
 	// Set default values
 	setDefaults()
+	// Let GOSUI_* variables override keys that have no default
+	bindEnv(reflect.TypeOf(config), "")
 
-	if err := viper.Unmarshal(&config); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+	if err := viper.UnmarshalExact(&config); err != nil {
+		// Decode leniently as well so unknown keys are reported together with any other problems
+		if lenientErr := viper.Unmarshal(&config); lenientErr != nil {
+			return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+		}
+		return nil, fmt.Errorf("invalid config:\n%w", errors.Join(err, config.Validate()))
+	}
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config:\n%w", err)
 	}
 
 	return &config, nil
 }
 
+// bindEnv binds an environment variable to every scalar and string list key of t.
+// Viper only consults the environment for keys it already knows about.
+func bindEnv(t reflect.Type, prefix string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		key := prefix + field.Tag.Get("mapstructure")
+		switch {
+		case field.Type.Kind() == reflect.Struct:
+			bindEnv(field.Type, key+".")
+		case field.Type.Kind() == reflect.Map:
+		case field.Type.Kind() == reflect.Slice && field.Type.Elem().Kind() != reflect.String:
+		default:
+			viper.BindEnv(key)
+		}
+	}
+}
+
 // setDefaults sets default values for configuration items
 func setDefaults() {
 	viper.SetDefault("server.port", 8080)
-	viper.SetDefault("server.host", "0.0.0.0")
+	viper.SetDefault("server.host", "127.0.0.1")
+	viper.SetDefault("server.read_only", false)
+	viper.SetDefault("server.transport", "stdio")
+	viper.SetDefault("server.http.endpoint_path", "/mcp")
+	viper.SetDefault("server.http.heartbeat_interval", "30s")
+	viper.SetDefault("server.http.session_idle_ttl", "30m")
 	viper.SetDefault("sui.executable_path", "sui")
+	viper.SetDefault("sui.backend", "cli")
+	viper.SetDefault("timeouts.default", "30s")
+	viper.SetDefault("timeouts.preview", "1m")
+	viper.SetDefault("approval.mode", "none")
+	viper.SetDefault("approval.timeout", "5m")
+	viper.SetDefault("policy.enabled", false)
+	viper.SetDefault("secrets.enabled", false)
+	viper.SetDefault("secrets.sink", "file")
+	viper.SetDefault("audit.enabled", false)
+	viper.SetDefault("subscriptions.poll_interval", "5s")
+	viper.SetDefault("subscriptions.max_per_session", 20)
+	if home, err := os.UserHomeDir(); err == nil {
+		viper.SetDefault("sui.client_config", filepath.Join(home, ".sui", "sui_config", "client.yaml"))
+		viper.SetDefault("sui.env_config_dir", filepath.Join(home, ".go-sui-mcp", "envs"))
+		viper.SetDefault("policy.state_file", filepath.Join(home, ".go-sui-mcp", "spending.json"))
+		viper.SetDefault("audit.path", filepath.Join(home, ".go-sui-mcp", "audit.jsonl"))
+	}
 }
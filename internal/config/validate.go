@@ -0,0 +1,157 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	// addressPattern matches a Sui address in short or full hex form
+	addressPattern = regexp.MustCompile(`^0x[0-9a-fA-F]{1,64}$`)
+	// coinTypePattern matches a fully qualified Move type such as 0x2::sui::SUI
+	coinTypePattern = regexp.MustCompile(`^0x[0-9a-fA-F]{1,64}::\w+::\w+(<.+>)?$`)
+)
+
+// Validate checks every section and returns all problems found, each prefixed with its config key
+func (c *Config) Validate() error {
+	var errs []error
+	fail := func(key, format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%s: %s", key, fmt.Sprintf(format, args...)))
+	}
+
+	// server
+	if c.Server.Port < 1 || c.Server.Port > 65535 {
+		fail("server.port", "%d is not in 1-65535", c.Server.Port)
+	}
+	switch c.Server.Transport {
+	case "stdio", "sse", "http":
+	default:
+		fail("server.transport", "%q is not one of stdio, sse, http", c.Server.Transport)
+	}
+	if c.Server.BaseURL != "" {
+		if u, err := url.Parse(c.Server.BaseURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			fail("server.base_url", "%q is not an absolute http(s) URL", c.Server.BaseURL)
+		}
+	}
+	if (c.Server.TLS.CertFile == "") != (c.Server.TLS.KeyFile == "") {
+		fail("server.tls", "cert_file and key_file must be set together")
+	}
+	if c.Server.TLS.ClientCAFile != "" && c.Server.TLS.CertFile == "" {
+		fail("server.tls.client_ca_file", "requires cert_file and key_file")
+	}
+	if c.Server.HTTP.HeartbeatInterval < 0 {
+		fail("server.http.heartbeat_interval", "must not be negative")
+	}
+	if c.Server.HTTP.SessionIdleTTL < 0 {
+		fail("server.http.session_idle_ttl", "must not be negative")
+	}
+	seenTokens := map[string]bool{}
+	for i, t := range c.Server.Auth.Tokens {
+		key := fmt.Sprintf("server.auth.tokens[%d]", i)
+		if t.Token == "" {
+			fail(key+".token", "must not be empty")
+		} else if seenTokens[t.Token] {
+			fail(key+".token", "is used by another entry")
+		}
+		seenTokens[t.Token] = true
+		if _, ok := c.Server.Auth.Roles[t.Role]; !ok {
+			fail(key+".role", "unknown role %q", t.Role)
+		}
+	}
+
+	// sui
+	switch c.Sui.Backend {
+	case "cli":
+		if _, err := exec.LookPath(c.Sui.ExecutablePath); err != nil {
+			fail("sui.executable_path", "%v", err)
+		}
+	case "rpc":
+		if u, err := url.Parse(c.Sui.RPCURL); c.Sui.RPCURL == "" || err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			fail("sui.rpc_url", "an http(s) URL is required when sui.backend is rpc")
+		}
+	default:
+		fail("sui.backend", "%q is not one of cli, rpc", c.Sui.Backend)
+	}
+
+	if c.Sui.Env != "" && (c.Sui.ClientConfig == "" || c.Sui.EnvConfigDir == "") {
+		fail("sui.env", "requires sui.client_config and sui.env_config_dir")
+	}
+
+	// timeouts
+	if c.Timeouts.Default < 0 {
+		fail("timeouts.default", "must not be negative")
+	}
+	for tool, d := range c.Timeouts.Tools {
+		if d < 0 {
+			fail("timeouts.tools."+tool, "must not be negative")
+		}
+	}
+	if c.Timeouts.Preview < 0 {
+		fail("timeouts.preview", "must not be negative")
+	}
+
+	// approval
+	switch c.Approval.Mode {
+	case "none", "elicitation":
+	case "http":
+		if c.Approval.URL == "" {
+			fail("approval.url", "is required when approval.mode is http")
+		}
+	default:
+		fail("approval.mode", "%q is not one of none, elicitation, http", c.Approval.Mode)
+	}
+
+	// policy
+	for coinType, limit := range c.Policy.CoinCaps {
+		if !coinTypePattern.MatchString(coinType) {
+			fail("policy.coin_caps", "%q is not a coin type like 0x2::sui::SUI", coinType)
+		}
+		if limit.PerTx == 0 && limit.PerDay == 0 {
+			fail("policy.coin_caps."+coinType, "sets neither per_tx nor per_day")
+		}
+	}
+	for i, addr := range c.Policy.AllowedRecipients {
+		if !addressPattern.MatchString(addr) {
+			fail(fmt.Sprintf("policy.allowed_recipients[%d]", i), "%q is not a hex address", addr)
+		}
+	}
+	for i, addr := range c.Policy.BlockedRecipients {
+		if !addressPattern.MatchString(addr) {
+			fail(fmt.Sprintf("policy.blocked_recipients[%d]", i), "%q is not a hex address", addr)
+		}
+	}
+	if c.Policy.Enabled && c.Policy.StateFile == "" {
+		fail("policy.state_file", "is required when the policy is enabled")
+	}
+
+	// secrets
+	switch c.Secrets.Sink {
+	case "file":
+	case "http":
+		if c.Secrets.URL == "" {
+			fail("secrets.url", "is required when secrets.sink is http")
+		}
+	default:
+		fail("secrets.sink", "%q is not one of file, http", c.Secrets.Sink)
+	}
+
+	// audit
+	if c.Audit.Enabled && strings.TrimSpace(c.Audit.Path) == "" {
+		fail("audit.path", "is required when the audit log is enabled")
+	}
+
+	// subscriptions
+	if c.Subscriptions.PollInterval < time.Second {
+		fail("subscriptions.poll_interval", "must be at least 1s")
+	}
+	if c.Subscriptions.MaxPerSession < 1 {
+		fail("subscriptions.max_per_session", "must be at least 1")
+	}
+
+	return errors.Join(errs...)
+}
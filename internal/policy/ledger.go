@@ -0,0 +1,91 @@
+package policy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Entry is one committed spend of a coin type
+type Entry struct {
+	At       time.Time `json:"at"`
+	CoinType string    `json:"coinType"`
+	Amount   string    `json:"amount"`
+}
+
+// Ledger keeps recent spending on disk so rolling limits survive restarts
+type Ledger struct {
+	path    string
+	entries []Entry
+}
+
+// OpenLedger loads the ledger at path; a missing file starts an empty ledger and
+// an empty path keeps the ledger in memory only
+func OpenLedger(path string) (*Ledger, error) {
+	l := &Ledger{path: path}
+	if path == "" {
+		return l, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return l, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read spending ledger: %w", err)
+	}
+	if err := json.Unmarshal(data, &l.entries); err != nil {
+		return nil, fmt.Errorf("failed to parse spending ledger %s: %w", path, err)
+	}
+	return l, nil
+}
+
+// Add appends an entry without saving
+func (l *Ledger) Add(e Entry) {
+	l.entries = append(l.entries, e)
+}
+
+// Total sums the entries of coinType recorded after since
+func (l *Ledger) Total(coinType string, since time.Time) *big.Int {
+	total := new(big.Int)
+	for _, e := range l.entries {
+		if e.CoinType != coinType || !e.At.After(since) {
+			continue
+		}
+		if amount, ok := new(big.Int).SetString(e.Amount, 10); ok {
+			total.Add(total, amount)
+		}
+	}
+	return total
+}
+
+// Save drops entries older than cutoff and atomically writes the rest to disk
+func (l *Ledger) Save(cutoff time.Time) error {
+	kept := l.entries[:0]
+	for _, e := range l.entries {
+		if e.At.After(cutoff) {
+			kept = append(kept, e)
+		}
+	}
+	l.entries = kept
+
+	if l.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(l.entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(l.path), 0o700); err != nil {
+		return fmt.Errorf("failed to create ledger directory: %w", err)
+	}
+	tmp := l.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write spending ledger: %w", err)
+	}
+	return os.Rename(tmp, l.path)
+}
@@ -0,0 +1,235 @@
+// Package policy enforces spending limits and recipient rules on payment tools.
+package policy
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/krli/go-sui-mcp/internal/config"
+)
+
+// suiCoinType is the normalised type of the native coin, which the MIST limits apply to
+const suiCoinType = "0x2::sui::SUI"
+
+// window is the length of the rolling spending period
+const window = 24 * time.Hour
+
+// Violation is returned when a payment breaks a configured rule
+type Violation struct {
+	// Rule is the config key of the rule that tripped, e.g. "max_per_day_mist"
+	Rule   string
+	Detail string
+}
+
+func (v *Violation) Error() string {
+	return fmt.Sprintf("policy violation [%s]: %s", v.Rule, v.Detail)
+}
+
+// Payment is an outgoing transfer to be checked against the policy
+type Payment struct {
+	Recipients []string
+	// Amounts maps a coin type to the total amount leaving the sender, in the coin's smallest unit
+	Amounts map[string]*big.Int
+	// Role and RoleMaxPerTxMist add the authenticated caller's role limit on top of the configured ones
+	Role             string
+	RoleMaxPerTxMist uint64
+}
+
+// Engine checks payments against the configured limits and tracks rolling totals
+type Engine struct {
+	cfg     config.PolicyConfig
+	ledger  *Ledger
+	now     func() time.Time
+	mu      sync.Mutex
+	pending map[*Reservation]struct{}
+}
+
+// Reservation holds a payment's amounts against the limits until it is committed or released
+type Reservation struct {
+	engine  *Engine
+	at      time.Time
+	amounts map[string]*big.Int
+}
+
+// New creates an engine and loads the persisted spending ledger
+func New(cfg config.PolicyConfig) (*Engine, error) {
+	ledger, err := OpenLedger(cfg.StateFile)
+	if err != nil {
+		return nil, err
+	}
+	return &Engine{
+		cfg:     cfg,
+		ledger:  ledger,
+		now:     time.Now,
+		pending: make(map[*Reservation]struct{}),
+	}, nil
+}
+
+// Reserve checks the payment against every rule and, if it passes, holds its
+// amounts so concurrent payments cannot overshoot the rolling limits. The
+// caller must Commit the reservation once the transaction succeeds or Release it.
+func (e *Engine) Reserve(p Payment) (*Reservation, error) {
+	if err := e.CheckRecipients(p.Recipients); err != nil {
+		return nil, err
+	}
+
+	amounts := make(map[string]*big.Int, len(p.Amounts))
+	for coinType, amount := range p.Amounts {
+		amounts[NormalizeCoinType(coinType)] = amount
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := e.now()
+	for coinType, amount := range amounts {
+		if roleMax := nonZero(p.RoleMaxPerTxMist); roleMax != nil && coinType == suiCoinType && amount.Cmp(roleMax) > 0 {
+			return nil, &Violation{
+				Rule:   "server.auth.roles." + p.Role + ".max_per_tx_mist",
+				Detail: fmt.Sprintf("sending %s MIST exceeds the per-transaction limit of %s for role %q", amount, roleMax, p.Role),
+			}
+		}
+		limits := e.limitsFor(coinType)
+		if limits.perTx != nil && amount.Cmp(limits.perTx) > 0 {
+			return nil, &Violation{
+				Rule:   limits.perTxRule,
+				Detail: fmt.Sprintf("sending %s of %s exceeds the per-transaction limit of %s", amount, coinType, limits.perTx),
+			}
+		}
+		if limits.perDay != nil {
+			spent := e.spentLocked(coinType, now)
+			total := new(big.Int).Add(spent, amount)
+			if total.Cmp(limits.perDay) > 0 {
+				return nil, &Violation{
+					Rule:   limits.perDayRule,
+					Detail: fmt.Sprintf("sending %s of %s would bring the 24h total to %s, above the limit of %s (already spent %s)", amount, coinType, total, limits.perDay, spent),
+				}
+			}
+		}
+	}
+
+	r := &Reservation{engine: e, at: now, amounts: amounts}
+	e.pending[r] = struct{}{}
+	return r, nil
+}
+
+// Commit records the reserved amounts in the persisted ledger
+func (r *Reservation) Commit() error {
+	e := r.engine
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	delete(e.pending, r)
+	for coinType, amount := range r.amounts {
+		e.ledger.Add(Entry{At: r.at, CoinType: coinType, Amount: amount.String()})
+	}
+	return e.ledger.Save(e.now().Add(-window))
+}
+
+// Release drops the reservation without recording any spending
+func (r *Reservation) Release() {
+	e := r.engine
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	delete(e.pending, r)
+}
+
+// CheckRecipients applies the blocked and allowed recipient lists
+func (e *Engine) CheckRecipients(recipients []string) error {
+	for _, recipient := range recipients {
+		addr := normalizeAddress(recipient)
+		for _, blocked := range e.cfg.BlockedRecipients {
+			if normalizeAddress(blocked) == addr {
+				return &Violation{Rule: "blocked_recipients", Detail: fmt.Sprintf("recipient %s is blocked", recipient)}
+			}
+		}
+		if len(e.cfg.AllowedRecipients) == 0 {
+			continue
+		}
+		allowed := false
+		for _, a := range e.cfg.AllowedRecipients {
+			if normalizeAddress(a) == addr {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return &Violation{Rule: "allowed_recipients", Detail: fmt.Sprintf("recipient %s is not on the allowlist", recipient)}
+		}
+	}
+	return nil
+}
+
+// limits are the caps that apply to one coin type; a nil cap means unlimited
+type limits struct {
+	perTx, perDay         *big.Int
+	perTxRule, perDayRule string
+}
+
+// limitsFor returns the caps for a coin type along with the config keys they came from
+func (e *Engine) limitsFor(coinType string) limits {
+	// Viper lower-cases map keys, so configured coin types are matched case-insensitively
+	for configured, limit := range e.cfg.CoinCaps {
+		if strings.EqualFold(NormalizeCoinType(configured), coinType) {
+			return limits{
+				perTx:      nonZero(limit.PerTx),
+				perDay:     nonZero(limit.PerDay),
+				perTxRule:  "coin_caps." + configured + ".per_tx",
+				perDayRule: "coin_caps." + configured + ".per_day",
+			}
+		}
+	}
+	if coinType == suiCoinType {
+		return limits{
+			perTx:      nonZero(e.cfg.MaxPerTxMist),
+			perDay:     nonZero(e.cfg.MaxPerDayMist),
+			perTxRule:  "max_per_tx_mist",
+			perDayRule: "max_per_day_mist",
+		}
+	}
+	return limits{}
+}
+
+// spentLocked sums committed and pending spending of coinType within the rolling window
+func (e *Engine) spentLocked(coinType string, now time.Time) *big.Int {
+	total := e.ledger.Total(coinType, now.Add(-window))
+	for r := range e.pending {
+		if amount, ok := r.amounts[coinType]; ok {
+			total.Add(total, amount)
+		}
+	}
+	return total
+}
+
+func nonZero(v uint64) *big.Int {
+	if v == 0 {
+		return nil
+	}
+	return new(big.Int).SetUint64(v)
+}
+
+// NormalizeCoinType lower-cases the package address of a coin type and strips
+// its leading zeros so "0x0000…0002::sui::SUI" and "0x2::sui::SUI" compare equal
+func NormalizeCoinType(coinType string) string {
+	addr, rest, ok := strings.Cut(coinType, "::")
+	if !ok {
+		return coinType
+	}
+	hex := strings.TrimLeft(strings.TrimPrefix(strings.ToLower(addr), "0x"), "0")
+	if hex == "" {
+		hex = "0"
+	}
+	return "0x" + hex + "::" + rest
+}
+
+// normalizeAddress lower-cases an address and pads it to 32 bytes
+func normalizeAddress(addr string) string {
+	hex := strings.TrimPrefix(strings.ToLower(strings.TrimSpace(addr)), "0x")
+	if len(hex) < 64 {
+		hex = strings.Repeat("0", 64-len(hex)) + hex
+	}
+	return "0x" + hex
+}
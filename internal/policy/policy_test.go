@@ -0,0 +1,243 @@
+package policy
+
+import (
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/krli/go-sui-mcp/internal/config"
+)
+
+const (
+	alice = "0x00000000000000000000000000000000000000000000000000000000000a11ce"
+	bob   = "0x0000000000000000000000000000000000000000000000000000000000000b0b"
+	usdc  = "0xdba34672e30cb065b1f93e3ab55318768fd6fef66c15942c9f7cb846e2f900e7::usdc::USDC"
+)
+
+func sui(mist int64) map[string]*big.Int {
+	return map[string]*big.Int{suiCoinType: big.NewInt(mist)}
+}
+
+// newEngine creates an engine whose clock is fixed at now
+func newEngine(t *testing.T, cfg config.PolicyConfig, now time.Time) *Engine {
+	t.Helper()
+	e, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	e.now = func() time.Time { return now }
+	return e
+}
+
+// wantRule fails unless err is a Violation of rule, or nil when rule is ""
+func wantRule(t *testing.T, err error, rule string) {
+	t.Helper()
+	var v *Violation
+	switch {
+	case rule == "" && err != nil:
+		t.Errorf("unexpected refusal: %v", err)
+	case rule != "" && !errors.As(err, &v):
+		t.Errorf("err = %v, want a violation of %s", err, rule)
+	case rule != "" && v.Rule != rule:
+		t.Errorf("violated %s, want %s", v.Rule, rule)
+	}
+}
+
+func TestReserve(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.PolicyConfig
+		// spent are payments committed before the checked one
+		spent   []Payment
+		payment Payment
+		// wantRule is the rule the payment breaks, or "" if it passes
+		wantRule string
+	}{
+		{
+			name:    "no limits",
+			payment: Payment{Recipients: []string{bob}, Amounts: sui(1 << 62)},
+		},
+		{
+			name:     "over per-tx limit",
+			cfg:      config.PolicyConfig{MaxPerTxMist: 1000},
+			payment:  Payment{Recipients: []string{bob}, Amounts: sui(1001)},
+			wantRule: "max_per_tx_mist",
+		},
+		{
+			name:    "at per-tx limit",
+			cfg:     config.PolicyConfig{MaxPerTxMist: 1000},
+			payment: Payment{Recipients: []string{bob}, Amounts: sui(1000)},
+		},
+		{
+			name:     "over per-day limit with earlier spending",
+			cfg:      config.PolicyConfig{MaxPerDayMist: 1000},
+			spent:    []Payment{{Amounts: sui(600)}},
+			payment:  Payment{Recipients: []string{bob}, Amounts: sui(500)},
+			wantRule: "max_per_day_mist",
+		},
+		{
+			name:    "coin cap overrides the MIST limit",
+			cfg:     config.PolicyConfig{MaxPerTxMist: 10, CoinCaps: map[string]config.CoinCap{"0x2::sui::SUI": {PerTx: 1000}}},
+			payment: Payment{Recipients: []string{bob}, Amounts: sui(500)},
+		},
+		{
+			name:     "coin cap of another coin type",
+			cfg:      config.PolicyConfig{CoinCaps: map[string]config.CoinCap{usdc: {PerDay: 100}}},
+			spent:    []Payment{{Amounts: map[string]*big.Int{usdc: big.NewInt(80)}}},
+			payment:  Payment{Recipients: []string{bob}, Amounts: map[string]*big.Int{usdc: big.NewInt(30)}},
+			wantRule: "coin_caps." + usdc + ".per_day",
+		},
+		{
+			name:    "MIST limits ignore other coin types",
+			cfg:     config.PolicyConfig{MaxPerTxMist: 10},
+			payment: Payment{Recipients: []string{bob}, Amounts: map[string]*big.Int{usdc: big.NewInt(1000)}},
+		},
+		{
+			name:     "blocked recipient",
+			cfg:      config.PolicyConfig{BlockedRecipients: []string{"0xb0b"}},
+			payment:  Payment{Recipients: []string{bob}, Amounts: sui(1)},
+			wantRule: "blocked_recipients",
+		},
+		{
+			name:     "recipient not on the allowlist",
+			cfg:      config.PolicyConfig{AllowedRecipients: []string{alice}},
+			payment:  Payment{Recipients: []string{bob}, Amounts: sui(1)},
+			wantRule: "allowed_recipients",
+		},
+		{
+			// policy.enabled=false builds the engine from an empty config, so only the role limit applies
+			name:     "role limit without a policy",
+			payment:  Payment{Recipients: []string{bob}, Amounts: sui(1001), Role: "agent", RoleMaxPerTxMist: 1000},
+			wantRule: "server.auth.roles.agent.max_per_tx_mist",
+		},
+		{
+			name:    "role limit within bounds",
+			payment: Payment{Recipients: []string{bob}, Amounts: sui(1000), Role: "agent", RoleMaxPerTxMist: 1000},
+		},
+		{
+			name:    "role limit only caps SUI",
+			payment: Payment{Recipients: []string{bob}, Amounts: map[string]*big.Int{usdc: big.NewInt(5000)}, Role: "agent", RoleMaxPerTxMist: 1000},
+		},
+		{
+			name:     "role limit is stricter than the policy",
+			cfg:      config.PolicyConfig{MaxPerTxMist: 5000},
+			payment:  Payment{Recipients: []string{bob}, Amounts: sui(2000), Role: "agent", RoleMaxPerTxMist: 1000},
+			wantRule: "server.auth.roles.agent.max_per_tx_mist",
+		},
+	}
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			e := newEngine(t, tc.cfg, now)
+			for _, p := range tc.spent {
+				r, err := e.Reserve(p)
+				if err != nil {
+					t.Fatalf("earlier payment refused: %v", err)
+				}
+				if err := r.Commit(); err != nil {
+					t.Fatal(err)
+				}
+			}
+			_, err := e.Reserve(tc.payment)
+			wantRule(t, err, tc.wantRule)
+		})
+	}
+}
+
+func TestReservationsHoldUntilReleased(t *testing.T) {
+	e := newEngine(t, config.PolicyConfig{MaxPerDayMist: 1000}, time.Now())
+
+	first, err := e.Reserve(Payment{Amounts: sui(600)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A concurrent payment counts the pending one against the limit
+	_, err = e.Reserve(Payment{Amounts: sui(600)})
+	wantRule(t, err, "max_per_day_mist")
+
+	// A failed transaction releases its reservation without spending anything
+	first.Release()
+	second, err := e.Reserve(Payment{Amounts: sui(600)})
+	wantRule(t, err, "")
+	if err := second.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = e.Reserve(Payment{Amounts: sui(600)})
+	wantRule(t, err, "max_per_day_mist")
+}
+
+func TestLedgerSurvivesRestart(t *testing.T) {
+	cfg := config.PolicyConfig{MaxPerDayMist: 1000, StateFile: filepath.Join(t.TempDir(), "state", "spending.json")}
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	e := newEngine(t, cfg, now)
+	r, err := e.Reserve(Payment{Amounts: sui(700)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Commit(); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+	// Released reservations are never persisted
+	r, err = e.Reserve(Payment{Amounts: sui(200)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Release()
+
+	tests := []struct {
+		name     string
+		at       time.Time
+		amount   int64
+		wantRule string
+	}{
+		{"committed spending is reloaded", now.Add(time.Hour), 400, "max_per_day_mist"},
+		{"the rest of the limit is still available", now.Add(time.Hour), 300, ""},
+		{"spending leaves the rolling window", now.Add(window + time.Minute), 1000, ""},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			restarted := newEngine(t, cfg, tc.at)
+			_, err := restarted.Reserve(Payment{Amounts: sui(tc.amount)})
+			wantRule(t, err, tc.wantRule)
+		})
+	}
+}
+
+func TestOpenLedgerRejectsCorruptState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spending.json")
+	l, err := OpenLedger(path)
+	if err != nil {
+		t.Fatalf("missing ledger: %v", err)
+	}
+	l.Add(Entry{At: time.Now(), CoinType: suiCoinType, Amount: "1"})
+	if err := l.Save(time.Now().Add(-window)); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("{not json"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenLedger(path); err == nil {
+		t.Error("corrupt ledger was accepted, which would reset the spending limits")
+	}
+}
+
+func TestNormalizeCoinType(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"0x2::sui::SUI", "0x2::sui::SUI"},
+		{"0x0000000000000000000000000000000000000000000000000000000000000002::sui::SUI", "0x2::sui::SUI"},
+		{"0xDBA3::usdc::USDC", "0xdba3::usdc::USDC"},
+		{"0x0::m::T", "0x0::m::T"},
+		{"not-a-type", "not-a-type"},
+	}
+	for _, tc := range tests {
+		if got := NormalizeCoinType(tc.in); got != tc.want {
+			t.Errorf("NormalizeCoinType(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// FileSink writes every secret to a new file readable only by the current user
+type FileSink struct {
+	dir string
+}
+
+// NewFileSink creates a sink writing into dir, which is created with 0700 perms if needed
+func NewFileSink(dir string) *FileSink {
+	return &FileSink{dir: dir}
+}
+
+// Store writes the secret and returns the file path
+func (f *FileSink) Store(ctx context.Context, secret Secret) (string, error) {
+	if err := os.MkdirAll(f.dir, 0o700); err != nil {
+		return "", fmt.Errorf("failed to create secrets directory: %w", err)
+	}
+
+	name := secret.Kind
+	if secret.Label != "" {
+		name += "-" + secret.Label
+	}
+	name = fmt.Sprintf("%s-%s.txt", sanitize(name), time.Now().UTC().Format("20060102T150405.000000000Z"))
+	path := filepath.Join(f.dir, name)
+
+	// O_EXCL so an existing file, or a symlink planted in its place, is never overwritten
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return "", fmt.Errorf("failed to create secret file: %w", err)
+	}
+	if _, err := file.WriteString(secret.Value); err != nil {
+		file.Close()
+		os.Remove(path)
+		return "", fmt.Errorf("failed to write secret file: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// sanitize keeps file names to a safe character set
+func sanitize(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' {
+			return r
+		}
+		return '_'
+	}, name)
+}
@@ -0,0 +1,67 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// HTTPSink posts each Secret as JSON to an endpoint that stores it elsewhere,
+// e.g. a bridge to a password manager. The endpoint must answer 200 with
+// {"ref": "..."} naming where the secret can be retrieved.
+type HTTPSink struct {
+	url        string
+	httpClient *http.Client
+}
+
+// NewHTTPSink creates a sink for the endpoint at url
+func NewHTTPSink(url string, httpClient *http.Client) *HTTPSink {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &HTTPSink{
+		url:        url,
+		httpClient: httpClient,
+	}
+}
+
+// Store posts the secret and returns the reference the endpoint assigned
+func (h *HTTPSink) Store(ctx context.Context, secret Secret) (string, error) {
+	body, err := json.Marshal(secret)
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.url, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := h.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("secret sink unreachable: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", fmt.Errorf("secret sink returned %s: %s", resp.Status, bytes.TrimSpace(data))
+	}
+
+	var stored struct {
+		Ref string `json:"ref"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&stored); err != nil {
+		return "", fmt.Errorf("invalid secret sink response: %w", err)
+	}
+	if stored.Ref == "" {
+		return "", errors.New("secret sink returned an empty ref")
+	}
+	return stored.Ref, nil
+}
@@ -0,0 +1,261 @@
+package secrets
+
+import (
+	_ "embed"
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Redacted replaces every secret removed from output
+const Redacted = "[REDACTED]"
+
+// minMnemonicWords is the length of the shortest BIP-39 recovery phrase
+const minMnemonicWords = 12
+
+// secretFields are the JSON field names whose string values are key material
+var secretFields = []string{"mnemonic", "recoveryPhrase", "exportedPrivateKey", "privateKey", "secretKey"}
+
+var (
+	// privateKeyPattern matches Bech32 encoded Sui private keys
+	privateKeyPattern = regexp.MustCompile(`suiprivkey1[02-9ac-hj-np-z]{20,}`)
+	// secretFieldPattern matches JSON string fields that hold key material
+	secretFieldPattern = regexp.MustCompile(`("(?:` + strings.Join(secretFields, "|") + `)"\s*:\s*)"[^"]*"`)
+	// wordRunPattern matches runs of at least 12 lower-case words the length of
+	// BIP-39 words; redactMnemonics then checks them against the wordlist
+	wordRunPattern = regexp.MustCompile(`\b[a-z]{3,8}(?: [a-z]{3,8}){11,}\b`)
+)
+
+// bip39English is the BIP-39 English wordlist that Sui recovery phrases are drawn from
+//
+//go:embed bip39_english.txt
+var bip39English string
+
+var mnemonicWords = func() map[string]bool {
+	words := make(map[string]bool, 2048)
+	for _, word := range strings.Fields(bip39English) {
+		words[word] = true
+	}
+	return words
+}()
+
+// Redact removes private keys and recovery phrases from s
+func Redact(s string) string {
+	s = privateKeyPattern.ReplaceAllString(s, Redacted)
+	s = secretFieldPattern.ReplaceAllString(s, `$1"`+Redacted+`"`)
+	return wordRunPattern.ReplaceAllStringFunc(s, redactMnemonics)
+}
+
+// redactMnemonics replaces every run of 12 or more BIP-39 words in a run of
+// words, so ordinary prose that happens to contain short words is left alone
+func redactMnemonics(run string) string {
+	words := strings.Split(run, " ")
+	var out []string
+	start := 0
+	flush := func(end int) {
+		if end-start >= minMnemonicWords {
+			out = append(out, Redacted)
+		} else {
+			out = append(out, words[start:end]...)
+		}
+	}
+	for i, word := range words {
+		if !mnemonicWords[word] {
+			flush(i)
+			out = append(out, word)
+			start = i + 1
+		}
+	}
+	flush(len(words))
+	return strings.Join(out, " ")
+}
+
+// RedactValue redacts the strings of a JSON-serialisable value. It returns v
+// unchanged when nothing needed redacting, otherwise a copy of the same type
+// with the secrets replaced.
+func RedactValue(v any) any {
+	if v == nil {
+		return v
+	}
+	out, changed := redactReflect(reflect.ValueOf(v))
+	if changed {
+		v = out.Interface()
+	}
+
+	// Secrets behind a custom MarshalJSON are out of reach of the walk above;
+	// fall back to redacting the encoded form
+	data, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	redacted := Redact(string(data))
+	if redacted == string(data) {
+		return v
+	}
+	var generic any
+	if err := json.Unmarshal([]byte(redacted), &generic); err != nil {
+		return Redacted
+	}
+	return generic
+}
+
+var rawMessageType = reflect.TypeOf(json.RawMessage(nil))
+
+// redactReflect returns a copy of v with every string it reaches redacted, and
+// whether anything changed; v itself is never modified
+func redactReflect(v reflect.Value) (reflect.Value, bool) {
+	switch v.Kind() {
+	case reflect.String:
+		redacted := Redact(v.String())
+		if redacted == v.String() {
+			return v, false
+		}
+		out := reflect.New(v.Type()).Elem()
+		out.SetString(redacted)
+		return out, true
+
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return v, false
+		}
+		elem, changed := redactReflect(v.Elem())
+		if !changed {
+			return v, false
+		}
+		if v.Kind() == reflect.Interface {
+			out := reflect.New(v.Type()).Elem()
+			out.Set(elem)
+			return out, true
+		}
+		out := reflect.New(v.Type().Elem())
+		out.Elem().Set(elem)
+		return out, true
+
+	case reflect.Struct:
+		var out reflect.Value
+		for i := range v.NumField() {
+			structField := v.Type().Field(i)
+			if !structField.IsExported() {
+				continue
+			}
+			name, _, _ := strings.Cut(structField.Tag.Get("json"), ",")
+			if name == "" {
+				name = structField.Name
+			}
+			field, changed := redactField(name, v.Field(i))
+			if !changed {
+				continue
+			}
+			if !out.IsValid() {
+				out = reflect.New(v.Type()).Elem()
+				out.Set(v)
+			}
+			out.Field(i).Set(field)
+		}
+		if !out.IsValid() {
+			return v, false
+		}
+		return out, true
+
+	case reflect.Slice:
+		if v.IsNil() {
+			return v, false
+		}
+		if v.Type() == rawMessageType {
+			raw := string(v.Bytes())
+			redacted := Redact(raw)
+			if redacted == raw {
+				return v, false
+			}
+			return reflect.ValueOf(json.RawMessage(redacted)), true
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return v, false
+		}
+		var out reflect.Value
+		for i := range v.Len() {
+			elem, changed := redactReflect(v.Index(i))
+			if !changed {
+				continue
+			}
+			if !out.IsValid() {
+				out = reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+				reflect.Copy(out, v)
+			}
+			out.Index(i).Set(elem)
+		}
+		if !out.IsValid() {
+			return v, false
+		}
+		return out, true
+
+	case reflect.Array:
+		var out reflect.Value
+		for i := range v.Len() {
+			elem, changed := redactReflect(v.Index(i))
+			if !changed {
+				continue
+			}
+			if !out.IsValid() {
+				out = reflect.New(v.Type()).Elem()
+				out.Set(v)
+			}
+			out.Index(i).Set(elem)
+		}
+		if !out.IsValid() {
+			return v, false
+		}
+		return out, true
+
+	case reflect.Map:
+		if v.IsNil() {
+			return v, false
+		}
+		var out reflect.Value
+		iter := v.MapRange()
+		for iter.Next() {
+			var elem reflect.Value
+			var changed bool
+			if iter.Key().Kind() == reflect.String {
+				elem, changed = redactField(iter.Key().String(), iter.Value())
+			} else {
+				elem, changed = redactReflect(iter.Value())
+			}
+			if !changed {
+				continue
+			}
+			if !out.IsValid() {
+				out = reflect.MakeMapWithSize(v.Type(), v.Len())
+				copyIter := v.MapRange()
+				for copyIter.Next() {
+					out.SetMapIndex(copyIter.Key(), copyIter.Value())
+				}
+			}
+			out.SetMapIndex(iter.Key(), elem)
+		}
+		if !out.IsValid() {
+			return v, false
+		}
+		return out, true
+	}
+	return v, false
+}
+
+// redactField redacts the value of a struct field or map entry, replacing the
+// whole string when the field is named like key material
+func redactField(name string, v reflect.Value) (reflect.Value, bool) {
+	if !slices.Contains(secretFields, name) {
+		return redactReflect(v)
+	}
+	for v.Kind() == reflect.Interface && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.String || v.String() == "" || v.String() == Redacted {
+		return redactReflect(v)
+	}
+	out := reflect.New(v.Type()).Elem()
+	out.SetString(Redacted)
+	return out, true
+}
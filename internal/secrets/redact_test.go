@@ -0,0 +1,135 @@
+package secrets
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const (
+	phrase12 = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+	phrase24 = "legal winner thank year wave sausage worth useful legal winner thank year wave sausage worth useful legal winner thank year wave sausage worth title"
+	privKey  = "suiprivkey1qzwant3mvqx5pfh3w8s4lk4nvhnvhf6ge5t0h8wp4wc6jmmz6ka3xjcyv6g"
+)
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "private key",
+			in:   "exported " + privKey + " for 0x1",
+			want: "exported [REDACTED] for 0x1",
+		},
+		{
+			name: "secret JSON field",
+			in:   `{"alias":"main","exportedPrivateKey":"AAECAw=="}`,
+			want: `{"alias":"main","exportedPrivateKey":"[REDACTED]"}`,
+		},
+		{
+			name: "12 word phrase in a keytool table",
+			in:   "│ recoveryPhrase │ " + phrase12 + " │",
+			want: "│ recoveryPhrase │ [REDACTED] │",
+		},
+		{
+			name: "24 word phrase",
+			in:   "Secret Recovery Phrase : [" + phrase24 + "]",
+			want: "Secret Recovery Phrase : [[REDACTED]]",
+		},
+		{
+			name: "phrase inside a longer run of words",
+			in:   "keytool generated " + phrase12 + " for the alias",
+			want: "keytool generated [REDACTED] for the alias",
+		},
+		{
+			name: "prose is not a phrase",
+			in:   "the transaction failed because the gas budget was lower than the minimum needed for this call on testnet",
+			want: "the transaction failed because the gas budget was lower than the minimum needed for this call on testnet",
+		},
+		{
+			name: "prose made mostly of wordlist words",
+			in:   "error could not find object owned by address please check that the object exists and try again later",
+			want: "error could not find object owned by address please check that the object exists and try again later",
+		},
+		{
+			name: "11 wordlist words are too few",
+			in:   strings.Repeat("abandon ", 10) + "about",
+			want: strings.Repeat("abandon ", 10) + "about",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := Redact(tc.in); got != tc.want {
+				t.Errorf("Redact(%q)\n got %q\nwant %q", tc.in, got, tc.want)
+			}
+		})
+	}
+}
+
+type keyInfo struct {
+	Alias    string            `json:"alias"`
+	Mnemonic string            `json:"mnemonic,omitempty"`
+	Notes    []string          `json:"notes"`
+	Extra    map[string]any    `json:"extra,omitempty"`
+	Raw      json.RawMessage   `json:"raw,omitempty"`
+	Labels   map[string]string `json:"labels,omitempty"`
+}
+
+func TestRedactValueKeepsType(t *testing.T) {
+	tests := []struct {
+		name string
+		in   any
+		want any
+	}{
+		{
+			name: "nothing to redact",
+			in:   keyInfo{Alias: "main", Notes: []string{"ok"}},
+			want: keyInfo{Alias: "main", Notes: []string{"ok"}},
+		},
+		{
+			name: "struct fields",
+			in:   keyInfo{Alias: "main", Mnemonic: "not even words", Notes: []string{"key " + privKey}},
+			want: keyInfo{Alias: "main", Mnemonic: Redacted, Notes: []string{"key " + Redacted}},
+		},
+		{
+			name: "pointer",
+			in:   &keyInfo{Alias: "main", Notes: []string{phrase12}},
+			want: &keyInfo{Alias: "main", Notes: []string{Redacted}},
+		},
+		{
+			name: "nested maps and raw JSON",
+			in: keyInfo{
+				Extra: map[string]any{"privateKey": "0xabc", "keep": 1.5},
+				Raw:   json.RawMessage(`{"secretKey":"abc"}`),
+			},
+			want: keyInfo{
+				Extra: map[string]any{"privateKey": Redacted, "keep": 1.5},
+				Raw:   json.RawMessage(`{"secretKey":"[REDACTED]"}`),
+			},
+		},
+		{
+			name: "generic map",
+			in:   map[string]any{"args": []any{"x", phrase24}},
+			want: map[string]any{"args": []any{"x", Redacted}},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := RedactValue(tc.in)
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("RedactValue() = %#v, want %#v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRedactValueDoesNotModifyInput(t *testing.T) {
+	in := &keyInfo{Alias: "main", Notes: []string{phrase12}, Labels: map[string]string{"mnemonic": phrase12}}
+	RedactValue(in)
+	if in.Notes[0] != phrase12 || in.Labels["mnemonic"] != phrase12 {
+		t.Errorf("input was modified: %+v", in)
+	}
+}
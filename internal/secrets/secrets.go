@@ -0,0 +1,59 @@
+// Package secrets keeps private keys and recovery phrases out of tool output.
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/krli/go-sui-mcp/internal/config"
+)
+
+// Sinks accepted by the secrets.sink setting
+const (
+	// SinkFile writes each secret to its own 0600 file
+	SinkFile = "file"
+	// SinkHTTP posts each secret to a local endpoint, such as a vault bridge
+	SinkHTTP = "http"
+)
+
+// Secret is sensitive CLI output that must not be returned to the model
+type Secret struct {
+	// Kind names the tool that produced the secret, e.g. "sui-keytool-export"
+	Kind string `json:"kind"`
+	// Label is a non-sensitive hint such as the address the key belongs to
+	Label string `json:"label,omitempty"`
+	Value string `json:"value"`
+}
+
+// Sink stores a secret and returns a reference that can safely be shown instead of it
+type Sink interface {
+	Store(ctx context.Context, secret Secret) (ref string, err error)
+}
+
+// New creates the sink selected by cfg; it returns nil when secret output is disabled
+func New(cfg config.SecretsConfig) (Sink, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	switch cfg.Sink {
+	case "", SinkFile:
+		dir := cfg.Dir
+		if dir == "" {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return nil, fmt.Errorf("secrets.dir is not set and the home directory is unknown: %w", err)
+			}
+			dir = filepath.Join(home, ".go-sui-mcp", "secrets")
+		}
+		return NewFileSink(dir), nil
+	case SinkHTTP:
+		if cfg.URL == "" {
+			return nil, fmt.Errorf("secrets.url is required when secrets.sink is %s", SinkHTTP)
+		}
+		return NewHTTPSink(cfg.URL, nil), nil
+	default:
+		return nil, fmt.Errorf("unknown secrets.sink %q (expected %s or %s)", cfg.Sink, SinkFile, SinkHTTP)
+	}
+}
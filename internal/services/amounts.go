@@ -0,0 +1,192 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/krli/go-sui-mcp/internal/policy"
+	"github.com/krli/go-sui-mcp/internal/sui"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// amountPattern matches amounts such as "1.5 SUI", "250USDC" and "1000000000"
+var amountPattern = regexp.MustCompile(`^(\d+)(?:\.(\d+))?\s*([A-Za-z][A-Za-z0-9_]*)?$`)
+
+// amountArg is an amount argument split into its digits and unit
+type amountArg struct {
+	whole, frac string
+	// unit is a coin symbol, or MIST; empty means the amount is already in base units
+	unit string
+}
+
+// parseAmountArg reads one amount argument. Strings may carry a unit; plain
+// JSON numbers are still accepted as base units as long as they are exact.
+func parseAmountArg(field string, v any) (amountArg, error) {
+	switch v := v.(type) {
+	case string:
+		m := amountPattern.FindStringSubmatch(strings.TrimSpace(v))
+		if m == nil {
+			return amountArg{}, fmt.Errorf(`%s: %q is not an amount; use a string such as "1.5 SUI", "250 USDC" or "1000000000"`, field, v)
+		}
+		arg := amountArg{whole: m[1], frac: m[2], unit: m[3]}
+		if arg.frac != "" && arg.unit == "" {
+			return amountArg{}, fmt.Errorf(`%s: %q has a fractional part but no unit; add the coin symbol, as in "%s SUI"`, field, v, v)
+		}
+		return arg, nil
+	case float64:
+		if v < 0 || v != math.Trunc(v) || v > 1<<53 {
+			return amountArg{}, fmt.Errorf(`%s: %v is not an exact amount as a JSON number; pass it as a string such as "1000000000" or "1.5 SUI"`, field, v)
+		}
+		return amountArg{whole: strconv.FormatFloat(v, 'f', -1, 64)}, nil
+	default:
+		return amountArg{}, fmt.Errorf(`%s must be a string such as "1.5 SUI"`, field)
+	}
+}
+
+// String renders the amount as it was given
+func (a amountArg) String() string {
+	s := a.whole
+	if a.frac != "" {
+		s += "." + a.frac
+	}
+	if a.unit != "" {
+		s += " " + a.unit
+	}
+	return s
+}
+
+// baseUnits converts the amount into the coin's smallest unit
+func (a amountArg) baseUnits(field string, decimals int) (*big.Int, error) {
+	if len(a.frac) > decimals {
+		return nil, fmt.Errorf("%s: %s has more than %d decimal places", field, a, decimals)
+	}
+	amount, _ := new(big.Int).SetString(a.whole+a.frac+strings.Repeat("0", decimals-len(a.frac)), 10)
+	// Coin balances are u64 on chain
+	if amount.BitLen() > 64 {
+		return nil, fmt.Errorf("%s: %s is more than the largest coin amount, %d base units", field, a, uint64(math.MaxUint64))
+	}
+	return amount, nil
+}
+
+// coinTypeFunc supplies the coin type amounts are denominated in. It is only
+// called when an amount carries a unit, so base-unit amounts need no lookups.
+type coinTypeFunc func(ctx context.Context) (string, error)
+
+// fixedCoinType is a coinTypeFunc for tools that only move one coin type
+func fixedCoinType(coinType string) coinTypeFunc {
+	return func(context.Context) (string, error) {
+		return coinType, nil
+	}
+}
+
+// objectCoinType is a coinTypeFunc that reads the coin type of a coin object
+func (s *SuiService) objectCoinType(objectID string) coinTypeFunc {
+	return func(ctx context.Context) (string, error) {
+		obj, err := s.client.GetObject(ctx, objectID)
+		if err != nil {
+			return "", err
+		}
+		coinType, ok := coinTypeOf(obj.Type)
+		if !ok {
+			return "", fmt.Errorf("object %s is a %s, not a coin", objectID, obj.Type)
+		}
+		return coinType, nil
+	}
+}
+
+// parseAmounts reads the named array argument into base units of the coin type
+func (s *SuiService) parseAmounts(ctx context.Context, request mcp.CallToolRequest, name string, coinType coinTypeFunc) ([]*big.Int, error) {
+	values, ok := request.GetArguments()[name].([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s must be an array", name)
+	}
+
+	decimals := make(map[string]int)
+	amounts := make([]*big.Int, len(values))
+	for i, v := range values {
+		field := fmt.Sprintf("%s[%d]", name, i)
+		arg, err := parseAmountArg(field, v)
+		if err != nil {
+			return nil, err
+		}
+		d, ok := decimals[strings.ToUpper(arg.unit)]
+		if !ok {
+			if d, err = s.unitDecimals(ctx, field, arg.unit, coinType); err != nil {
+				return nil, err
+			}
+			decimals[strings.ToUpper(arg.unit)] = d
+		}
+		if amounts[i], err = arg.baseUnits(field, d); err != nil {
+			return nil, err
+		}
+	}
+	return amounts, nil
+}
+
+// parseAmount reads the named optional argument into base units of the coin type; nil when absent
+func (s *SuiService) parseAmount(ctx context.Context, request mcp.CallToolRequest, name string, coinType coinTypeFunc) (*big.Int, error) {
+	v, ok := request.GetArguments()[name]
+	if !ok || v == nil {
+		return nil, nil
+	}
+	arg, err := parseAmountArg(name, v)
+	if err != nil {
+		return nil, err
+	}
+	decimals, err := s.unitDecimals(ctx, name, arg.unit, coinType)
+	if err != nil {
+		return nil, err
+	}
+	return arg.baseUnits(name, decimals)
+}
+
+// unitDecimals returns how many decimals an amount in unit has: none without a
+// unit, 9 for SUI and 0 for MIST, and otherwise the decimals from the coin
+// metadata, provided unit is the coin's symbol
+func (s *SuiService) unitDecimals(ctx context.Context, field, unit string, coinType coinTypeFunc) (int, error) {
+	if unit == "" {
+		return 0, nil
+	}
+	ct, err := coinType(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("%s: cannot tell which coin %s refers to: %w", field, unit, err)
+	}
+	if isSUICoinType(ct) {
+		switch strings.ToUpper(unit) {
+		case "SUI":
+			return 9, nil
+		case "MIST":
+			return 0, nil
+		}
+		return 0, fmt.Errorf("%s: unit %s does not match the coin type %s; use SUI or MIST", field, unit, sui.SUICoinType)
+	}
+
+	meta, err := s.client.GetCoinMetadata(ctx, ct)
+	if err != nil {
+		return 0, fmt.Errorf("%s: cannot resolve the decimals of %s: %w", field, ct, err)
+	}
+	if !strings.EqualFold(meta.Symbol, unit) {
+		return 0, fmt.Errorf("%s: unit %s does not match the coin type %s, whose symbol is %s", field, unit, ct, meta.Symbol)
+	}
+	return meta.Decimals, nil
+}
+
+// isSUICoinType reports whether coinType is the native SUI coin, in any address form
+func isSUICoinType(coinType string) bool {
+	return policy.NormalizeCoinType(coinType) == sui.SUICoinType
+}
+
+// coinTypeOf returns T for an object of type 0x2::coin::Coin<T>
+func coinTypeOf(objectType string) (string, bool) {
+	const marker = "::coin::Coin<"
+	i := strings.Index(objectType, marker)
+	if i < 0 || !strings.HasSuffix(objectType, ">") {
+		return "", false
+	}
+	return objectType[i+len(marker) : len(objectType)-1], true
+}
@@ -0,0 +1,123 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"strings"
+	"time"
+
+	"github.com/krli/go-sui-mcp/internal/approval"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// signingTools are the tools that sign and submit transactions; all of them accept dry-run
+var signingTools = map[string]bool{
+	"sui-transfer":     true,
+	"sui-transfer-sui": true,
+	"sui-split-coin":   true,
+	"sui-merge-coin":   true,
+	"sui-pay":          true,
+	"sui-pay-sui":      true,
+	"sui-pay-all-sui":  true,
+	"sui-call":         true,
+	"sui-publish":      true,
+}
+
+// IsSigningTool reports whether the named tool signs and submits a transaction
+func IsSigningTool(name string) bool {
+	return signingTools[name]
+}
+
+// ApprovalMiddleware holds every signing tool call until a human approves it.
+// The call is first executed as a dry run, the preview is sent to the approver,
+// and only an explicit approval lets the real call through. Dry-run calls and
+// non-signing tools pass straight through. A nil approver disables the gate.
+// timeout bounds the wait for a decision, previewTimeout the dry run.
+func ApprovalMiddleware(approver approval.Approver, timeout, previewTimeout time.Duration) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		if approver == nil {
+			return next
+		}
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			name := request.Params.Name
+			if !IsSigningTool(name) {
+				return next(ctx, request)
+			}
+			if dryRun, _ := request.GetArguments()["dry-run"].(bool); dryRun {
+				return next(ctx, request)
+			}
+
+			preview, err := runPreview(ctx, next, request, previewTimeout)
+			if err != nil {
+				return nil, fmt.Errorf("%s dry run failed, not asking for approval: %w", name, err)
+			}
+			if preview.IsError {
+				return preview, nil
+			}
+
+			approveCtx := ctx
+			if timeout > 0 {
+				var cancel context.CancelFunc
+				approveCtx, cancel = context.WithTimeout(ctx, timeout)
+				defer cancel()
+			}
+			decision, err := approver.Approve(approveCtx, approval.Request{
+				Tool:      name,
+				Arguments: request.GetArguments(),
+				Preview:   resultText(preview),
+			})
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("%s was not executed: approval failed: %v", name, err)), nil
+			}
+			if !decision.Approved {
+				reason := decision.Reason
+				if reason == "" {
+					reason = "rejected"
+				}
+				return mcp.NewToolResultError(fmt.Sprintf("%s was not executed: %s", name, reason)), nil
+			}
+
+			return next(ctx, request)
+		}
+	}
+}
+
+// runPreview runs request as a dry run, within timeout when it is positive, so
+// that a hung simulation cannot hold the call forever
+func runPreview(ctx context.Context, next server.ToolHandlerFunc, request mcp.CallToolRequest, timeout time.Duration) (*mcp.CallToolResult, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	result, err := next(ctx, withDryRun(request))
+	if err != nil && errors.Is(err, context.DeadlineExceeded) && ctx.Err() != nil {
+		return nil, fmt.Errorf("timed out after %s: %w", timeout, err)
+	}
+	return result, err
+}
+
+// withDryRun returns a copy of request with dry-run forced on
+func withDryRun(request mcp.CallToolRequest) mcp.CallToolRequest {
+	args := maps.Clone(request.GetArguments())
+	if args == nil {
+		args = map[string]any{}
+	}
+	args["dry-run"] = true
+	request.Params.Arguments = args
+	return request
+}
+
+// resultText joins the text content of a tool result
+func resultText(result *mcp.CallToolResult) string {
+	var parts []string
+	for _, content := range result.Content {
+		if text, ok := content.(mcp.TextContent); ok {
+			parts = append(parts, text.Text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
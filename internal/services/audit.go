@@ -0,0 +1,77 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/krli/go-sui-mcp/internal/audit"
+	"github.com/krli/go-sui-mcp/internal/auth"
+	"github.com/krli/go-sui-mcp/internal/secrets"
+	"github.com/krli/go-sui-mcp/internal/sui"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// AuditMiddleware records every tool call with its session and authenticated caller, redacted arguments,
+// the sui commands it ran and their exit codes, the outcome and any transaction
+// digest. A nil logger disables auditing. Failing to write a record is logged
+// but does not fail the call, since the transaction may already be on chain.
+func AuditMiddleware(logger *audit.Logger) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		if logger == nil {
+			return next
+		}
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			start := time.Now()
+			ctx, commands := sui.WithCommandLog(ctx)
+
+			result, err := next(ctx, request)
+
+			rec := audit.Record{
+				Time:       start.UTC(),
+				Session:    sessionID(ctx),
+				Tool:       request.Params.Name,
+				Status:     audit.StatusOK,
+				DurationMs: time.Since(start).Milliseconds(),
+			}
+			if id, ok := auth.FromContext(ctx); ok {
+				rec.Caller, rec.Role = id.Name, id.Role
+			}
+			if args, marshalErr := json.Marshal(secrets.RedactValue(request.GetArguments())); marshalErr == nil {
+				rec.Args = args
+			}
+			for _, cmd := range commands.Commands() {
+				rec.Commands = append(rec.Commands, audit.Command{Argv: cmd.Argv, ExitCode: cmd.ExitCode})
+			}
+			switch {
+			case err != nil:
+				rec.Status = audit.StatusError
+				rec.Error = secrets.Redact(err.Error())
+			case result != nil && result.IsError:
+				rec.Status = audit.StatusToolError
+				rec.Error = secrets.Redact(resultText(result))
+			}
+			if result != nil {
+				// Dry runs have a predicted digest only; nothing reached the chain
+				if summary, ok := result.StructuredContent.(TransactionSummary); ok && !summary.DryRun {
+					rec.Digest = summary.Digest
+				}
+			}
+
+			if logErr := logger.Log(rec); logErr != nil {
+				log.Printf("audit: %v", logErr)
+			}
+			return result, err
+		}
+	}
+}
+
+// sessionID identifies the MCP client session serving the call
+func sessionID(ctx context.Context) string {
+	if session := server.ClientSessionFromContext(ctx); session != nil {
+		return session.SessionID()
+	}
+	return ""
+}
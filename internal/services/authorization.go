@@ -0,0 +1,83 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/krli/go-sui-mcp/internal/auth"
+	"github.com/krli/go-sui-mcp/internal/config"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// RoleGuard restricts authenticated callers to the tools of their role.
+// Calls without an identity, i.e. over stdio, are not restricted.
+type RoleGuard struct {
+	filters map[string]func(name string) bool
+}
+
+// NewRoleGuard builds the tool filter of every configured role
+func NewRoleGuard(roles map[string]config.RoleConfig) (*RoleGuard, error) {
+	g := &RoleGuard{filters: make(map[string]func(string) bool, len(roles))}
+	for name, role := range roles {
+		filter, err := NewToolFilter(config.ToolsConfig{Include: role.Include, Exclude: role.Exclude}, false)
+		if err != nil {
+			return nil, fmt.Errorf("server.auth.roles.%s: %w", name, err)
+		}
+		g.filters[name] = filter
+	}
+	return g, nil
+}
+
+// Allowed reports whether the caller in ctx may use the named tool
+func (g *RoleGuard) Allowed(ctx context.Context, tool string) bool {
+	id, ok := auth.FromContext(ctx)
+	if !ok {
+		return true
+	}
+	filter, ok := g.filters[id.Role]
+	return ok && filter(tool)
+}
+
+// Middleware refuses calls to tools outside the caller's role
+func (g *RoleGuard) Middleware() server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if !g.Allowed(ctx, request.Params.Name) {
+				id, _ := auth.FromContext(ctx)
+				return mcp.NewToolResultError(fmt.Sprintf("%s is not permitted for role %q", request.Params.Name, id.Role)), nil
+			}
+			return next(ctx, request)
+		}
+	}
+}
+
+// FilterTools hides the tools outside the caller's role from tools/list
+func (g *RoleGuard) FilterTools(ctx context.Context, tools []mcp.Tool) []mcp.Tool {
+	allowed := make([]mcp.Tool, 0, len(tools))
+	for _, tool := range tools {
+		if g.Allowed(ctx, tool.Name) {
+			allowed = append(allowed, tool)
+		}
+	}
+	return allowed
+}
+
+// AllowedResource reports whether the caller in ctx may read the resource at uri
+func (g *RoleGuard) AllowedResource(ctx context.Context, uri string) bool {
+	tool := ResourceTool(uri)
+	return tool != "" && g.Allowed(ctx, tool)
+}
+
+// ResourceMiddleware refuses reads of resources whose tool is outside the caller's role
+func (g *RoleGuard) ResourceMiddleware() server.ResourceHandlerMiddleware {
+	return func(next server.ResourceHandlerFunc) server.ResourceHandlerFunc {
+		return func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+			if !g.AllowedResource(ctx, request.Params.URI) {
+				id, _ := auth.FromContext(ctx)
+				return nil, fmt.Errorf("%s is not permitted for role %q", request.Params.URI, id.Role)
+			}
+			return next(ctx, request)
+		}
+	}
+}
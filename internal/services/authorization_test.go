@@ -0,0 +1,101 @@
+package services
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/krli/go-sui-mcp/internal/auth"
+	"github.com/krli/go-sui-mcp/internal/config"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestRoleGuard(t *testing.T) {
+	guard, err := NewRoleGuard(map[string]config.RoleConfig{
+		"reader": {Include: []string{GroupQuery}},
+		"payer":  {Include: []string{GroupQuery, GroupPayments}, Exclude: []string{"sui-faucet"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tools := []mcp.Tool{{Name: "sui-gas"}, {Name: "sui-object"}, {Name: "sui-transfer-sui"}, {Name: "sui-faucet"}, {Name: "sui-keytool-export"}}
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		// listed are the tools the caller may see and call
+		listed []string
+		// resources maps a resource URI to whether the caller may read it
+		resources map[string]bool
+	}{
+		{
+			name:      "stdio",
+			ctx:       context.Background(),
+			listed:    []string{"sui-gas", "sui-object", "sui-transfer-sui", "sui-faucet", "sui-keytool-export"},
+			resources: map[string]bool{"sui://object/0x5": true, "file:///etc/passwd": false},
+		},
+		{
+			name:      "reader",
+			ctx:       auth.WithIdentity(context.Background(), auth.Identity{Name: "analyst", Role: "reader"}),
+			listed:    []string{"sui-gas", "sui-object"},
+			resources: map[string]bool{"sui://object/0x5": true, "sui://tx/abc": true, "sui://other/1": false},
+		},
+		{
+			name:      "payer",
+			ctx:       auth.WithIdentity(context.Background(), auth.Identity{Name: "bot", Role: "payer"}),
+			listed:    []string{"sui-gas", "sui-object", "sui-transfer-sui"},
+			resources: map[string]bool{"sui://address/0x5/balances": true},
+		},
+		{
+			name:      "unknown role",
+			ctx:       auth.WithIdentity(context.Background(), auth.Identity{Name: "stale", Role: "admin"}),
+			resources: map[string]bool{"sui://object/0x5": false},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var listed []string
+			for _, tool := range guard.FilterTools(tc.ctx, tools) {
+				listed = append(listed, tool.Name)
+			}
+			if !slices.Equal(listed, tc.listed) {
+				t.Errorf("FilterTools = %v, want %v", listed, tc.listed)
+			}
+
+			call := guard.Middleware()(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return mcp.NewToolResultText("ran"), nil
+			})
+			for _, tool := range tools {
+				request := mcp.CallToolRequest{}
+				request.Params.Name = tool.Name
+				result, err := call(tc.ctx, request)
+				if err != nil {
+					t.Fatalf("%s: %v", tool.Name, err)
+				}
+				ran := !result.IsError
+				if want := slices.Contains(tc.listed, tool.Name); ran != want {
+					t.Errorf("calling %s ran = %v, want %v", tool.Name, ran, want)
+				}
+				if !ran && !strings.Contains(result.Content[0].(mcp.TextContent).Text, "is not permitted for role") {
+					t.Errorf("%s refused with %v", tool.Name, result.Content)
+				}
+			}
+
+			read := guard.ResourceMiddleware()(func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+				return nil, nil
+			})
+			for uri, want := range tc.resources {
+				if got := guard.AllowedResource(tc.ctx, uri); got != want {
+					t.Errorf("AllowedResource(%s) = %v, want %v", uri, got, want)
+				}
+				request := mcp.ReadResourceRequest{}
+				request.Params.URI = uri
+				if _, err := read(tc.ctx, request); (err == nil) != want {
+					t.Errorf("reading %s: %v, want allowed %v", uri, err, want)
+				}
+			}
+		})
+	}
+}
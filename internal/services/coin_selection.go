@@ -0,0 +1,97 @@
+package services
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"math/big"
+	"slices"
+
+	"github.com/krli/go-sui-mcp/internal/sui"
+)
+
+// defaultGasReserveMist is the SUI kept back for gas when a call sets no gas budget
+const defaultGasReserveMist = 50_000_000
+
+// gasReserve returns the MIST to keep back for gas: the gas budget when one is given
+func gasReserve(opts sui.TxOptions) *big.Int {
+	if budget, ok := new(big.Int).SetString(opts.GasBudget, 10); ok {
+		return budget
+	}
+	return big.NewInt(defaultGasReserveMist)
+}
+
+// coinBalance parses a coin's balance; coins with an unreadable balance count as empty
+func coinBalance(coin sui.Coin) *big.Int {
+	balance, ok := new(big.Int).SetString(coin.Balance, 10)
+	if !ok {
+		return new(big.Int)
+	}
+	return balance
+}
+
+// sumAmounts totals the amounts of a payment
+func sumAmounts(amounts []*big.Int) *big.Int {
+	total := new(big.Int)
+	for _, amount := range amounts {
+		total.Add(total, amount)
+	}
+	return total
+}
+
+// coinSelection is the outcome of picking input coins for a payment
+type coinSelection struct {
+	// Coins are the selected coins, largest first
+	Coins []sui.Coin
+	// Rest are the owned coins that were not selected, largest first
+	Rest []sui.Coin
+}
+
+// IDs returns the object IDs of the selected coins
+func (s coinSelection) IDs() []string {
+	ids := make([]string, len(s.Coins))
+	for i, coin := range s.Coins {
+		ids[i] = coin.CoinObjectID
+	}
+	return ids
+}
+
+// selectCoins picks the fewest of the signer's coinType coins, largest first,
+// whose balances add up to at least target. The transaction itself merges the
+// selected coins and splits off the amounts, so no separate merge or split is needed.
+func (s *SuiService) selectCoins(ctx context.Context, coinType string, target *big.Int) (coinSelection, error) {
+	coins, err := s.client.GetCoins(ctx, "", coinType)
+	if err != nil {
+		return coinSelection{}, fmt.Errorf("cannot list coins for automatic selection: %w", err)
+	}
+	slices.SortFunc(coins, func(a, b sui.Coin) int {
+		return cmp.Compare(0, coinBalance(a).Cmp(coinBalance(b)))
+	})
+
+	total := new(big.Int)
+	for i, coin := range coins {
+		total.Add(total, coinBalance(coin))
+		if total.Cmp(target) >= 0 {
+			return coinSelection{Coins: coins[:i+1], Rest: coins[i+1:]}, nil
+		}
+	}
+	return coinSelection{}, fmt.Errorf("insufficient %s balance: need %s, have %s across %d coins", coinType, target, total, len(coins))
+}
+
+// selectSingleCoin returns the smallest of the signer's SUI coins holding at
+// least target on its own, or false when no single coin does
+func (s *SuiService) selectSingleCoin(ctx context.Context, target *big.Int) (sui.Coin, bool, error) {
+	coins, err := s.client.GetCoins(ctx, "", sui.SUICoinType)
+	if err != nil {
+		return sui.Coin{}, false, fmt.Errorf("cannot list coins for automatic selection: %w", err)
+	}
+	var best sui.Coin
+	found := false
+	for _, coin := range coins {
+		balance := coinBalance(coin)
+		if balance.Cmp(target) >= 0 && (!found || balance.Cmp(coinBalance(best)) < 0) {
+			best, found = coin, true
+		}
+	}
+	return best, found, nil
+}
@@ -0,0 +1,24 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/krli/go-sui-mcp/internal/sui"
+)
+
+func TestGasReserve(t *testing.T) {
+	tests := []struct {
+		budget string
+		want   string
+	}{
+		{budget: "", want: "50000000"},
+		{budget: "1000", want: "1000"},
+		{budget: "18446744073709551615", want: "18446744073709551615"},
+		{budget: "lots", want: "50000000"},
+	}
+	for _, tc := range tests {
+		if got := gasReserve(sui.TxOptions{GasBudget: tc.budget}); got.String() != tc.want {
+			t.Errorf("gasReserve(%q) = %s, want %s", tc.budget, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/krli/go-sui-mcp/internal/sui"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// EnvMiddleware runs each call against the environment named by its env
+// argument, or defaultEnv when the argument is absent. Each environment uses
+// its own client config, so concurrent calls never switch the shared active env.
+// With neither set, calls use the CLI's active env as before. The env argument
+// of sui-switch-env names the env to switch to, so that tool runs unselected.
+func EnvMiddleware(client *sui.Client, defaultEnv string) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if request.Params.Name == "sui-switch-env" {
+				return next(ctx, request)
+			}
+			alias, _ := request.GetArguments()["env"].(string)
+			if alias == "" {
+				alias = defaultEnv
+			}
+			if alias == "" {
+				return next(ctx, request)
+			}
+
+			env, err := client.ResolveEnv(alias)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("%s was not executed: %v", request.Params.Name, err)), nil
+			}
+			return next(sui.WithEnv(ctx, env), request)
+		}
+	}
+}
+
+// ResourceEnvMiddleware reads resources on defaultEnv, or on the CLI's active
+// env when it is empty; resource URIs have no env argument
+func ResourceEnvMiddleware(client *sui.Client, defaultEnv string) server.ResourceHandlerMiddleware {
+	return func(next server.ResourceHandlerFunc) server.ResourceHandlerFunc {
+		return func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+			ctx, err := WithDefaultEnv(ctx, client, defaultEnv)
+			if err != nil {
+				return nil, err
+			}
+			return next(ctx, request)
+		}
+	}
+}
+
+// WithDefaultEnv selects defaultEnv in ctx for work that has no env argument
+func WithDefaultEnv(ctx context.Context, client *sui.Client, defaultEnv string) (context.Context, error) {
+	if defaultEnv == "" {
+		return ctx, nil
+	}
+	env, err := client.ResolveEnv(defaultEnv)
+	if err != nil {
+		return nil, err
+	}
+	return sui.WithEnv(ctx, env), nil
+}
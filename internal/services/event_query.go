@@ -0,0 +1,121 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/krli/go-sui-mcp/internal/sui"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// moveEventTypePattern matches a Move struct type such as 0x2::coin::CoinMetadata<0x2::sui::SUI>
+var moveEventTypePattern = regexp.MustCompile(`^0x[0-9A-Fa-f]{1,64}::[A-Za-z_][A-Za-z0-9_]*::[A-Za-z_][A-Za-z0-9_]*(<.+>)?$`)
+
+// eventFilter builds the single fullnode filter a sui-query-events call asks for.
+// A time range on its own becomes a TimeRange filter.
+func eventFilter(request mcp.CallToolRequest, within timeRange) (map[string]any, error) {
+	args := request.GetArguments()
+	from, _ := args["from-address"].(string)
+	digest, _ := args["tx-digest"].(string)
+	eventType, _ := args["event-type"].(string)
+	pkg, _ := args["package"].(string)
+	module, _ := args["module"].(string)
+
+	given := 0
+	for _, v := range []string{from, digest, eventType, pkg + module} {
+		if v != "" {
+			given++
+		}
+	}
+	if given > 1 {
+		return nil, errors.New("give only one of from-address, tx-digest, event-type or package/module; a time range may be added to any of them")
+	}
+	if eventType != "" && !moveEventTypePattern.MatchString(eventType) {
+		return nil, fmt.Errorf("event-type %q is not a Move type such as 0x2::coin::CoinMetadata", eventType)
+	}
+	if (pkg == "") != (module == "") {
+		return nil, errors.New("package and module must be given together")
+	}
+	if module != "" && !moveIdentifierPattern.MatchString(module) {
+		return nil, fmt.Errorf("module %q is not a Move identifier", module)
+	}
+
+	switch {
+	case from != "":
+		return map[string]any{"Sender": from}, nil
+	case digest != "":
+		return map[string]any{"Transaction": digest}, nil
+	case eventType != "":
+		return map[string]any{"MoveEventType": eventType}, nil
+	case pkg != "":
+		return map[string]any{"MoveEventModule": map[string]string{"package": pkg, "module": module}}, nil
+	case within.isOpen():
+		return nil, errors.New("give one of from-address, tx-digest, event-type, package/module or a time range")
+	}
+	until := within.Until
+	if until.IsZero() {
+		until = time.Now()
+	}
+	return map[string]any{"TimeRange": map[string]string{
+		"startTime": strconv.FormatInt(within.Since.UnixMilli(), 10),
+		"endTime":   strconv.FormatInt(until.UnixMilli(), 10),
+	}}, nil
+}
+
+// parseEventCursor reads a cursor of the form <tx digest>:<event sequence>, as
+// returned in nextCursor; an empty cursor starts at the first page
+func parseEventCursor(cursor string) (*sui.EventID, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+	digest, seq, ok := strings.Cut(cursor, ":")
+	if ok {
+		if _, err := strconv.ParseUint(seq, 10, 64); err != nil {
+			ok = false
+		}
+	}
+	if !ok || sui.ValidateDigest(digest) != nil {
+		return nil, fmt.Errorf("cursor %q is not a nextCursor of the form <tx digest>:<event sequence>", cursor)
+	}
+	return &sui.EventID{TxDigest: digest, EventSeq: seq}, nil
+}
+
+// formatEventCursor renders an event ID in the form parseEventCursor reads
+func formatEventCursor(id *sui.EventID) string {
+	if id == nil {
+		return ""
+	}
+	return id.TxDigest + ":" + id.EventSeq
+}
+
+// queryEvents fetches up to limit events matching filter within the time range
+func (s *SuiService) queryEvents(ctx context.Context, filter map[string]any, within timeRange, cursor *sui.EventID, limit int, descending bool) (EventList, error) {
+	fetch := func(cursor *sui.EventID, size int) (queryPage[sui.Event, *sui.EventID], error) {
+		page, err := s.client.QueryEvents(ctx, filter, cursor, size, descending)
+		if err != nil {
+			return queryPage[sui.Event, *sui.EventID]{}, err
+		}
+		return queryPage[sui.Event, *sui.EventID]{Items: page.Data, Next: page.NextCursor, HasNext: page.HasNextPage && page.NextCursor != nil}, nil
+	}
+	cursorOf := func(event sui.Event) *sui.EventID { return &event.ID }
+	timestampOf := func(event sui.Event) (int64, bool) { return parseTimestampMs(event.TimestampMs) }
+	if _, ok := filter["TimeRange"]; ok {
+		// The fullnode already applied the range
+		within = timeRange{}
+	}
+
+	page, err := collectInRange(fetch, cursorOf, timestampOf, within, cursor, limit, descending)
+	if err != nil {
+		return EventList{}, err
+	}
+	list := EventList{Filter: filter, Events: []EventListEntry{}, NextCursor: formatEventCursor(page.Next), HasNextPage: page.HasNext}
+	for _, event := range page.Items {
+		list.Events = append(list.Events, summarizeListedEvent(event))
+	}
+	return list, nil
+}
@@ -0,0 +1,137 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/krli/go-sui-mcp/internal/sui"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// nameArguments lists, per tool, the address arguments that also accept a SuiNS name
+var nameArguments = map[string][]string{
+	"sui-balance-summary":    {"address"},
+	"sui-objects-summary":    {"address"},
+	"sui-query-transactions": {"from-address", "to-address"},
+	"sui-query-events":       {"from-address"},
+	"sui-subscribe":          {"from-address", "address"},
+	"sui-transfer":           {"to"},
+	"sui-transfer-sui":       {"to"},
+	"sui-pay":                {"recipients"},
+	"sui-pay-sui":            {"recipients"},
+	"sui-pay-all-sui":        {"recipient"},
+}
+
+// acceptsName reports whether the tool's argument may be given as a SuiNS name
+func acceptsName(tool, argument string) bool {
+	return slices.Contains(nameArguments[tool], argument)
+}
+
+type resolvedNamesKey struct{}
+
+// resolvedNamesFromContext returns the names resolved for the call, keyed by name
+func resolvedNamesFromContext(ctx context.Context) map[string]string {
+	names, _ := ctx.Value(resolvedNamesKey{}).(map[string]string)
+	return names
+}
+
+// NameResolutionMiddleware replaces SuiNS names such as alice.sui in address
+// arguments with the address they point at, on the call's network. It runs
+// before the spending policy and approval so that both only ever see addresses.
+func NameResolutionMiddleware(client *sui.Client) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			fields := nameArguments[request.Params.Name]
+			if len(fields) == 0 {
+				return next(ctx, request)
+			}
+
+			args := maps.Clone(request.GetArguments())
+			names := make(map[string]string)
+			resolve := func(field, value string) (string, error) {
+				if !sui.IsName(value) {
+					return value, nil
+				}
+				address, err := client.ResolveName(ctx, value)
+				if err != nil {
+					return "", fmt.Errorf("cannot resolve %s %s: %w", field, value, err)
+				}
+				names[value] = address
+				return address, nil
+			}
+
+			for _, field := range fields {
+				var err error
+				switch value := args[field].(type) {
+				case string:
+					args[field], err = resolve(field, value)
+				case []any:
+					items := slices.Clone(value)
+					for i, item := range items {
+						if str, ok := item.(string); ok && err == nil {
+							items[i], err = resolve(fmt.Sprintf("%s[%d]", field, i), str)
+						}
+					}
+					args[field] = items
+				}
+				if err != nil {
+					return mcp.NewToolResultError(fmt.Sprintf("%s was not executed: %v", request.Params.Name, err)), nil
+				}
+			}
+			if len(names) == 0 {
+				return next(ctx, request)
+			}
+
+			request.Params.Arguments = args
+			return next(context.WithValue(ctx, resolvedNamesKey{}, names), request)
+		}
+	}
+}
+
+// ResolvedNamesMiddleware echoes the names resolved for a call in its result,
+// both as structured content and as text. It wraps the handlers directly so
+// that dry-run previews shown for approval carry the resolution too.
+func ResolvedNamesMiddleware() server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			result, err := next(ctx, request)
+			names := resolvedNamesFromContext(ctx)
+			if err != nil || result == nil || result.IsError || len(names) == 0 {
+				return result, err
+			}
+
+			switch summary := result.StructuredContent.(type) {
+			case TransactionSummary:
+				summary.ResolvedNames = names
+				result.StructuredContent = summary
+			case BalanceSummary:
+				summary.ResolvedNames = names
+				result.StructuredContent = summary
+			case ObjectsSummary:
+				summary.ResolvedNames = names
+				result.StructuredContent = summary
+			case TransactionList:
+				summary.ResolvedNames = names
+				result.StructuredContent = summary
+			case EventList:
+				summary.ResolvedNames = names
+				result.StructuredContent = summary
+			case SubscriptionsSummary:
+				summary.ResolvedNames = names
+				result.StructuredContent = summary
+			}
+
+			var b strings.Builder
+			b.WriteString("Resolved names:\n")
+			for _, name := range slices.Sorted(maps.Keys(names)) {
+				fmt.Fprintf(&b, "  %s -> %s\n", name, names[name])
+			}
+			result.Content = append(result.Content, mcp.NewTextContent(b.String()))
+			return result, nil
+		}
+	}
+}
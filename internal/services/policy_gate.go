@@ -0,0 +1,130 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/krli/go-sui-mcp/internal/auth"
+	"github.com/krli/go-sui-mcp/internal/policy"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// paymentTools are the tools the spending policy applies to
+var paymentTools = map[string]bool{
+	"sui-transfer":     true,
+	"sui-transfer-sui": true,
+	"sui-pay":          true,
+	"sui-pay-sui":      true,
+	"sui-pay-all-sui":  true,
+}
+
+// PolicyMiddleware checks every payment against the spending policy before it is submitted.
+// The call is dry-run to learn exactly how much of each coin type leaves the sender;
+// amounts are taken from the predicted balance changes so PayAllSUI and whole-coin
+// transfers are measured too. A passing payment is recorded once it succeeds. Dry-run
+// calls and non-payment tools pass straight through. A nil engine disables the policy.
+// previewTimeout bounds the dry run.
+func PolicyMiddleware(engine *policy.Engine, previewTimeout time.Duration) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		if engine == nil {
+			return next
+		}
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			name := request.Params.Name
+			if !paymentTools[name] {
+				return next(ctx, request)
+			}
+			if dryRun, _ := request.GetArguments()["dry-run"].(bool); dryRun {
+				return next(ctx, request)
+			}
+
+			// Recipients named in the arguments are checked up front so a refused payment is not even simulated
+			if err := engine.CheckRecipients(argumentRecipients(request)); err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("%s was not executed: %v", name, err)), nil
+			}
+
+			preview, err := runPreview(ctx, next, request, previewTimeout)
+			if err != nil {
+				return nil, fmt.Errorf("%s dry run failed, cannot check spending policy: %w", name, err)
+			}
+			if preview.IsError {
+				return preview, nil
+			}
+			summary, ok := preview.StructuredContent.(TransactionSummary)
+			if !ok {
+				return nil, fmt.Errorf("%s dry run returned no transaction summary, cannot check spending policy", name)
+			}
+
+			payment := paymentFromSummary(request, summary)
+			if id, ok := auth.FromContext(ctx); ok {
+				payment.Role, payment.RoleMaxPerTxMist = id.Role, id.MaxPerTxMist
+			}
+			reservation, err := engine.Reserve(payment)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("%s was not executed: %v", name, err)), nil
+			}
+
+			result, err := next(ctx, request)
+			if err != nil || result.IsError {
+				reservation.Release()
+				return result, err
+			}
+			if submitted, ok := result.StructuredContent.(TransactionSummary); ok && submitted.Status != "success" {
+				reservation.Release()
+				return result, nil
+			}
+			if err := reservation.Commit(); err != nil {
+				return nil, fmt.Errorf("%s was submitted but recording it against the spending limits failed: %w", name, err)
+			}
+			return result, nil
+		}
+	}
+}
+
+// argumentRecipients returns the addresses named by the to, recipient and recipients arguments
+func argumentRecipients(request mcp.CallToolRequest) []string {
+	args := request.GetArguments()
+	var recipients []string
+	for _, key := range []string{"to", "recipient"} {
+		if addr, ok := args[key].(string); ok && addr != "" {
+			recipients = append(recipients, addr)
+		}
+	}
+	if list, ok := args["recipients"].([]any); ok {
+		for _, v := range list {
+			if addr, ok := v.(string); ok {
+				recipients = append(recipients, addr)
+			}
+		}
+	}
+	return recipients
+}
+
+// paymentFromSummary collects the recipients named in the arguments and the
+// amounts credited to anyone other than the sender in the dry-run balance changes
+func paymentFromSummary(request mcp.CallToolRequest, summary TransactionSummary) policy.Payment {
+	recipients := argumentRecipients(request)
+	amounts := map[string]*big.Int{}
+	for _, change := range summary.BalanceChanges {
+		owner := change.Owner.Address
+		if owner == "" || strings.EqualFold(owner, summary.Sender) {
+			continue
+		}
+		amount, ok := new(big.Int).SetString(change.Amount, 10)
+		if !ok || amount.Sign() <= 0 {
+			continue
+		}
+		if total, ok := amounts[change.CoinType]; ok {
+			total.Add(total, amount)
+		} else {
+			amounts[change.CoinType] = amount
+		}
+		recipients = append(recipients, owner)
+	}
+
+	return policy.Payment{Recipients: recipients, Amounts: amounts}
+}
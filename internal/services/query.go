@@ -0,0 +1,180 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	// defaultQueryLimit and maxQueryLimit bound the entries of one query page;
+	// fullnodes serve at most 50 per request
+	defaultQueryLimit = 20
+	maxQueryLimit     = 50
+	// maxQueryPages bounds the fullnode pages scanned for one call when a time
+	// range has to be applied to the results
+	maxQueryPages = 10
+)
+
+// moveIdentifierPattern matches Move module and function names
+var moveIdentifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// timeRange bounds a query by transaction timestamp; a zero bound is open
+type timeRange struct {
+	Since, Until time.Time
+}
+
+// contains reports whether a timestamp in milliseconds lies within the range.
+// Transactions without a timestamp are kept.
+func (r timeRange) contains(ms int64, ok bool) bool {
+	if !ok {
+		return true
+	}
+	t := time.UnixMilli(ms)
+	return (r.Since.IsZero() || !t.Before(r.Since)) && (r.Until.IsZero() || t.Before(r.Until))
+}
+
+// isOpen reports whether the range does not restrict anything
+func (r timeRange) isOpen() bool {
+	return r.Since.IsZero() && r.Until.IsZero()
+}
+
+// parseTimeArg reads a time bound given as RFC 3339, a date (midnight UTC) or Unix milliseconds
+func parseTimeArg(field string, v any) (time.Time, error) {
+	switch v := v.(type) {
+	case nil:
+		return time.Time{}, nil
+	case float64:
+		return time.UnixMilli(int64(v)), nil
+	case string:
+		if v == "" {
+			return time.Time{}, nil
+		}
+		if ms, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return time.UnixMilli(ms), nil
+		}
+		if t, err := time.Parse(time.RFC3339, v); err == nil {
+			return t, nil
+		}
+		if t, err := time.Parse(time.DateOnly, v); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%s must be an RFC 3339 time such as 2025-01-31T12:00:00Z, a date such as 2025-01-31 or Unix milliseconds", field)
+}
+
+// parseTimeRange reads the since and until arguments
+func parseTimeRange(request mcp.CallToolRequest) (timeRange, error) {
+	var r timeRange
+	var err error
+	if r.Since, err = parseTimeArg("since", request.GetArguments()["since"]); err != nil {
+		return r, err
+	}
+	if r.Until, err = parseTimeArg("until", request.GetArguments()["until"]); err != nil {
+		return r, err
+	}
+	if !r.Since.IsZero() && !r.Until.IsZero() && !r.Since.Before(r.Until) {
+		return r, errors.New("since must be before until")
+	}
+	return r, nil
+}
+
+// parseQueryLimit reads the limit argument, defaulting to defaultQueryLimit
+func parseQueryLimit(request mcp.CallToolRequest) (int, error) {
+	v, ok := request.GetArguments()["limit"]
+	if !ok || v == nil {
+		return defaultQueryLimit, nil
+	}
+	limit, ok := v.(float64)
+	if !ok || limit != float64(int(limit)) || limit < 1 || limit > maxQueryLimit {
+		return 0, fmt.Errorf("limit must be a whole number from 1 to %d", maxQueryLimit)
+	}
+	return int(limit), nil
+}
+
+// parseDescending reads the order argument; newest first unless it is "ascending"
+func parseDescending(request mcp.CallToolRequest) (bool, error) {
+	switch order, _ := request.GetArguments()["order"].(string); order {
+	case "", "descending":
+		return true, nil
+	case "ascending":
+		return false, nil
+	default:
+		return false, fmt.Errorf("order must be ascending or descending, got %q", order)
+	}
+}
+
+// queryPage is one page of a fullnode listing; Next continues after it
+type queryPage[T, C any] struct {
+	Items   []T
+	Next    C
+	HasNext bool
+}
+
+// collectInRange pages through a fullnode listing until it holds limit items
+// within the time range. Fullnodes cannot combine a time range with another
+// filter, so the range is applied here: pages of maxQueryLimit items are
+// fetched so that few requests are spent on items the range drops, at most
+// maxQueryPages of them, stopping as soon as the listing has moved past the
+// range. Without a range it returns a single page of limit items. fetch gets
+// the page size to ask for; cursorOf gives the cursor continuing after an item.
+func collectInRange[T, C any](
+	fetch func(cursor C, size int) (queryPage[T, C], error),
+	cursorOf func(T) C,
+	timestampOf func(T) (int64, bool),
+	within timeRange, cursor C, limit int, descending bool,
+) (queryPage[T, C], error) {
+	size := limit
+	if !within.isOpen() {
+		size = maxQueryLimit
+	}
+
+	var result queryPage[T, C]
+	for pages := 0; ; pages++ {
+		if pages == maxQueryPages {
+			result.Next, result.HasNext = cursor, true
+			return result, nil
+		}
+		page, err := fetch(cursor, size)
+		if err != nil {
+			return result, err
+		}
+		for i, item := range page.Items {
+			cursor = cursorOf(item)
+			ms, ok := timestampOf(item)
+			if ok && ((descending && !within.Since.IsZero() && time.UnixMilli(ms).Before(within.Since)) ||
+				(!descending && !within.Until.IsZero() && !time.UnixMilli(ms).Before(within.Until))) {
+				// Everything after this lies outside the range
+				return result, nil
+			}
+			if !within.contains(ms, ok) {
+				continue
+			}
+			result.Items = append(result.Items, item)
+			if len(result.Items) == limit {
+				if i < len(page.Items)-1 || page.HasNext {
+					result.Next, result.HasNext = cursor, true
+				}
+				return result, nil
+			}
+		}
+		if !page.HasNext {
+			return result, nil
+		}
+		cursor = page.Next
+		if within.isOpen() {
+			result.Next, result.HasNext = cursor, true
+			return result, nil
+		}
+	}
+}
+
+// parseTimestampMs reads a fullnode timestamp in milliseconds, if there is one
+func parseTimestampMs(timestampMs string) (int64, bool) {
+	ms, err := strconv.ParseInt(timestampMs, 10, 64)
+	return ms, err == nil
+}
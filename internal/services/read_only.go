@@ -0,0 +1,33 @@
+package services
+
+// readOnlyTools are the tools that only query chain or local state; they are
+// the only tools registered when the server runs in read-only mode
+var readOnlyTools = map[string]bool{
+	"sui-formatted-version":   true,
+	"sui-path":                true,
+	"sui-balance-summary":     true,
+	"sui-objects-summary":     true,
+	"sui-object":              true,
+	"sui-resolve-name":        true,
+	"sui-process-transaction": true,
+	"sui-query-transactions":  true,
+	"sui-query-events":        true,
+	"sui-subscribe":           true,
+	"sui-unsubscribe":         true,
+	"sui-subscriptions":       true,
+	"sui-active-address":      true,
+	"sui-addresses":           true,
+	"sui-active-env":          true,
+	"sui-envs":                true,
+	"sui-chain-identifier":    true,
+	"sui-gas":                 true,
+	"sui-dynamic-field":       true,
+	"sui-move-module":         true,
+	"sui-move-build":          true,
+	"sui-move-test":           true,
+}
+
+// IsReadOnlyTool reports whether the named tool is safe to expose in read-only mode
+func IsReadOnlyTool(name string) bool {
+	return readOnlyTools[name]
+}
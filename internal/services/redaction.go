@@ -0,0 +1,39 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/krli/go-sui-mcp/internal/secrets"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// RedactionMiddleware scrubs private keys and recovery phrases from every tool
+// result and error before it reaches the client, whichever tool produced them
+func RedactionMiddleware() server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			result, err := next(ctx, request)
+			if err != nil {
+				if msg := secrets.Redact(err.Error()); msg != err.Error() {
+					err = errors.New(msg)
+				}
+			}
+			if result == nil {
+				return result, err
+			}
+
+			for i, content := range result.Content {
+				if text, ok := content.(mcp.TextContent); ok {
+					text.Text = secrets.Redact(text.Text)
+					result.Content[i] = text
+				}
+			}
+			if result.StructuredContent != nil {
+				result.StructuredContent = secrets.RedactValue(result.StructuredContent)
+			}
+			return result, err
+		}
+	}
+}
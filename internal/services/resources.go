@@ -0,0 +1,178 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/krli/go-sui-mcp/internal/subscriptions"
+	"github.com/krli/go-sui-mcp/internal/sui"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// URI templates of the chain state exposed as MCP resources
+const (
+	ObjectResourceURI      = "sui://object/{id}"
+	BalancesResourceURI    = "sui://address/{addr}/balances"
+	TransactionResourceURI = "sui://tx/{digest}"
+	ModuleResourceURI      = "sui://package/{id}/module/{name}"
+)
+
+// resourceTools names the tool each kind of resource mirrors, keyed by the
+// first segment of its URI. A resource is only served where that tool is, so
+// the tools config, read-only mode and auth roles cover resources too.
+var resourceTools = map[string]string{
+	"object":  "sui-object",
+	"address": "sui-balance-summary",
+	"tx":      "sui-process-transaction",
+	"package": "sui-move-module",
+}
+
+// ResourceTool returns the tool whose availability governs a resource URI or
+// URI template, or "" when the URI is not one of this server's
+func ResourceTool(uri string) string {
+	path, ok := strings.CutPrefix(uri, "sui://")
+	if !ok {
+		return ""
+	}
+	kind, _, _ := strings.Cut(path, "/")
+	return resourceTools[kind]
+}
+
+// ReadObject serves sui://object/{id}
+func (s *SuiService) ReadObject(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	objectID, err := sui.NormalizeAddress(resourceArgument(request, "id"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid object ID: %w", err)
+	}
+	object, err := s.client.GetObject(ctx, objectID)
+	if err != nil {
+		return nil, err
+	}
+	return jsonResource(request.Params.URI, object)
+}
+
+// ReadBalances serves sui://address/{addr}/balances
+func (s *SuiService) ReadBalances(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	address, err := s.resourceAddress(ctx, resourceArgument(request, "addr"))
+	if err != nil {
+		return nil, err
+	}
+	balances, err := s.client.GetBalance(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+	if balances == nil {
+		balances = []sui.Balance{}
+	}
+	return jsonResource(request.Params.URI, BalanceSummary{Address: address, Balances: balances})
+}
+
+// ReadTransaction serves sui://tx/{digest}
+func (s *SuiService) ReadTransaction(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	digest := resourceArgument(request, "digest")
+	if err := sui.ValidateDigest(digest); err != nil {
+		return nil, fmt.Errorf("invalid transaction digest: %w", err)
+	}
+	tx, err := s.client.GetTransaction(ctx, digest)
+	if err != nil {
+		return nil, err
+	}
+	return jsonResource(request.Params.URI, summarizeTransaction(tx, false))
+}
+
+// ReadModule serves sui://package/{id}/module/{name}
+func (s *SuiService) ReadModule(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	packageID, err := sui.NormalizeAddress(resourceArgument(request, "id"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid package ID: %w", err)
+	}
+	module := resourceArgument(request, "name")
+	if !moveIdentifierPattern.MatchString(module) {
+		return nil, fmt.Errorf("module %q is not a Move identifier", module)
+	}
+	raw, err := s.client.GetMoveModule(ctx, packageID, module)
+	if err != nil {
+		return nil, err
+	}
+	return jsonResource(request.Params.URI, raw)
+}
+
+// SubscribeResource watches a resource the calling session subscribed to with
+// resources/subscribe. Objects and balances are polled like sui-subscribe
+// watches; transactions and published modules never change, so there is
+// nothing to watch for them.
+func (s *SuiService) SubscribeResource(ctx context.Context, uri string) error {
+	session, err := s.subscriptionSession(ctx)
+	if err != nil {
+		return err
+	}
+
+	path, _ := strings.CutPrefix(uri, "sui://")
+	parts := strings.Split(path, "/")
+	spec := subscriptions.Spec{URI: uri}
+	switch {
+	case len(parts) == 2 && parts[0] == "object":
+		spec.Kind = subscriptions.KindObject
+		if spec.ObjectID, err = sui.NormalizeAddress(parts[1]); err != nil {
+			return fmt.Errorf("invalid object ID: %w", err)
+		}
+	case len(parts) == 3 && parts[0] == "address" && parts[2] == "balances":
+		spec.Kind = subscriptions.KindAddress
+		if spec.Address, err = s.resourceAddress(ctx, parts[1]); err != nil {
+			return err
+		}
+	case ResourceTool(uri) != "":
+		return nil
+	default:
+		return fmt.Errorf("unknown resource %s", uri)
+	}
+
+	_, err = s.subscriptions.Subscribe(ctx, session, spec)
+	return err
+}
+
+// UnsubscribeResource stops watching a resource for the calling session
+func (s *SuiService) UnsubscribeResource(ctx context.Context, uri string) error {
+	session, err := s.subscriptionSession(ctx)
+	if err != nil {
+		return err
+	}
+	s.subscriptions.UnsubscribeURI(session, uri)
+	return nil
+}
+
+// resourceAddress validates the address of a resource URI, resolving a SuiNS name
+func (s *SuiService) resourceAddress(ctx context.Context, addr string) (string, error) {
+	if sui.IsName(addr) {
+		return s.client.ResolveName(ctx, addr)
+	}
+	address, err := sui.NormalizeAddress(addr)
+	if err != nil {
+		return "", fmt.Errorf("invalid address: %w", err)
+	}
+	return address, nil
+}
+
+// resourceArgument returns a variable matched from a resource URI template
+func resourceArgument(request mcp.ReadResourceRequest, name string) string {
+	switch v := request.Params.Arguments[name].(type) {
+	case string:
+		return v
+	case []string:
+		if len(v) == 1 {
+			return v[0]
+		}
+	}
+	return ""
+}
+
+// jsonResource renders v as the JSON contents of the resource at uri
+func jsonResource(uri string, v any) ([]mcp.ResourceContents, error) {
+	text, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode resource: %w", err)
+	}
+	return []mcp.ResourceContents{mcp.TextResourceContents{URI: uri, MIMEType: "application/json", Text: string(text)}}, nil
+}
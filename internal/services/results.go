@@ -0,0 +1,423 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/krli/go-sui-mcp/internal/subscriptions"
+	"github.com/krli/go-sui-mcp/internal/sui"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// BalanceSummary is the structured result of sui-balance-summary
+type BalanceSummary struct {
+	Address  string        `json:"address,omitempty"`
+	Balances []sui.Balance `json:"balances"`
+	// ResolvedNames maps each SuiNS name in the arguments to the address it resolved to
+	ResolvedNames map[string]string `json:"resolvedNames,omitempty"`
+}
+
+// ObjectsSummary is the structured result of sui-objects-summary
+type ObjectsSummary struct {
+	Address string           `json:"address,omitempty"`
+	Objects []sui.ObjectData `json:"objects"`
+	// ResolvedNames maps each SuiNS name in the arguments to the address it resolved to
+	ResolvedNames map[string]string `json:"resolvedNames,omitempty"`
+}
+
+// GasSummary is the structured result of sui-gas
+type GasSummary struct {
+	Address  string        `json:"address,omitempty"`
+	GasCoins []sui.GasCoin `json:"gasCoins"`
+	// TotalMist is the sum of all gas coin balances
+	TotalMist uint64 `json:"totalMist"`
+}
+
+// TransactionSummary is the structured result of sui-process-transaction and every signing tool
+type TransactionSummary struct {
+	// DryRun is set when the transaction was only simulated and nothing was submitted
+	DryRun      bool   `json:"dryRun,omitempty"`
+	Digest      string `json:"digest"`
+	Status      string `json:"status"`
+	Error       string `json:"error,omitempty"`
+	Sender      string `json:"sender,omitempty"`
+	Checkpoint  string `json:"checkpoint,omitempty"`
+	TimestampMs string `json:"timestampMs,omitempty"`
+	// GasUsed is the raw gas breakdown and NetGasCost is computation + storage - rebate, in MIST
+	GasUsed        sui.GasCostSummary  `json:"gasUsed"`
+	NetGasCost     string              `json:"netGasCost"`
+	BalanceChanges []sui.BalanceChange `json:"balanceChanges,omitempty"`
+	ObjectChanges  []sui.ObjectChange  `json:"objectChanges,omitempty"`
+	Events         []sui.Event         `json:"events,omitempty"`
+	// SelectedCoins are the input coins chosen automatically, when the call named none
+	SelectedCoins []string `json:"selectedCoins,omitempty"`
+	// ResolvedNames maps each SuiNS name in the arguments to the address it resolved to
+	ResolvedNames map[string]string `json:"resolvedNames,omitempty"`
+}
+
+// TransactionListEntry is the compact form of one transaction in a listing
+type TransactionListEntry struct {
+	Digest string `json:"digest"`
+	Status string `json:"status,omitempty"`
+	Sender string `json:"sender,omitempty"`
+	// Time is the checkpoint timestamp in RFC 3339 form; TimestampMs is the raw value
+	Time           string              `json:"time,omitempty"`
+	TimestampMs    string              `json:"timestampMs,omitempty"`
+	Checkpoint     string              `json:"checkpoint,omitempty"`
+	NetGasCost     string              `json:"netGasCost"`
+	BalanceChanges []sui.BalanceChange `json:"balanceChanges,omitempty"`
+}
+
+// TransactionList is the structured result of sui-query-transactions
+type TransactionList struct {
+	// Filter is the fullnode transaction filter the query used
+	Filter       map[string]any         `json:"filter"`
+	Transactions []TransactionListEntry `json:"transactions"`
+	// NextCursor continues the listing when HasNextPage is set
+	NextCursor  string `json:"nextCursor,omitempty"`
+	HasNextPage bool   `json:"hasNextPage"`
+	// ResolvedNames maps each SuiNS name in the arguments to the address it resolved to
+	ResolvedNames map[string]string `json:"resolvedNames,omitempty"`
+}
+
+// EventListEntry is one event in a listing, with its Move fields decoded
+type EventListEntry struct {
+	TxDigest string `json:"txDigest"`
+	EventSeq string `json:"eventSeq"`
+	Type     string `json:"type"`
+	Sender   string `json:"sender,omitempty"`
+	// PackageID and Module are the package and module the emitting transaction called
+	PackageID string `json:"packageId,omitempty"`
+	Module    string `json:"module,omitempty"`
+	// Time is the checkpoint timestamp in RFC 3339 form; TimestampMs is the raw value
+	Time        string `json:"time,omitempty"`
+	TimestampMs string `json:"timestampMs,omitempty"`
+	// Fields are the event's Move fields as decoded by the fullnode
+	Fields any `json:"fields,omitempty"`
+}
+
+// EventList is the structured result of sui-query-events
+type EventList struct {
+	// Filter is the fullnode event filter the query used
+	Filter map[string]any   `json:"filter"`
+	Events []EventListEntry `json:"events"`
+	// NextCursor continues the listing when HasNextPage is set
+	NextCursor  string `json:"nextCursor,omitempty"`
+	HasNextPage bool   `json:"hasNextPage"`
+	// ResolvedNames maps each SuiNS name in the arguments to the address it resolved to
+	ResolvedNames map[string]string `json:"resolvedNames,omitempty"`
+}
+
+// SubscriptionsSummary is the structured result of sui-subscribe, sui-unsubscribe and sui-subscriptions
+type SubscriptionsSummary struct {
+	Subscriptions []subscriptions.Subscription `json:"subscriptions"`
+	// ResolvedNames maps each SuiNS name in the arguments to the address it resolved to
+	ResolvedNames map[string]string `json:"resolvedNames,omitempty"`
+}
+
+// newBalanceResult renders balances as structured content with a readable table
+func newBalanceResult(address string, balances []sui.Balance) *mcp.CallToolResult {
+	if balances == nil {
+		balances = []sui.Balance{}
+	}
+	summary := BalanceSummary{Address: address, Balances: balances}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Balances of %s:\n", addressOrActive(address))
+	if len(balances) == 0 {
+		b.WriteString("- no coins\n")
+	}
+	for _, bal := range balances {
+		fmt.Fprintf(&b, "- %s: %s (%d coin objects)\n", bal.CoinType, bal.TotalBalance, bal.CoinObjectCount)
+	}
+	return mcp.NewToolResultStructured(summary, b.String())
+}
+
+// newObjectsResult renders owned objects as structured content with one line per object
+func newObjectsResult(address string, objects []sui.ObjectData) *mcp.CallToolResult {
+	if objects == nil {
+		objects = []sui.ObjectData{}
+	}
+	summary := ObjectsSummary{Address: address, Objects: objects}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d objects owned by %s:\n", len(objects), addressOrActive(address))
+	for _, obj := range objects {
+		fmt.Fprintf(&b, "- %s %s (version %d)\n", obj.ObjectID, obj.Type, obj.Version)
+	}
+	return mcp.NewToolResultStructured(summary, b.String())
+}
+
+// newObjectResult renders a single object as structured content with a short description
+func newObjectResult(obj *sui.ObjectData) *mcp.CallToolResult {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Object %s\n", obj.ObjectID)
+	fmt.Fprintf(&b, "Type: %s\n", obj.Type)
+	fmt.Fprintf(&b, "Version: %d\n", obj.Version)
+	fmt.Fprintf(&b, "Digest: %s\n", obj.Digest)
+	if obj.Owner != nil {
+		fmt.Fprintf(&b, "Owner: %s\n", obj.Owner)
+	}
+	if fields, ok := obj.Content["fields"].(map[string]any); ok {
+		b.WriteString("Fields:\n")
+		for _, name := range slices.Sorted(maps.Keys(fields)) {
+			fmt.Fprintf(&b, "  %s: %v\n", name, fields[name])
+		}
+	}
+	return mcp.NewToolResultStructured(obj, b.String())
+}
+
+// newGasResult renders gas coins as structured content with their balances
+func newGasResult(address string, gasCoins []sui.GasCoin) *mcp.CallToolResult {
+	if gasCoins == nil {
+		gasCoins = []sui.GasCoin{}
+	}
+	summary := GasSummary{Address: address, GasCoins: gasCoins}
+	for _, coin := range gasCoins {
+		summary.TotalMist += coin.MistBalance
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Gas coins of %s (total %d MIST, %s SUI):\n", addressOrActive(address), summary.TotalMist, sui.FormatMist(summary.TotalMist))
+	for _, coin := range gasCoins {
+		fmt.Fprintf(&b, "- %s: %d MIST\n", coin.GasCoinID, coin.MistBalance)
+	}
+	return mcp.NewToolResultStructured(summary, b.String())
+}
+
+// summarizeTransaction flattens a transaction response into a TransactionSummary
+func summarizeTransaction(resp *sui.TransactionBlockResponse, dryRun bool) TransactionSummary {
+	summary := TransactionSummary{
+		DryRun:         dryRun,
+		Digest:         resp.Digest,
+		Checkpoint:     resp.Checkpoint,
+		TimestampMs:    resp.TimestampMs,
+		BalanceChanges: resp.BalanceChanges,
+		ObjectChanges:  resp.ObjectChanges,
+		Events:         resp.Events,
+	}
+	if resp.Transaction != nil {
+		summary.Sender = resp.Transaction.Data.Sender
+	} else if resp.Input != nil {
+		summary.Sender = resp.Input.Sender
+	}
+	if resp.Effects != nil {
+		summary.Status = resp.Effects.Status.Status
+		summary.Error = resp.Effects.Status.Error
+		summary.GasUsed = resp.Effects.GasUsed
+		if summary.Digest == "" {
+			summary.Digest = resp.Effects.TransactionDigest
+		}
+	}
+	summary.NetGasCost = netGasCost(summary.GasUsed)
+	return summary
+}
+
+// newTransactionResult renders a transaction as structured content with a readable receipt
+func newTransactionResult(resp *sui.TransactionBlockResponse, dryRun bool) *mcp.CallToolResult {
+	return renderTransaction(summarizeTransaction(resp, dryRun))
+}
+
+// newSelectedCoinsResult renders a transaction whose input coins were selected automatically
+func newSelectedCoinsResult(resp *sui.TransactionBlockResponse, dryRun bool, selected []string) *mcp.CallToolResult {
+	summary := summarizeTransaction(resp, dryRun)
+	summary.SelectedCoins = selected
+	return renderTransaction(summary)
+}
+
+// renderTransaction renders a transaction summary as structured content with a readable receipt
+func renderTransaction(summary TransactionSummary) *mcp.CallToolResult {
+	var b strings.Builder
+	if summary.DryRun {
+		b.WriteString("Dry run only: the transaction was NOT submitted. Predicted effects:\n")
+	}
+	fmt.Fprintf(&b, "Transaction: %s\n", summary.Digest)
+	fmt.Fprintf(&b, "Status: %s\n", summary.Status)
+	if summary.Error != "" {
+		fmt.Fprintf(&b, "Error: %s\n", summary.Error)
+	}
+	if summary.Sender != "" {
+		fmt.Fprintf(&b, "Sender: %s\n", summary.Sender)
+	}
+	b.WriteString("Gas:\n")
+	fmt.Fprintf(&b, "  Storage Cost: %s MIST\n", summary.GasUsed.StorageCost)
+	fmt.Fprintf(&b, "  Computation Cost: %s MIST\n", summary.GasUsed.ComputationCost)
+	fmt.Fprintf(&b, "  Storage Rebate: %s MIST\n", summary.GasUsed.StorageRebate)
+	fmt.Fprintf(&b, "  Non-refundable Storage Fee: %s MIST\n", summary.GasUsed.NonRefundableStorageFee)
+	fmt.Fprintf(&b, "  Net Gas Cost: %s MIST\n", summary.NetGasCost)
+	if len(summary.SelectedCoins) > 0 {
+		fmt.Fprintf(&b, "Input Coins (selected automatically): %s\n", strings.Join(summary.SelectedCoins, ", "))
+	}
+	if len(summary.BalanceChanges) > 0 {
+		b.WriteString("Balance Changes:\n")
+		for _, change := range summary.BalanceChanges {
+			fmt.Fprintf(&b, "  %s: %s %s\n", change.Owner, change.Amount, change.CoinType)
+		}
+	}
+	if len(summary.ObjectChanges) > 0 {
+		b.WriteString("Object Changes:\n")
+		for _, change := range summary.ObjectChanges {
+			id := change.ObjectID
+			if id == "" {
+				id = change.PackageID
+			}
+			fmt.Fprintf(&b, "  %s %s %s\n", change.Type, id, change.ObjectType)
+		}
+	}
+	return mcp.NewToolResultStructured(summary, b.String())
+}
+
+// summarizeListedTransaction reduces a transaction to its TransactionListEntry
+func summarizeListedTransaction(tx sui.TransactionBlockResponse) TransactionListEntry {
+	summary := summarizeTransaction(&tx, false)
+	entry := TransactionListEntry{
+		Digest:         summary.Digest,
+		Status:         summary.Status,
+		Sender:         summary.Sender,
+		TimestampMs:    summary.TimestampMs,
+		Checkpoint:     summary.Checkpoint,
+		NetGasCost:     summary.NetGasCost,
+		BalanceChanges: summary.BalanceChanges,
+	}
+	if ms, ok := parseTimestampMs(tx.TimestampMs); ok {
+		entry.Time = time.UnixMilli(ms).UTC().Format(time.RFC3339)
+	}
+	return entry
+}
+
+// newTransactionListResult renders a transaction listing with one line per transaction
+func newTransactionListResult(list TransactionList) *mcp.CallToolResult {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d transactions:\n", len(list.Transactions))
+	for _, tx := range list.Transactions {
+		fmt.Fprintf(&b, "- %s %s %s from %s, gas %s MIST\n", tx.Time, tx.Digest, tx.Status, tx.Sender, tx.NetGasCost)
+		for _, change := range tx.BalanceChanges {
+			fmt.Fprintf(&b, "    %s: %s %s\n", change.Owner, change.Amount, change.CoinType)
+		}
+	}
+	if list.HasNextPage {
+		fmt.Fprintf(&b, "More transactions follow: pass cursor %s to continue\n", list.NextCursor)
+	}
+	return mcp.NewToolResultStructured(list, b.String())
+}
+
+// summarizeListedEvent reduces an event to its EventListEntry
+func summarizeListedEvent(event sui.Event) EventListEntry {
+	entry := EventListEntry{
+		TxDigest:    event.ID.TxDigest,
+		EventSeq:    event.ID.EventSeq,
+		Type:        event.Type,
+		Sender:      event.Sender,
+		PackageID:   event.PackageID,
+		Module:      event.TransactionModule,
+		TimestampMs: event.TimestampMs,
+		Fields:      event.ParsedJSON,
+	}
+	if ms, ok := parseTimestampMs(event.TimestampMs); ok {
+		entry.Time = time.UnixMilli(ms).UTC().Format(time.RFC3339)
+	}
+	return entry
+}
+
+// newEventListResult renders an event listing with the fields of each event
+func newEventListResult(list EventList) *mcp.CallToolResult {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d events:\n", len(list.Events))
+	for _, event := range list.Events {
+		fmt.Fprintf(&b, "- %s %s from %s (tx %s, event %s)\n", event.Time, event.Type, event.Sender, event.TxDigest, event.EventSeq)
+		fields, ok := event.Fields.(map[string]any)
+		if !ok {
+			if event.Fields != nil {
+				fmt.Fprintf(&b, "    %s\n", compactJSON(event.Fields))
+			}
+			continue
+		}
+		for _, name := range slices.Sorted(maps.Keys(fields)) {
+			fmt.Fprintf(&b, "    %s: %s\n", name, compactJSON(fields[name]))
+		}
+	}
+	if list.HasNextPage {
+		fmt.Fprintf(&b, "More events follow: pass cursor %s to continue\n", list.NextCursor)
+	}
+	return mcp.NewToolResultStructured(list, b.String())
+}
+
+// newSubscriptionsResult renders subscriptions with one line each under heading
+func newSubscriptionsResult(heading string, subs []subscriptions.Subscription) *mcp.CallToolResult {
+	if subs == nil {
+		subs = []subscriptions.Subscription{}
+	}
+	var b strings.Builder
+	b.WriteString(heading + "\n")
+	if len(subs) == 0 {
+		b.WriteString("- none\n")
+	}
+	for _, sub := range subs {
+		fmt.Fprintf(&b, "- %s: %s ", sub.ID, sub.Kind)
+		switch sub.Kind {
+		case subscriptions.KindEvents:
+			b.WriteString(compactJSON(sub.EventFilter))
+		case subscriptions.KindAddress:
+			b.WriteString(sub.Address)
+		case subscriptions.KindObject:
+			b.WriteString(sub.ObjectID)
+		}
+		if sub.Env != "" {
+			fmt.Fprintf(&b, " on %s", sub.Env)
+		}
+		if sub.URI != "" {
+			fmt.Fprintf(&b, " for %s", sub.URI)
+		}
+		b.WriteString("\n")
+	}
+	return mcp.NewToolResultStructured(SubscriptionsSummary{Subscriptions: subs}, b.String())
+}
+
+// compactJSON renders a decoded JSON value on one line; strings are shown bare
+func compactJSON(v any) string {
+	if str, ok := v.(string); ok {
+		return str
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(raw)
+}
+
+// netGasCost returns computation + storage - rebate as a decimal string
+func netGasCost(gas sui.GasCostSummary) string {
+	total := new(big.Int)
+	for _, part := range []struct {
+		value string
+		sign  int
+	}{
+		{gas.ComputationCost, 1},
+		{gas.StorageCost, 1},
+		{gas.StorageRebate, -1},
+	} {
+		n, ok := new(big.Int).SetString(part.value, 10)
+		if !ok {
+			continue
+		}
+		if part.sign < 0 {
+			total.Sub(total, n)
+		} else {
+			total.Add(total, n)
+		}
+	}
+	return total.String()
+}
+
+// addressOrActive labels an empty address as the CLI's active address
+func addressOrActive(address string) string {
+	if address == "" {
+		return "the active address"
+	}
+	return address
+}
@@ -0,0 +1,41 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/krli/go-sui-mcp/internal/sui"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// SenderMiddleware signs each call with the address named by its sender
+// argument, which must be in the keystore. The call runs with its own client
+// config, so concurrent calls never switch the shared active address.
+// Calls without a sender sign with the active address as before.
+func SenderMiddleware(client *sui.Client) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			sender, _ := request.GetArguments()["sender"].(string)
+			if sender == "" {
+				return next(ctx, request)
+			}
+
+			refuse := func(reason string, args ...any) (*mcp.CallToolResult, error) {
+				return mcp.NewToolResultError(fmt.Sprintf("%s was not executed: %s", request.Params.Name, fmt.Sprintf(reason, args...))), nil
+			}
+			addresses, err := client.ListAddresses(ctx)
+			if err != nil {
+				return refuse("cannot list keystore addresses: %v", err)
+			}
+			if !addresses.Contains(sender) {
+				return refuse("sender %s is not an address in the keystore", sender)
+			}
+			env, err := client.ResolveSender(ctx, sender)
+			if err != nil {
+				return refuse("%v", err)
+			}
+			return next(sui.WithEnv(ctx, env), request)
+		}
+	}
+}
@@ -4,15 +4,22 @@ import (
 	"strings"
 
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"math/big"
 
+	"github.com/krli/go-sui-mcp/internal/secrets"
+	"github.com/krli/go-sui-mcp/internal/subscriptions"
 	"github.com/krli/go-sui-mcp/internal/sui"
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
 // SuiService provides higher-level operations on the Sui blockchain
 type SuiService struct {
-	client *sui.Client
+	client        *sui.Client
+	secrets       secrets.Sink
+	subscriptions *subscriptions.Manager
 }
 
 // NewSuiService creates a new Sui service
@@ -22,9 +29,19 @@ func NewSuiService(client *sui.Client) *SuiService {
 	}
 }
 
+// UseSecretSink enables the keytool tools that produce secrets, storing their output in sink
+func (s *SuiService) UseSecretSink(sink secrets.Sink) {
+	s.secrets = sink
+}
+
+// UseSubscriptions enables the subscription tools, backed by manager
+func (s *SuiService) UseSubscriptions(manager *subscriptions.Manager) {
+	s.subscriptions = manager
+}
+
 // GetFormattedVersion returns a cleaned version string
 func (s *SuiService) GetFormattedVersion(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	version, err := s.client.GetVersion()
+	version, err := s.client.GetVersion(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -34,76 +51,236 @@ func (s *SuiService) GetFormattedVersion(ctx context.Context, request mcp.CallTo
 }
 
 func (s *SuiService) GetSuiPath(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	path, err := s.client.GetSuiPath()
+	path, err := s.client.GetSuiPath(ctx)
 	if err != nil {
 		return nil, err
 	}
 	return mcp.NewToolResultText(path), nil
 }
 
-// GetBalanceSummary returns a summary of the balance for an address
-type BalanceSummary struct {
-	Address     string `json:"address"`
-	TotalCoins  uint64 `json:"total_coins"`
-	CoinCount   int    `json:"coin_count"`
-	CoinObjects []any  `json:"coin_objects"`
-}
-
 // GetBalanceSummary returns a structured summary of the balance for an address
 func (s *SuiService) GetBalanceSummary(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	address, _ := request.Params.Arguments["address"].(string)
-	output, err := s.client.GetBalance(address)
+	address, _ := request.GetArguments()["address"].(string)
+	balances, err := s.client.GetBalance(ctx, address)
 	if err != nil {
 		return nil, err
 	}
 
-	return mcp.NewToolResultText(output), nil
+	return newBalanceResult(address, balances), nil
 }
 
 // GetObjectsSummary gets a summary of objects owned by an address
 func (s *SuiService) GetObjectsSummary(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	address, _ := request.Params.Arguments["address"].(string)
+	address, _ := request.GetArguments()["address"].(string)
 
-	output, err := s.client.GetObjects(address)
+	objects, err := s.client.GetObjects(ctx, address)
 	if err != nil {
 		return nil, err
 	}
 
-	return mcp.NewToolResultText(output), nil
+	return newObjectsResult(address, objects), nil
 }
 
 // GetObject processes a transaction and returns readable information
 func (s *SuiService) GetObject(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	objectID, ok := request.Params.Arguments["objectID"].(string)
+	objectID, ok := request.GetArguments()["objectID"].(string)
 	if !ok {
 		return nil, errors.New("objectID must be a string")
 	}
-	output, err := s.client.GetObject(objectID)
+	object, err := s.client.GetObject(ctx, objectID)
 	if err != nil {
 		return nil, err
 	}
 
-	return mcp.NewToolResultText(output), nil
+	return newObjectResult(object), nil
+}
+
+// ResolveName resolves a SuiNS name to an address, or an address to its names
+func (s *SuiService) ResolveName(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	name, _ := request.GetArguments()["name"].(string)
+	address, _ := request.GetArguments()["address"].(string)
+	if (name == "") == (address == "") {
+		return nil, errors.New("exactly one of name or address must be given")
+	}
+
+	if name != "" {
+		resolved, err := s.client.ResolveName(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("%s -> %s", name, resolved)), nil
+	}
+
+	names, err := s.client.LookupNames(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+	if len(names) == 0 {
+		return mcp.NewToolResultText(fmt.Sprintf("No SuiNS names point at %s", address)), nil
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("%s <- %s", address, strings.Join(names, ", "))), nil
 }
 
 // ProcessTransaction processes a transaction and returns readable information
 func (s *SuiService) ProcessTransaction(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	txID, ok := request.Params.Arguments["txID"].(string)
+	txID, ok := request.GetArguments()["txID"].(string)
 	if !ok {
 		return nil, errors.New("txID must be a string")
 	}
-	output, err := s.client.GetTransaction(txID)
+	tx, err := s.client.GetTransaction(ctx, txID)
 	if err != nil {
 		return nil, err
 	}
 
-	return mcp.NewToolResultText(output), nil
+	return newTransactionResult(tx, false), nil
+}
+
+// QueryTransactions lists the transactions matching one filter, newest first by default
+func (s *SuiService) QueryTransactions(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	filter, err := transactionFilter(request)
+	if err != nil {
+		return nil, err
+	}
+	within, err := parseTimeRange(request)
+	if err != nil {
+		return nil, err
+	}
+	limit, err := parseQueryLimit(request)
+	if err != nil {
+		return nil, err
+	}
+	descending, err := parseDescending(request)
+	if err != nil {
+		return nil, err
+	}
+	cursor, _ := request.GetArguments()["cursor"].(string)
+
+	list, err := s.queryTransactions(ctx, filter, within, cursor, limit, descending)
+	if err != nil {
+		return nil, err
+	}
+	return newTransactionListResult(list), nil
+}
+
+// QueryEvents lists the Move events matching one filter, newest first by default
+func (s *SuiService) QueryEvents(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	within, err := parseTimeRange(request)
+	if err != nil {
+		return nil, err
+	}
+	filter, err := eventFilter(request, within)
+	if err != nil {
+		return nil, err
+	}
+	limit, err := parseQueryLimit(request)
+	if err != nil {
+		return nil, err
+	}
+	descending, err := parseDescending(request)
+	if err != nil {
+		return nil, err
+	}
+	cursorArg, _ := request.GetArguments()["cursor"].(string)
+	cursor, err := parseEventCursor(cursorArg)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := s.queryEvents(ctx, filter, within, cursor, limit, descending)
+	if err != nil {
+		return nil, err
+	}
+	return newEventListResult(list), nil
+}
+
+// Subscribe starts watching events, an address or an object for the calling session
+func (s *SuiService) Subscribe(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	session, err := s.subscriptionSession(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	args := request.GetArguments()
+	kind, _ := args["kind"].(string)
+	spec := subscriptions.Spec{Kind: kind}
+	switch kind {
+	case subscriptions.KindEvents:
+		given := func(name string) bool {
+			v, _ := args[name].(string)
+			return v != ""
+		}
+		if !given("from-address") && !given("event-type") && !given("package") {
+			return nil, errors.New("an events subscription needs from-address, event-type or package and module")
+		}
+		if spec.EventFilter, err = eventFilter(request, timeRange{}); err != nil {
+			return nil, err
+		}
+	case subscriptions.KindAddress:
+		if spec.Address, _ = args["address"].(string); spec.Address == "" {
+			return nil, errors.New("an address subscription needs address")
+		}
+	case subscriptions.KindObject:
+		if spec.ObjectID, _ = args["object-id"].(string); spec.ObjectID == "" {
+			return nil, errors.New("an object subscription needs object-id")
+		}
+	}
+
+	sub, err := s.subscriptions.Subscribe(ctx, session, spec)
+	if err != nil {
+		return nil, err
+	}
+	return newSubscriptionsResult(fmt.Sprintf("Subscribed as %s; changes arrive as notifications/message from logger %s:", sub.ID, subscriptions.Logger), []subscriptions.Subscription{sub}), nil
+}
+
+// Unsubscribe stops one or all of the calling session's subscriptions
+func (s *SuiService) Unsubscribe(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	session, err := s.subscriptionSession(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	id, _ := request.GetArguments()["id"].(string)
+	all, _ := request.GetArguments()["all"].(bool)
+	var heading string
+	switch {
+	case all:
+		heading = fmt.Sprintf("Stopped %d subscriptions.", s.subscriptions.CloseSession(session))
+	case id != "":
+		if err := s.subscriptions.Unsubscribe(session, id); err != nil {
+			return nil, err
+		}
+		heading = fmt.Sprintf("Stopped %s.", id)
+	default:
+		return nil, errors.New("give id, or all to stop every subscription")
+	}
+	return newSubscriptionsResult(heading+" Remaining subscriptions:", s.subscriptions.List(session)), nil
+}
+
+// ListSubscriptions lists the calling session's subscriptions
+func (s *SuiService) ListSubscriptions(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	session, err := s.subscriptionSession(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return newSubscriptionsResult("Subscriptions of this session:", s.subscriptions.List(session)), nil
+}
+
+// subscriptionSession returns the session subscriptions of the call belong to
+func (s *SuiService) subscriptionSession(ctx context.Context) (string, error) {
+	if s.subscriptions == nil {
+		return "", errors.New("subscriptions are not enabled on this server")
+	}
+	session := sessionID(ctx)
+	if session == "" {
+		return "", errors.New("subscriptions need a client session to notify")
+	}
+	return session, nil
 }
 
 // PaySUI transfers tokens and returns the transaction result
 func (s *SuiService) PaySUI(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	// Parse recipients
-	recipientsInterface, ok := request.Params.Arguments["recipients"].([]interface{})
+	recipientsInterface, ok := request.GetArguments()["recipients"].([]interface{})
 	if !ok {
 		return nil, errors.New("recipients must be an array")
 	}
@@ -116,49 +293,44 @@ func (s *SuiService) PaySUI(ctx context.Context, request mcp.CallToolRequest) (*
 		}
 	}
 
-	// Parse amounts
-	amountsInterface, ok := request.Params.Arguments["amounts"].([]interface{})
-	if !ok {
-		return nil, errors.New("amounts must be an array")
-	}
-	amounts := make([]uint64, len(amountsInterface))
-	for i, v := range amountsInterface {
-		if num, ok := v.(float64); ok {
-			amounts[i] = uint64(num)
-		} else {
-			return nil, errors.New("amounts must contain numbers")
-		}
+	// Parse amounts, such as "1.5 SUI" or a MIST integer
+	amounts, err := s.parseAmounts(ctx, request, "amounts", fixedCoinType(sui.SUICoinType))
+	if err != nil {
+		return nil, err
 	}
 
-	// Parse input-coins
-	inputCoinsInterface, ok := request.Params.Arguments["input-coins"].([]interface{})
-	if !ok {
-		return nil, errors.New("input-coins must be an array")
+	// Parse input-coins; they are selected automatically when omitted
+	inputCoins, err := optionalStrings(request, "input-coins")
+	if err != nil {
+		return nil, err
 	}
-	inputCoins := make([]string, len(inputCoinsInterface))
-	for i, v := range inputCoinsInterface {
-		if str, ok := v.(string); ok {
-			inputCoins[i] = str
-		} else {
-			return nil, errors.New("input-coins must contain strings")
+
+	opts := txOptions(request)
+
+	// The first input coin also pays for gas, so the selection covers the amounts plus the gas reserve
+	var selected []string
+	if len(inputCoins) == 0 {
+		selection, err := s.selectCoins(ctx, sui.SUICoinType, new(big.Int).Add(sumAmounts(amounts), gasReserve(opts)))
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("%s was not executed: %v", request.Params.Name, err)), nil
 		}
+		inputCoins = selection.IDs()
+		selected = inputCoins
 	}
 
-	gasBudget, _ := request.Params.Arguments["gas-budget"].(string)
-
-	output, err := s.client.PaySUI(recipients, inputCoins, amounts, gasBudget)
+	tx, err := s.client.PaySUI(ctx, recipients, inputCoins, amounts, opts)
 	if err != nil {
 		return nil, err
 	}
 
-	return mcp.NewToolResultText(output), nil
+	return newSelectedCoinsResult(tx, opts.DryRun, selected), nil
 }
 
 // ============ Address and Environment Management ============
 
 // GetActiveAddress returns the current active address
 func (s *SuiService) GetActiveAddress(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	output, err := s.client.GetActiveAddress()
+	output, err := s.client.GetActiveAddress(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -167,16 +339,42 @@ func (s *SuiService) GetActiveAddress(ctx context.Context, request mcp.CallToolR
 
 // GetAddresses returns all addresses managed by the client
 func (s *SuiService) GetAddresses(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	output, err := s.client.GetAddresses()
+	output, err := s.client.GetAddresses(ctx)
 	if err != nil {
 		return nil, err
 	}
 	return mcp.NewToolResultText(output), nil
 }
 
+// SwitchAddress changes the active address of the shared client config
+func (s *SuiService) SwitchAddress(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	address, ok := request.GetArguments()["address"].(string)
+	if !ok {
+		return nil, errors.New("address must be a string")
+	}
+	output, err := s.client.SwitchAddress(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+	return mcp.NewToolResultText(strings.TrimSpace(output)), nil
+}
+
+// SwitchEnv changes the active environment of the shared client config
+func (s *SuiService) SwitchEnv(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	env, ok := request.GetArguments()["env"].(string)
+	if !ok {
+		return nil, errors.New("env must be a string")
+	}
+	output, err := s.client.SwitchEnv(ctx, env)
+	if err != nil {
+		return nil, err
+	}
+	return mcp.NewToolResultText(strings.TrimSpace(output)), nil
+}
+
 // GetActiveEnv returns the current active environment
 func (s *SuiService) GetActiveEnv(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	output, err := s.client.GetActiveEnv()
+	output, err := s.client.GetActiveEnv(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -185,7 +383,7 @@ func (s *SuiService) GetActiveEnv(ctx context.Context, request mcp.CallToolReque
 
 // GetEnvs returns all Sui environments
 func (s *SuiService) GetEnvs(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	output, err := s.client.GetEnvs()
+	output, err := s.client.GetEnvs(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -194,7 +392,7 @@ func (s *SuiService) GetEnvs(ctx context.Context, request mcp.CallToolRequest) (
 
 // GetChainIdentifier queries the chain identifier from the RPC endpoint
 func (s *SuiService) GetChainIdentifier(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	output, err := s.client.GetChainIdentifier()
+	output, err := s.client.GetChainIdentifier(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -205,18 +403,18 @@ func (s *SuiService) GetChainIdentifier(ctx context.Context, request mcp.CallToo
 
 // GetGas obtains all gas objects owned by the address
 func (s *SuiService) GetGas(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	address, _ := request.Params.Arguments["address"].(string)
-	output, err := s.client.GetGas(address)
+	address, _ := request.GetArguments()["address"].(string)
+	gasCoins, err := s.client.GetGas(ctx, address)
 	if err != nil {
 		return nil, err
 	}
-	return mcp.NewToolResultText(output), nil
+	return newGasResult(address, gasCoins), nil
 }
 
 // RequestFromFaucet requests gas coins from faucet
 func (s *SuiService) RequestFromFaucet(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	address, _ := request.Params.Arguments["address"].(string)
-	output, err := s.client.RequestFromFaucet(address)
+	address, _ := request.GetArguments()["address"].(string)
+	output, err := s.client.RequestFromFaucet(ctx, address)
 	if err != nil {
 		return nil, err
 	}
@@ -227,113 +425,133 @@ func (s *SuiService) RequestFromFaucet(ctx context.Context, request mcp.CallTool
 
 // Transfer transfers an object to another address
 func (s *SuiService) Transfer(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	to, ok := request.Params.Arguments["to"].(string)
+	to, ok := request.GetArguments()["to"].(string)
 	if !ok {
 		return nil, errors.New("to must be a string")
 	}
-	objectID, ok := request.Params.Arguments["object-id"].(string)
+	objectID, ok := request.GetArguments()["object-id"].(string)
 	if !ok {
 		return nil, errors.New("object-id must be a string")
 	}
-	gasBudget, _ := request.Params.Arguments["gas-budget"].(string)
+	opts := txOptions(request)
 
-	output, err := s.client.Transfer(to, objectID, gasBudget)
+	tx, err := s.client.Transfer(ctx, to, objectID, opts)
 	if err != nil {
 		return nil, err
 	}
-	return mcp.NewToolResultText(output), nil
+	return newTransactionResult(tx, opts.DryRun), nil
 }
 
 // TransferSUI transfers SUI to another address
 func (s *SuiService) TransferSUI(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	to, ok := request.Params.Arguments["to"].(string)
+	to, ok := request.GetArguments()["to"].(string)
 	if !ok {
 		return nil, errors.New("to must be a string")
 	}
-	suiCoinObjectID, ok := request.Params.Arguments["sui-coin-object-id"].(string)
-	if !ok {
-		return nil, errors.New("sui-coin-object-id must be a string")
+	suiCoinObjectID, _ := request.GetArguments()["sui-coin-object-id"].(string)
+
+	amount, err := s.parseAmount(ctx, request, "amount", fixedCoinType(sui.SUICoinType))
+	if err != nil {
+		return nil, err
 	}
 
-	var amount uint64
-	if amountFloat, ok := request.Params.Arguments["amount"].(float64); ok {
-		amount = uint64(amountFloat)
+	opts := txOptions(request)
+
+	if suiCoinObjectID == "" {
+		return s.transferSUISelected(ctx, request, to, amount, opts)
 	}
 
-	gasBudget, _ := request.Params.Arguments["gas-budget"].(string)
+	tx, err := s.client.TransferSUI(ctx, to, suiCoinObjectID, amount, opts)
+	if err != nil {
+		return nil, err
+	}
+	return newTransactionResult(tx, opts.DryRun), nil
+}
 
-	output, err := s.client.TransferSUI(to, suiCoinObjectID, amount, gasBudget)
+// transferSUISelected sends amount to a recipient from automatically selected
+// coins. A single coin that covers the amount and gas is transferred from
+// directly; otherwise several coins are merged and split by a pay-sui.
+func (s *SuiService) transferSUISelected(ctx context.Context, request mcp.CallToolRequest, to string, amount *big.Int, opts sui.TxOptions) (*mcp.CallToolResult, error) {
+	refuse := func(err error) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultError(fmt.Sprintf("%s was not executed: %v", request.Params.Name, err)), nil
+	}
+	if amount == nil || amount.Sign() == 0 {
+		return refuse(errors.New("amount is required when sui-coin-object-id is omitted"))
+	}
+
+	target := new(big.Int).Add(amount, gasReserve(opts))
+	coin, ok, err := s.selectSingleCoin(ctx, target)
+	if err != nil {
+		return refuse(err)
+	}
+	if ok {
+		tx, err := s.client.TransferSUI(ctx, to, coin.CoinObjectID, amount, opts)
+		if err != nil {
+			return nil, err
+		}
+		return newSelectedCoinsResult(tx, opts.DryRun, []string{coin.CoinObjectID}), nil
+	}
+
+	selection, err := s.selectCoins(ctx, sui.SUICoinType, target)
+	if err != nil {
+		return refuse(err)
+	}
+	tx, err := s.client.PaySUI(ctx, []string{to}, selection.IDs(), []*big.Int{amount}, opts)
 	if err != nil {
 		return nil, err
 	}
-	return mcp.NewToolResultText(output), nil
+	return newSelectedCoinsResult(tx, opts.DryRun, selection.IDs()), nil
 }
 
 // SplitCoin splits a coin object into multiple coins
 func (s *SuiService) SplitCoin(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	coinID, ok := request.Params.Arguments["coin-id"].(string)
+	coinID, ok := request.GetArguments()["coin-id"].(string)
 	if !ok {
 		return nil, errors.New("coin-id must be a string")
 	}
 
-	amountsInterface, ok := request.Params.Arguments["amounts"].([]interface{})
-	if !ok {
-		return nil, errors.New("amounts must be an array")
-	}
-
-	amounts := make([]uint64, len(amountsInterface))
-	for i, v := range amountsInterface {
-		if num, ok := v.(float64); ok {
-			amounts[i] = uint64(num)
-		} else {
-			return nil, errors.New("amounts must contain numbers")
-		}
+	amounts, err := s.parseAmounts(ctx, request, "amounts", s.objectCoinType(coinID))
+	if err != nil {
+		return nil, err
 	}
 
-	gasBudget, _ := request.Params.Arguments["gas-budget"].(string)
+	opts := txOptions(request)
 
-	output, err := s.client.SplitCoin(coinID, amounts, gasBudget)
+	tx, err := s.client.SplitCoin(ctx, coinID, amounts, opts)
 	if err != nil {
 		return nil, err
 	}
-	return mcp.NewToolResultText(output), nil
+	return newTransactionResult(tx, opts.DryRun), nil
 }
 
 // MergeCoin merges two coin objects into one
 func (s *SuiService) MergeCoin(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	primaryCoin, ok := request.Params.Arguments["primary-coin"].(string)
+	primaryCoin, ok := request.GetArguments()["primary-coin"].(string)
 	if !ok {
 		return nil, errors.New("primary-coin must be a string")
 	}
-	coinToMerge, ok := request.Params.Arguments["coin-to-merge"].(string)
+	coinToMerge, ok := request.GetArguments()["coin-to-merge"].(string)
 	if !ok {
 		return nil, errors.New("coin-to-merge must be a string")
 	}
-	gasBudget, _ := request.Params.Arguments["gas-budget"].(string)
+	opts := txOptions(request)
 
-	output, err := s.client.MergeCoin(primaryCoin, coinToMerge, gasBudget)
+	tx, err := s.client.MergeCoin(ctx, primaryCoin, coinToMerge, opts)
 	if err != nil {
 		return nil, err
 	}
-	return mcp.NewToolResultText(output), nil
+	return newTransactionResult(tx, opts.DryRun), nil
 }
 
 // Pay pays coins to recipients following specified amounts
 func (s *SuiService) Pay(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	inputCoinsInterface, ok := request.Params.Arguments["input-coins"].([]interface{})
-	if !ok {
-		return nil, errors.New("input-coins must be an array")
-	}
-	inputCoins := make([]string, len(inputCoinsInterface))
-	for i, v := range inputCoinsInterface {
-		if str, ok := v.(string); ok {
-			inputCoins[i] = str
-		} else {
-			return nil, errors.New("input-coins must contain strings")
-		}
+	// Input coins are selected automatically from coin-type when omitted
+	inputCoins, err := optionalStrings(request, "input-coins")
+	if err != nil {
+		return nil, err
 	}
 
-	recipientsInterface, ok := request.Params.Arguments["recipients"].([]interface{})
+	recipientsInterface, ok := request.GetArguments()["recipients"].([]interface{})
 	if !ok {
 		return nil, errors.New("recipients must be an array")
 	}
@@ -346,31 +564,62 @@ func (s *SuiService) Pay(ctx context.Context, request mcp.CallToolRequest) (*mcp
 		}
 	}
 
-	amountsInterface, ok := request.Params.Arguments["amounts"].([]interface{})
-	if !ok {
-		return nil, errors.New("amounts must be an array")
-	}
-	amounts := make([]uint64, len(amountsInterface))
-	for i, v := range amountsInterface {
-		if num, ok := v.(float64); ok {
-			amounts[i] = uint64(num)
+	// Amounts with a unit are denominated in coin-type, or else in the type of the first input coin
+	coinType, _ := request.GetArguments()["coin-type"].(string)
+	amountsCoinType := fixedCoinType(coinType)
+	if coinType == "" {
+		if len(inputCoins) > 0 {
+			amountsCoinType = s.objectCoinType(inputCoins[0])
 		} else {
-			return nil, errors.New("amounts must contain numbers")
+			amountsCoinType = func(context.Context) (string, error) {
+				return "", errors.New("coin-type is not set")
+			}
 		}
 	}
+	amounts, err := s.parseAmounts(ctx, request, "amounts", amountsCoinType)
+	if err != nil {
+		return nil, err
+	}
 
-	gasBudget, _ := request.Params.Arguments["gas-budget"].(string)
+	opts := txOptions(request)
 
-	output, err := s.client.Pay(inputCoins, recipients, amounts, gasBudget)
+	var selected []string
+	if len(inputCoins) == 0 {
+		if coinType == "" {
+			return mcp.NewToolResultError(fmt.Sprintf("%s was not executed: coin-type is required when input-coins is omitted", request.Params.Name)), nil
+		}
+		if inputCoins, err = s.selectPayCoins(ctx, coinType, sumAmounts(amounts), opts); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("%s was not executed: %v", request.Params.Name, err)), nil
+		}
+		selected = inputCoins
+	}
+
+	tx, err := s.client.Pay(ctx, inputCoins, recipients, amounts, opts)
 	if err != nil {
 		return nil, err
 	}
-	return mcp.NewToolResultText(output), nil
+	return newSelectedCoinsResult(tx, opts.DryRun, selected), nil
+}
+
+// selectPayCoins picks input coins of coinType for a pay. The CLI then picks a
+// gas coin among the SUI coins not used as input, so paying in SUI must leave
+// one large enough behind.
+func (s *SuiService) selectPayCoins(ctx context.Context, coinType string, total *big.Int, opts sui.TxOptions) ([]string, error) {
+	selection, err := s.selectCoins(ctx, coinType, total)
+	if err != nil {
+		return nil, err
+	}
+	if isSUICoinType(coinType) {
+		if len(selection.Rest) == 0 || coinBalance(selection.Rest[0]).Cmp(gasReserve(opts)) < 0 {
+			return nil, errors.New("no SUI coin is left over to pay for gas; use sui-pay-sui, which pays gas from the input coins")
+		}
+	}
+	return selection.IDs(), nil
 }
 
 // PayAllSUI pays all residual SUI coins to the recipient
 func (s *SuiService) PayAllSUI(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	inputCoinsInterface, ok := request.Params.Arguments["input-coins"].([]interface{})
+	inputCoinsInterface, ok := request.GetArguments()["input-coins"].([]interface{})
 	if !ok {
 		return nil, errors.New("input-coins must be an array")
 	}
@@ -383,39 +632,39 @@ func (s *SuiService) PayAllSUI(ctx context.Context, request mcp.CallToolRequest)
 		}
 	}
 
-	recipient, ok := request.Params.Arguments["recipient"].(string)
+	recipient, ok := request.GetArguments()["recipient"].(string)
 	if !ok {
 		return nil, errors.New("recipient must be a string")
 	}
 
-	gasBudget, _ := request.Params.Arguments["gas-budget"].(string)
+	opts := txOptions(request)
 
-	output, err := s.client.PayAllSUI(inputCoins, recipient, gasBudget)
+	tx, err := s.client.PayAllSUI(ctx, inputCoins, recipient, opts)
 	if err != nil {
 		return nil, err
 	}
-	return mcp.NewToolResultText(output), nil
+	return newTransactionResult(tx, opts.DryRun), nil
 }
 
 // ============ Contract Interaction ============
 
 // Call calls a Move function
 func (s *SuiService) Call(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	packageID, ok := request.Params.Arguments["package"].(string)
+	packageID, ok := request.GetArguments()["package"].(string)
 	if !ok {
 		return nil, errors.New("package must be a string")
 	}
-	module, ok := request.Params.Arguments["module"].(string)
+	module, ok := request.GetArguments()["module"].(string)
 	if !ok {
 		return nil, errors.New("module must be a string")
 	}
-	function, ok := request.Params.Arguments["function"].(string)
+	function, ok := request.GetArguments()["function"].(string)
 	if !ok {
 		return nil, errors.New("function must be a string")
 	}
 
 	var typeArgs []string
-	if typeArgsInterface, ok := request.Params.Arguments["type-args"].([]interface{}); ok {
+	if typeArgsInterface, ok := request.GetArguments()["type-args"].([]interface{}); ok {
 		typeArgs = make([]string, len(typeArgsInterface))
 		for i, v := range typeArgsInterface {
 			if str, ok := v.(string); ok {
@@ -425,7 +674,7 @@ func (s *SuiService) Call(ctx context.Context, request mcp.CallToolRequest) (*mc
 	}
 
 	var args []string
-	if argsInterface, ok := request.Params.Arguments["args"].([]interface{}); ok {
+	if argsInterface, ok := request.GetArguments()["args"].([]interface{}); ok {
 		args = make([]string, len(argsInterface))
 		for i, v := range argsInterface {
 			if str, ok := v.(string); ok {
@@ -434,55 +683,77 @@ func (s *SuiService) Call(ctx context.Context, request mcp.CallToolRequest) (*mc
 		}
 	}
 
-	gasBudget, _ := request.Params.Arguments["gas-budget"].(string)
+	opts := txOptions(request)
 
-	output, err := s.client.Call(packageID, module, function, typeArgs, args, gasBudget)
+	tx, err := s.client.Call(ctx, packageID, module, function, typeArgs, args, opts)
 	if err != nil {
 		return nil, err
 	}
-	return mcp.NewToolResultText(output), nil
+	return newTransactionResult(tx, opts.DryRun), nil
 }
 
 // Publish publishes Move modules
 func (s *SuiService) Publish(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	packagePath, ok := request.Params.Arguments["package-path"].(string)
+	packagePath, ok := request.GetArguments()["package-path"].(string)
 	if !ok {
 		return nil, errors.New("package-path must be a string")
 	}
 
-	gasBudget, _ := request.Params.Arguments["gas-budget"].(string)
-	skipDependencyVerification, _ := request.Params.Arguments["skip-dependency-verification"].(bool)
+	opts := txOptions(request)
+	skipDependencyVerification, _ := request.GetArguments()["skip-dependency-verification"].(bool)
 
-	output, err := s.client.Publish(packagePath, gasBudget, skipDependencyVerification)
+	tx, err := s.client.Publish(ctx, packagePath, opts, skipDependencyVerification)
 	if err != nil {
 		return nil, err
 	}
-	return mcp.NewToolResultText(output), nil
+	return newTransactionResult(tx, opts.DryRun), nil
 }
 
 // GetDynamicField queries a dynamic field by its address
 func (s *SuiService) GetDynamicField(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	parentObjectID, ok := request.Params.Arguments["parent-object-id"].(string)
+	parentObjectID, ok := request.GetArguments()["parent-object-id"].(string)
 	if !ok {
 		return nil, errors.New("parent-object-id must be a string")
 	}
 
-	name, _ := request.Params.Arguments["name"].(string)
+	name, _ := request.GetArguments()["name"].(string)
 
-	output, err := s.client.GetDynamicField(parentObjectID, name)
+	output, err := s.client.GetDynamicField(ctx, parentObjectID, name)
 	if err != nil {
 		return nil, err
 	}
 	return mcp.NewToolResultText(output), nil
 }
 
+// GetMoveModule shows a published Move module in normalized form
+func (s *SuiService) GetMoveModule(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	packageID, ok := request.GetArguments()["package-id"].(string)
+	if !ok {
+		return nil, errors.New("package-id must be a string")
+	}
+	module, ok := request.GetArguments()["module"].(string)
+	if !ok || !moveIdentifierPattern.MatchString(module) {
+		return nil, fmt.Errorf("module %q is not a Move identifier", module)
+	}
+
+	raw, err := s.client.GetMoveModule(ctx, packageID, module)
+	if err != nil {
+		return nil, err
+	}
+	text, err := json.MarshalIndent(raw, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to format module: %w", err)
+	}
+	return mcp.NewToolResultText(string(text)), nil
+}
+
 // ============ Move Development ============
 
 // MoveBuild builds a Move package
 func (s *SuiService) MoveBuild(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	packagePath, _ := request.Params.Arguments["package-path"].(string)
+	packagePath, _ := request.GetArguments()["package-path"].(string)
 
-	output, err := s.client.MoveBuild(packagePath)
+	output, err := s.client.MoveBuild(ctx, packagePath)
 	if err != nil {
 		return nil, err
 	}
@@ -491,10 +762,10 @@ func (s *SuiService) MoveBuild(ctx context.Context, request mcp.CallToolRequest)
 
 // MoveTest runs Move unit tests
 func (s *SuiService) MoveTest(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	packagePath, _ := request.Params.Arguments["package-path"].(string)
-	filter, _ := request.Params.Arguments["filter"].(string)
+	packagePath, _ := request.GetArguments()["package-path"].(string)
+	filter, _ := request.GetArguments()["filter"].(string)
 
-	output, err := s.client.MoveTest(packagePath, filter)
+	output, err := s.client.MoveTest(ctx, packagePath, filter)
 	if err != nil {
 		return nil, err
 	}
@@ -503,14 +774,14 @@ func (s *SuiService) MoveTest(ctx context.Context, request mcp.CallToolRequest)
 
 // MoveNew creates a new Move package
 func (s *SuiService) MoveNew(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	name, ok := request.Params.Arguments["name"].(string)
+	name, ok := request.GetArguments()["name"].(string)
 	if !ok {
 		return nil, errors.New("name must be a string")
 	}
 
-	path, _ := request.Params.Arguments["path"].(string)
+	path, _ := request.GetArguments()["path"].(string)
 
-	output, err := s.client.MoveNew(name, path)
+	output, err := s.client.MoveNew(ctx, name, path)
 	if err != nil {
 		return nil, err
 	}
@@ -521,40 +792,90 @@ func (s *SuiService) MoveNew(ctx context.Context, request mcp.CallToolRequest) (
 
 // KeytoolList lists all keys in the keystore
 func (s *SuiService) KeytoolList(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	output, err := s.client.KeytoolList()
+	output, err := s.client.KeytoolList(ctx)
 	if err != nil {
 		return nil, err
 	}
 	return mcp.NewToolResultText(output), nil
 }
 
-// KeytoolGenerate generates a new keypair
+// KeytoolGenerate generates a new keypair; the recovery phrase goes to the secret sink
 func (s *SuiService) KeytoolGenerate(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	keyScheme, ok := request.Params.Arguments["key-scheme"].(string)
+	if s.secrets == nil {
+		return secretsDisabled(request), nil
+	}
+
+	keyScheme, ok := request.GetArguments()["key-scheme"].(string)
 	if !ok {
 		return nil, errors.New("key-scheme must be a string")
 	}
 
-	derivationPath, _ := request.Params.Arguments["derivation-path"].(string)
-	wordLength, _ := request.Params.Arguments["word-length"].(string)
+	derivationPath, _ := request.GetArguments()["derivation-path"].(string)
+	wordLength, _ := request.GetArguments()["word-length"].(string)
 
-	output, err := s.client.KeytoolGenerate(keyScheme, derivationPath, wordLength)
+	output, err := s.client.KeytoolGenerate(ctx, keyScheme, derivationPath, wordLength)
 	if err != nil {
 		return nil, err
 	}
-	return mcp.NewToolResultText(output), nil
+	return s.storeSecret(ctx, request.Params.Name, "", output)
 }
 
-// KeytoolExport exports the private key for a given address
+// KeytoolExport exports the private key for a given address to the secret sink
 func (s *SuiService) KeytoolExport(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	address, ok := request.Params.Arguments["address"].(string)
+	if s.secrets == nil {
+		return secretsDisabled(request), nil
+	}
+
+	address, ok := request.GetArguments()["address"].(string)
 	if !ok {
 		return nil, errors.New("address must be a string")
 	}
 
-	output, err := s.client.KeytoolExport(address)
+	output, err := s.client.KeytoolExport(ctx, address)
 	if err != nil {
 		return nil, err
 	}
-	return mcp.NewToolResultText(output), nil
+	return s.storeSecret(ctx, request.Params.Name, address, output)
+}
+
+// storeSecret hands raw secret-bearing output to the sink and returns a redacted copy with the reference
+func (s *SuiService) storeSecret(ctx context.Context, kind, label, output string) (*mcp.CallToolResult, error) {
+	ref, err := s.secrets.Store(ctx, secrets.Secret{Kind: kind, Label: label, Value: output})
+	if err != nil {
+		return nil, fmt.Errorf("failed to store secret output, nothing was returned: %w", err)
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("%s\n\nSecret material was removed from this output. The full output is stored at: %s", strings.TrimSpace(secrets.Redact(output)), ref)), nil
+}
+
+// secretsDisabled refuses a tool whose output would contain secrets
+func secretsDisabled(request mcp.CallToolRequest) *mcp.CallToolResult {
+	return mcp.NewToolResultError(fmt.Sprintf("%s is disabled because its output contains secrets; set secrets.enabled to write them to a secret sink instead", request.Params.Name))
+}
+
+// optionalStrings reads an optional array-of-strings argument; a missing argument yields nil
+func optionalStrings(request mcp.CallToolRequest, name string) ([]string, error) {
+	value, ok := request.GetArguments()[name]
+	if !ok || value == nil {
+		return nil, nil
+	}
+	items, ok := value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s must be an array", name)
+	}
+	values := make([]string, len(items))
+	for i, v := range items {
+		str, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s must contain strings", name)
+		}
+		values[i] = str
+	}
+	return values, nil
+}
+
+// txOptions reads the flags shared by every signing tool
+func txOptions(request mcp.CallToolRequest) sui.TxOptions {
+	gasBudget, _ := request.GetArguments()["gas-budget"].(string)
+	dryRun, _ := request.GetArguments()["dry-run"].(bool)
+	return sui.TxOptions{GasBudget: gasBudget, DryRun: dryRun}
 }
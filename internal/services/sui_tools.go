@@ -1,8 +1,7 @@
 package services
 
 import (
-
-
+	"github.com/krli/go-sui-mcp/internal/sui"
 	"github.com/mark3labs/mcp-go/mcp"
 	// "github.com/mark3labs/mcp-go/server"
 )
@@ -34,7 +33,9 @@ func (s *SuiTools) GetBalanceSummary() mcp.Tool {
 		mcp.WithString("address",
 			mcp.Description("Address to get the balance summary of, if not provided, the current address will be used"),
 		),
+		envArgument(),
 		mcp.WithDescription("Get the balance summary of the Sui client"),
+		mcp.WithOutputSchema[BalanceSummary](),
 	)
 }
 
@@ -44,7 +45,9 @@ func (s *SuiTools) GetObjectsSummary() mcp.Tool {
 		mcp.WithString("address",
 			mcp.Description("Address to get the objects summary of, if not provided, the current address will be used"),
 		),
+		envArgument(),
 		mcp.WithDescription("Get the objects summary of the Sui client"),
+		mcp.WithOutputSchema[ObjectsSummary](),
 	)
 }
 
@@ -55,7 +58,23 @@ func (s *SuiTools) GetObject() mcp.Tool {
 			mcp.Required(),
 			mcp.Description("Object ID to get"),
 		),
+		envArgument(),
 		mcp.WithDescription("Get the object of the Sui client"),
+		mcp.WithOutputSchema[sui.ObjectData](),
+	)
+}
+
+func (s *SuiTools) ResolveName() mcp.Tool {
+	return mcp.NewTool(
+		"sui-resolve-name",
+		mcp.WithString("name",
+			mcp.Description("SuiNS name to resolve to an address, e.g. alice.sui"),
+		),
+		mcp.WithString("address",
+			mcp.Description("Address to look up the SuiNS names of"),
+		),
+		envArgument(),
+		mcp.WithDescription("Resolve a SuiNS name to its address, or list the names pointing at an address. Give exactly one of name or address"),
 	)
 }
 
@@ -66,7 +85,92 @@ func (s *SuiTools) ProcessTransaction() mcp.Tool {
 			mcp.Required(),
 			mcp.Description("Transaction ID to process"),
 		),
+		envArgument(),
 		mcp.WithDescription("Process a transaction"),
+		mcp.WithOutputSchema[TransactionSummary](),
+	)
+}
+
+func (s *SuiTools) QueryTransactions() mcp.Tool {
+	return mcp.NewTool(
+		"sui-query-transactions",
+		mcp.WithString("from-address",
+			mcp.Description("List transactions sent by this address or SuiNS name"),
+		),
+		mcp.WithString("to-address",
+			mcp.Description("List transactions that sent objects or coins to this address or SuiNS name; may be combined with from-address"),
+		),
+		mcp.WithString("object",
+			mcp.Description("List transactions that created, mutated, wrapped or deleted this object"),
+		),
+		mcp.WithString("package",
+			mcp.Description("List transactions calling this package, optionally narrowed by module and function"),
+		),
+		mcp.WithString("module",
+			mcp.Description("Module of package to narrow the calls to"),
+		),
+		mcp.WithString("function",
+			mcp.Description("Function of module to narrow the calls to"),
+		),
+		mcp.WithString("since",
+			mcp.Description("Only list transactions at or after this time: RFC 3339 (2025-01-31T12:00:00Z), a date (2025-01-31, UTC) or Unix milliseconds"),
+		),
+		mcp.WithString("until",
+			mcp.Description("Only list transactions before this time, in the same forms as since"),
+		),
+		mcp.WithString("cursor",
+			mcp.Description("nextCursor of the previous page, to continue a listing"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description("Maximum number of transactions to return, 1 to 50 (default 20)"),
+		),
+		mcp.WithString("order",
+			mcp.Description("descending (newest first, the default) or ascending"),
+			mcp.Enum("descending", "ascending"),
+		),
+		envArgument(),
+		mcp.WithDescription("List transactions by sender, recipient, touched object or called package/module/function, with a compact summary of each. Give exactly one of from-address/to-address, object or package. Use sui-process-transaction for the full details of one transaction"),
+		mcp.WithOutputSchema[TransactionList](),
+	)
+}
+
+func (s *SuiTools) QueryEvents() mcp.Tool {
+	return mcp.NewTool(
+		"sui-query-events",
+		mcp.WithString("from-address",
+			mcp.Description("List events emitted by transactions sent from this address or SuiNS name"),
+		),
+		mcp.WithString("tx-digest",
+			mcp.Description("List the events emitted by this transaction"),
+		),
+		mcp.WithString("event-type",
+			mcp.Description("List events of this Move type, e.g. 0x2::coin::CoinMetadata<0x2::sui::SUI>"),
+		),
+		mcp.WithString("package",
+			mcp.Description("List events whose type is defined in this package; needs module"),
+		),
+		mcp.WithString("module",
+			mcp.Description("Module of package defining the event types"),
+		),
+		mcp.WithString("since",
+			mcp.Description("Only list events at or after this time: RFC 3339 (2025-01-31T12:00:00Z), a date (2025-01-31, UTC) or Unix milliseconds"),
+		),
+		mcp.WithString("until",
+			mcp.Description("Only list events before this time, in the same forms as since"),
+		),
+		mcp.WithString("cursor",
+			mcp.Description("nextCursor of the previous page, to continue a listing"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description("Maximum number of events to return, 1 to 50 (default 20)"),
+		),
+		mcp.WithString("order",
+			mcp.Description("descending (newest first, the default) or ascending"),
+			mcp.Enum("descending", "ascending"),
+		),
+		envArgument(),
+		mcp.WithDescription("List Move events with their decoded fields, by sender, transaction, event type or defining package/module. Give at most one of from-address, tx-digest, event-type or package/module; since/until may be combined with any of them or used alone"),
+		mcp.WithOutputSchema[EventList](),
 	)
 }
 
@@ -90,19 +194,24 @@ func (s *SuiTools) PaySUI() mcp.Tool {
 		),
 		mcp.WithArray("amounts",
 			mcp.Required(),
-			mcp.Description("Array of amounts to transfer (in MIST), must match length of recipients"),
-			mcp.Items(map[string]interface{}{"type": "number"}),
+			mcp.Description(`Array of amounts to transfer, such as "1.5 SUI" or "1500000000" (an integer is in MIST); must match length of recipients`),
+			mcp.Items(map[string]interface{}{"type": "string"}),
 		),
 		mcp.WithArray("input-coins",
-			mcp.Required(),
-			mcp.Description("Array of input SUI coin object IDs, need first to get the object of the SUI coin"),
+			mcp.Description("Array of input SUI coin object IDs; the first one also pays for gas. If omitted, coins covering the amounts plus gas are selected automatically"),
 			mcp.Items(map[string]interface{}{"type": "string"}),
 		),
 		mcp.WithString("gas-budget",
 			mcp.Description("Gas budget"),
 		),
 
-		mcp.WithDescription("Pay SUI to multiple recipients, 首先, 第一步先检查整体余额是否足够, 然后调用objects summary 获取所有coin object, 然后调用object 找到余额足够的coin object, 然后调用pay sui 进行支付, 然后按照模板<"+template+">返回结果"),
+		mcp.WithBoolean("dry-run",
+			mcp.Description("Preview the predicted effects, balance changes and gas cost without submitting the transaction"),
+		),
+		envArgument(),
+		senderArgument(),
+		mcp.WithDescription("Pay SUI to multiple recipients. Input coins are selected, merged and split automatically unless given, and the selected coins are listed in the result. 按照模板<"+template+">返回结果"),
+		mcp.WithOutputSchema[TransactionSummary](),
 	)
 }
 
@@ -111,6 +220,7 @@ func (s *SuiTools) PaySUI() mcp.Tool {
 func (s *SuiTools) GetActiveAddress() mcp.Tool {
 	return mcp.NewTool(
 		"sui-active-address",
+		envArgument(),
 		mcp.WithDescription("Get the current active address"),
 	)
 }
@@ -118,6 +228,7 @@ func (s *SuiTools) GetActiveAddress() mcp.Tool {
 func (s *SuiTools) GetAddresses() mcp.Tool {
 	return mcp.NewTool(
 		"sui-addresses",
+		envArgument(),
 		mcp.WithDescription("List all addresses managed by the client"),
 	)
 }
@@ -125,6 +236,7 @@ func (s *SuiTools) GetAddresses() mcp.Tool {
 func (s *SuiTools) GetActiveEnv() mcp.Tool {
 	return mcp.NewTool(
 		"sui-active-env",
+		envArgument(),
 		mcp.WithDescription("Get the current active environment (e.g., devnet, testnet, mainnet)"),
 	)
 }
@@ -132,6 +244,7 @@ func (s *SuiTools) GetActiveEnv() mcp.Tool {
 func (s *SuiTools) GetEnvs() mcp.Tool {
 	return mcp.NewTool(
 		"sui-envs",
+		envArgument(),
 		mcp.WithDescription("List all Sui network environments configured"),
 	)
 }
@@ -139,10 +252,33 @@ func (s *SuiTools) GetEnvs() mcp.Tool {
 func (s *SuiTools) GetChainIdentifier() mcp.Tool {
 	return mcp.NewTool(
 		"sui-chain-identifier",
+		envArgument(),
 		mcp.WithDescription("Query the chain identifier from the RPC endpoint"),
 	)
 }
 
+func (s *SuiTools) SwitchAddress() mcp.Tool {
+	return mcp.NewTool(
+		"sui-switch-address",
+		mcp.WithString("address",
+			mcp.Required(),
+			mcp.Description("Address or alias from the keystore to make active"),
+		),
+		mcp.WithDescription("Change the active address of the sui client config. This changes the default signer for every session; prefer the sender argument of a signing tool for a single call"),
+	)
+}
+
+func (s *SuiTools) SwitchEnv() mcp.Tool {
+	return mcp.NewTool(
+		"sui-switch-env",
+		mcp.WithString("env",
+			mcp.Required(),
+			mcp.Description("Environment alias to make active (e.g., devnet, testnet, mainnet)"),
+		),
+		mcp.WithDescription("Change the active environment of the sui client config. This changes the default network for every session; prefer the env argument of a tool for a single call"),
+	)
+}
+
 // ============ Gas Management ============
 
 func (s *SuiTools) GetGas() mcp.Tool {
@@ -151,7 +287,9 @@ func (s *SuiTools) GetGas() mcp.Tool {
 		mcp.WithString("address",
 			mcp.Description("Address to get gas objects for, if not provided, the current address will be used"),
 		),
+		envArgument(),
 		mcp.WithDescription("Get all gas objects owned by the address"),
+		mcp.WithOutputSchema[GasSummary](),
 	)
 }
 
@@ -161,6 +299,7 @@ func (s *SuiTools) RequestFromFaucet() mcp.Tool {
 		mcp.WithString("address",
 			mcp.Description("Address to request gas coins for, if not provided, the current address will be used"),
 		),
+		envArgument(),
 		mcp.WithDescription("Request gas coins from the faucet (works on devnet/testnet only)"),
 	)
 }
@@ -181,7 +320,13 @@ func (s *SuiTools) Transfer() mcp.Tool {
 		mcp.WithString("gas-budget",
 			mcp.Description("Gas budget for the transaction"),
 		),
+		mcp.WithBoolean("dry-run",
+			mcp.Description("Preview the predicted effects, balance changes and gas cost without submitting the transaction"),
+		),
+		envArgument(),
+		senderArgument(),
 		mcp.WithDescription("Transfer an object to another address"),
+		mcp.WithOutputSchema[TransactionSummary](),
 	)
 }
 
@@ -193,16 +338,21 @@ func (s *SuiTools) TransferSUI() mcp.Tool {
 			mcp.Description("Recipient address"),
 		),
 		mcp.WithString("sui-coin-object-id",
-			mcp.Required(),
-			mcp.Description("SUI coin object ID to transfer from"),
+			mcp.Description("SUI coin object ID to transfer from, which also pays for gas. If omitted, amount is required and coins are selected automatically"),
 		),
-		mcp.WithNumber("amount",
-			mcp.Description("Amount to transfer (in MIST). If not specified, transfers entire object"),
+		mcp.WithString("amount",
+			mcp.Description(`Amount to transfer, such as "1.5 SUI" or "1500000000" (an integer is in MIST). If not specified, transfers entire object`),
 		),
 		mcp.WithString("gas-budget",
 			mcp.Description("Gas budget for the transaction"),
 		),
+		mcp.WithBoolean("dry-run",
+			mcp.Description("Preview the predicted effects, balance changes and gas cost without submitting the transaction"),
+		),
+		envArgument(),
+		senderArgument(),
 		mcp.WithDescription("Transfer SUI to another address (simplified version)"),
+		mcp.WithOutputSchema[TransactionSummary](),
 	)
 }
 
@@ -215,13 +365,19 @@ func (s *SuiTools) SplitCoin() mcp.Tool {
 		),
 		mcp.WithArray("amounts",
 			mcp.Required(),
-			mcp.Description("Array of amounts to split into (in MIST)"),
-			mcp.Items(map[string]interface{}{"type": "number"}),
+			mcp.Description(`Array of amounts to split into, such as "0.5 SUI" or "250 USDC" in the coin's symbol, or integers in its smallest unit`),
+			mcp.Items(map[string]interface{}{"type": "string"}),
 		),
 		mcp.WithString("gas-budget",
 			mcp.Description("Gas budget for the transaction"),
 		),
+		mcp.WithBoolean("dry-run",
+			mcp.Description("Preview the predicted effects, balance changes and gas cost without submitting the transaction"),
+		),
+		envArgument(),
+		senderArgument(),
 		mcp.WithDescription("Split a coin object into multiple coins"),
+		mcp.WithOutputSchema[TransactionSummary](),
 	)
 }
 
@@ -239,7 +395,13 @@ func (s *SuiTools) MergeCoin() mcp.Tool {
 		mcp.WithString("gas-budget",
 			mcp.Description("Gas budget for the transaction"),
 		),
+		mcp.WithBoolean("dry-run",
+			mcp.Description("Preview the predicted effects, balance changes and gas cost without submitting the transaction"),
+		),
+		envArgument(),
+		senderArgument(),
 		mcp.WithDescription("Merge two coin objects into one"),
+		mcp.WithOutputSchema[TransactionSummary](),
 	)
 }
 
@@ -247,10 +409,12 @@ func (s *SuiTools) Pay() mcp.Tool {
 	return mcp.NewTool(
 		"sui-pay",
 		mcp.WithArray("input-coins",
-			mcp.Required(),
-			mcp.Description("Array of coin object IDs to use as input"),
+			mcp.Description("Array of coin object IDs to use as input. If omitted, coins of coin-type covering the amounts are selected automatically"),
 			mcp.Items(map[string]interface{}{"type": "string"}),
 		),
+		mcp.WithString("coin-type",
+			mcp.Description("Coin type to pay with (e.g. 0x2::sui::SUI), required when input-coins is omitted"),
+		),
 		mcp.WithArray("recipients",
 			mcp.Required(),
 			mcp.Description("Array of recipient addresses"),
@@ -258,13 +422,19 @@ func (s *SuiTools) Pay() mcp.Tool {
 		),
 		mcp.WithArray("amounts",
 			mcp.Required(),
-			mcp.Description("Array of amounts to pay (in MIST)"),
-			mcp.Items(map[string]interface{}{"type": "number"}),
+			mcp.Description(`Array of amounts to pay, such as "250 USDC" in the coin's symbol, or integers in its smallest unit`),
+			mcp.Items(map[string]interface{}{"type": "string"}),
 		),
 		mcp.WithString("gas-budget",
 			mcp.Description("Gas budget for the transaction"),
 		),
+		mcp.WithBoolean("dry-run",
+			mcp.Description("Preview the predicted effects, balance changes and gas cost without submitting the transaction"),
+		),
+		envArgument(),
+		senderArgument(),
 		mcp.WithDescription("Pay coins to multiple recipients with specified amounts"),
+		mcp.WithOutputSchema[TransactionSummary](),
 	)
 }
 
@@ -283,7 +453,13 @@ func (s *SuiTools) PayAllSUI() mcp.Tool {
 		mcp.WithString("gas-budget",
 			mcp.Description("Gas budget for the transaction"),
 		),
+		mcp.WithBoolean("dry-run",
+			mcp.Description("Preview the predicted effects, balance changes and gas cost without submitting the transaction"),
+		),
+		envArgument(),
+		senderArgument(),
 		mcp.WithDescription("Pay all residual SUI to the recipient after deducting gas cost"),
+		mcp.WithOutputSchema[TransactionSummary](),
 	)
 }
 
@@ -315,7 +491,13 @@ func (s *SuiTools) Call() mcp.Tool {
 		mcp.WithString("gas-budget",
 			mcp.Description("Gas budget for the transaction"),
 		),
+		mcp.WithBoolean("dry-run",
+			mcp.Description("Preview the predicted effects, balance changes and gas cost without submitting the transaction"),
+		),
+		envArgument(),
+		senderArgument(),
 		mcp.WithDescription("Call a Move function on the Sui blockchain"),
+		mcp.WithOutputSchema[TransactionSummary](),
 	)
 }
 
@@ -332,7 +514,13 @@ func (s *SuiTools) Publish() mcp.Tool {
 		mcp.WithBoolean("skip-dependency-verification",
 			mcp.Description("Skip dependency verification"),
 		),
+		mcp.WithBoolean("dry-run",
+			mcp.Description("Preview the predicted effects, balance changes and gas cost without submitting the transaction"),
+		),
+		envArgument(),
+		senderArgument(),
 		mcp.WithDescription("Publish Move modules to the Sui blockchain"),
+		mcp.WithOutputSchema[TransactionSummary](),
 	)
 }
 
@@ -346,10 +534,27 @@ func (s *SuiTools) GetDynamicField() mcp.Tool {
 		mcp.WithString("name",
 			mcp.Description("Name of the dynamic field to query"),
 		),
+		envArgument(),
 		mcp.WithDescription("Query a dynamic field by its parent object address"),
 	)
 }
 
+func (s *SuiTools) GetMoveModule() mcp.Tool {
+	return mcp.NewTool(
+		"sui-move-module",
+		mcp.WithString("package-id",
+			mcp.Required(),
+			mcp.Description("ID of the published package"),
+		),
+		mcp.WithString("module",
+			mcp.Required(),
+			mcp.Description("Name of the module in the package"),
+		),
+		envArgument(),
+		mcp.WithDescription("Show a published Move module in normalized form: its structs with fields and abilities, and its function signatures. Needs a fullnode"),
+	)
+}
+
 // ============ Move Development ============
 
 func (s *SuiTools) MoveBuild() mcp.Tool {
@@ -411,7 +616,7 @@ func (s *SuiTools) KeytoolGenerate() mcp.Tool {
 		mcp.WithString("word-length",
 			mcp.Description("Word length for mnemonic: word12, word15, word18, word21, or word24"),
 		),
-		mcp.WithDescription("Generate a new keypair and add it to the keystore"),
+		mcp.WithDescription("Generate a new keypair and add it to the keystore. The recovery phrase is never returned: it is written to the configured secret sink and only a reference to it is shown. Requires secrets.enabled."),
 	)
 }
 
@@ -422,6 +627,114 @@ func (s *SuiTools) KeytoolExport() mcp.Tool {
 			mcp.Required(),
 			mcp.Description("Address or alias of the key to export"),
 		),
-		mcp.WithDescription("Export the private key for a given address (Bech32 encoded)"),
+		mcp.WithDescription("Export the private key for a given address (Bech32 encoded). The key is never returned: it is written to the configured secret sink and only a reference to it is shown. Requires secrets.enabled."),
+	)
+}
+
+// ============ Subscriptions ============
+
+func (s *SuiTools) Subscribe() mcp.Tool {
+	return mcp.NewTool(
+		"sui-subscribe",
+		mcp.WithString("kind",
+			mcp.Required(),
+			mcp.Description("What to watch: events (Move events matching a filter), address (balance changes of an address) or object (transactions changing an object)"),
+			mcp.Enum("events", "address", "object"),
+		),
+		mcp.WithString("from-address",
+			mcp.Description("events: watch events emitted by transactions sent from this address or SuiNS name"),
+		),
+		mcp.WithString("event-type",
+			mcp.Description("events: watch events of this Move type, e.g. 0x2::coin::CoinMetadata<0x2::sui::SUI>"),
+		),
+		mcp.WithString("package",
+			mcp.Description("events: watch events whose type is defined in this package; needs module"),
+		),
+		mcp.WithString("module",
+			mcp.Description("events: module of package defining the event types"),
+		),
+		mcp.WithString("address",
+			mcp.Description("address: address or SuiNS name whose balance changes to watch"),
+		),
+		mcp.WithString("object-id",
+			mcp.Description("object: object ID to watch for mutation, wrapping or deletion"),
+		),
+		envArgument(),
+		mcp.WithDescription("Watch the chain and get notified of changes after this call, as notifications/message from logger sui-subscriptions, until unsubscribed or the session ends"),
+		mcp.WithOutputSchema[SubscriptionsSummary](),
+	)
+}
+
+func (s *SuiTools) Unsubscribe() mcp.Tool {
+	return mcp.NewTool(
+		"sui-unsubscribe",
+		mcp.WithString("id",
+			mcp.Description("ID of the subscription to stop, as returned by sui-subscribe"),
+		),
+		mcp.WithBoolean("all",
+			mcp.Description("Stop every subscription of this session"),
+		),
+		mcp.WithDescription("Stop a subscription, or all of them"),
+		mcp.WithOutputSchema[SubscriptionsSummary](),
+	)
+}
+
+func (s *SuiTools) ListSubscriptions() mcp.Tool {
+	return mcp.NewTool(
+		"sui-subscriptions",
+		mcp.WithDescription("List the subscriptions of this session"),
+		mcp.WithOutputSchema[SubscriptionsSummary](),
+	)
+}
+
+// ============ Resources ============
+
+func (s *SuiTools) ObjectResource() mcp.ResourceTemplate {
+	return mcp.NewResourceTemplate(
+		ObjectResourceURI,
+		"Sui object",
+		mcp.WithTemplateDescription("An object with its type, owner, version and Move fields. Subscribe to be told when a transaction changes it."),
+		mcp.WithTemplateMIMEType("application/json"),
+	)
+}
+
+func (s *SuiTools) BalancesResource() mcp.ResourceTemplate {
+	return mcp.NewResourceTemplate(
+		BalancesResourceURI,
+		"Sui address balances",
+		mcp.WithTemplateDescription("The total balance of every coin type an address holds; addr may be a SuiNS name. Subscribe to be told when a transaction changes them."),
+		mcp.WithTemplateMIMEType("application/json"),
+	)
+}
+
+func (s *SuiTools) TransactionResource() mcp.ResourceTemplate {
+	return mcp.NewResourceTemplate(
+		TransactionResourceURI,
+		"Sui transaction",
+		mcp.WithTemplateDescription("A transaction's status, gas cost, balance changes, object changes and events"),
+		mcp.WithTemplateMIMEType("application/json"),
+	)
+}
+
+func (s *SuiTools) ModuleResource() mcp.ResourceTemplate {
+	return mcp.NewResourceTemplate(
+		ModuleResourceURI,
+		"Move module",
+		mcp.WithTemplateDescription("A published Move module in normalized form: its structs with fields and abilities, and its function signatures"),
+		mcp.WithTemplateMIMEType("application/json"),
+	)
+}
+
+// envArgument is the optional env argument of every tool that talks to the network
+func envArgument() mcp.ToolOption {
+	return mcp.WithString("env",
+		mcp.Description("Environment alias from the sui client config (e.g. devnet, testnet, mainnet) to run against; defaults to the server's configured env, or the active env"),
+	)
+}
+
+// senderArgument is the optional sender argument of every signing tool
+func senderArgument() mcp.ToolOption {
+	return mcp.WithString("sender",
+		mcp.Description("Keystore address to sign with; defaults to the active address"),
 	)
 }
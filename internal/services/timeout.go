@@ -0,0 +1,34 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/krli/go-sui-mcp/internal/config"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// TimeoutMiddleware bounds every tool call by its configured timeout. The
+// request context already carries MCP cancellation, so a cancelled or timed
+// out call kills the underlying sui process.
+func TimeoutMiddleware(timeouts config.TimeoutConfig) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			timeout := timeouts.For(request.Params.Name)
+			if timeout <= 0 {
+				return next(ctx, request)
+			}
+
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			result, err := next(ctx, request)
+			if err != nil && errors.Is(err, context.DeadlineExceeded) {
+				return nil, fmt.Errorf("%s timed out after %s: %w", request.Params.Name, timeout, err)
+			}
+			return result, err
+		}
+	}
+}
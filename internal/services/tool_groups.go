@@ -0,0 +1,117 @@
+package services
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/krli/go-sui-mcp/internal/config"
+)
+
+// Tool groups used to select which capabilities a deployment exposes
+const (
+	GroupQuery     = "query"
+	GroupPayments  = "payments"
+	GroupContracts = "contracts"
+	GroupMoveDev   = "move-dev"
+	GroupKeytool   = "keytool"
+	GroupClient    = "client"
+	// GroupSubscriptions holds the live chain subscription tools
+	GroupSubscriptions = "subscriptions"
+)
+
+// toolGroups assigns every tool to exactly one group
+var toolGroups = map[string]string{
+	"sui-formatted-version":   GroupQuery,
+	"sui-path":                GroupQuery,
+	"sui-balance-summary":     GroupQuery,
+	"sui-objects-summary":     GroupQuery,
+	"sui-object":              GroupQuery,
+	"sui-resolve-name":        GroupQuery,
+	"sui-process-transaction": GroupQuery,
+	"sui-query-transactions":  GroupQuery,
+	"sui-query-events":        GroupQuery,
+	"sui-active-address":      GroupQuery,
+	"sui-addresses":           GroupQuery,
+	"sui-active-env":          GroupQuery,
+	"sui-envs":                GroupQuery,
+	"sui-chain-identifier":    GroupQuery,
+	"sui-gas":                 GroupQuery,
+	"sui-dynamic-field":       GroupQuery,
+	"sui-move-module":         GroupQuery,
+
+	"sui-faucet":       GroupPayments,
+	"sui-pay-sui":      GroupPayments,
+	"sui-pay":          GroupPayments,
+	"sui-pay-all-sui":  GroupPayments,
+	"sui-transfer":     GroupPayments,
+	"sui-transfer-sui": GroupPayments,
+	"sui-split-coin":   GroupPayments,
+	"sui-merge-coin":   GroupPayments,
+
+	"sui-call":    GroupContracts,
+	"sui-publish": GroupContracts,
+
+	"sui-move-build": GroupMoveDev,
+	"sui-move-test":  GroupMoveDev,
+	"sui-move-new":   GroupMoveDev,
+
+	"sui-keytool-list":     GroupKeytool,
+	"sui-keytool-generate": GroupKeytool,
+	"sui-keytool-export":   GroupKeytool,
+
+	"sui-switch-address": GroupClient,
+	"sui-switch-env":     GroupClient,
+
+	"sui-subscribe":     GroupSubscriptions,
+	"sui-unsubscribe":   GroupSubscriptions,
+	"sui-subscriptions": GroupSubscriptions,
+}
+
+// ToolGroups lists the group names in a stable order
+var ToolGroups = []string{GroupQuery, GroupPayments, GroupContracts, GroupMoveDev, GroupKeytool, GroupClient, GroupSubscriptions}
+
+// ToolGroup returns the group the named tool belongs to
+func ToolGroup(name string) string {
+	return toolGroups[name]
+}
+
+// NewToolFilter returns a predicate deciding which tools are registered.
+// A tool is exposed when it, or its group, is in Include (or Include is empty)
+// and neither is in Exclude; an entry in Enabled then forces it on or off.
+// Read-only mode always has the last word. Unknown group or tool names are an error.
+func NewToolFilter(cfg config.ToolsConfig, readOnly bool) (func(name string) bool, error) {
+	for _, entry := range append(slices.Clone(cfg.Include), cfg.Exclude...) {
+		if err := checkToolOrGroup(entry); err != nil {
+			return nil, err
+		}
+	}
+	for name := range cfg.Enabled {
+		if _, ok := toolGroups[name]; !ok {
+			return nil, fmt.Errorf("tools.enabled: unknown tool %q", name)
+		}
+	}
+
+	matches := func(list []string, name string) bool {
+		return slices.Contains(list, name) || slices.Contains(list, toolGroups[name])
+	}
+	return func(name string) bool {
+		if readOnly && !IsReadOnlyTool(name) {
+			return false
+		}
+		if enabled, ok := cfg.Enabled[name]; ok {
+			return enabled
+		}
+		if len(cfg.Include) > 0 && !matches(cfg.Include, name) {
+			return false
+		}
+		return !matches(cfg.Exclude, name)
+	}, nil
+}
+
+// checkToolOrGroup rejects names that are neither a tool nor a group
+func checkToolOrGroup(name string) error {
+	if _, ok := toolGroups[name]; ok || slices.Contains(ToolGroups, name) {
+		return nil
+	}
+	return fmt.Errorf("unknown tool or group %q (groups are %v)", name, ToolGroups)
+}
@@ -0,0 +1,136 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/krli/go-sui-mcp/internal/config"
+)
+
+func TestToolFilter(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      config.ToolsConfig
+		readOnly bool
+		// exposed and hidden are tools that must and must not pass the filter
+		exposed, hidden []string
+	}{
+		{
+			name:    "empty config exposes everything",
+			exposed: []string{"sui-gas", "sui-transfer", "sui-keytool-export", "sui-subscribe"},
+		},
+		{
+			name:    "include a group",
+			cfg:     config.ToolsConfig{Include: []string{GroupQuery}},
+			exposed: []string{"sui-gas", "sui-object"},
+			hidden:  []string{"sui-transfer", "sui-move-build", "sui-subscribe"},
+		},
+		{
+			name:    "include a group and a single tool",
+			cfg:     config.ToolsConfig{Include: []string{GroupQuery, "sui-transfer"}},
+			exposed: []string{"sui-gas", "sui-transfer"},
+			hidden:  []string{"sui-pay-sui"},
+		},
+		{
+			name:    "exclude a group",
+			cfg:     config.ToolsConfig{Exclude: []string{GroupKeytool}},
+			exposed: []string{"sui-gas", "sui-transfer"},
+			hidden:  []string{"sui-keytool-list", "sui-keytool-export"},
+		},
+		{
+			name:    "exclude wins over include",
+			cfg:     config.ToolsConfig{Include: []string{GroupPayments}, Exclude: []string{"sui-faucet"}},
+			exposed: []string{"sui-pay-sui"},
+			hidden:  []string{"sui-faucet", "sui-gas"},
+		},
+		{
+			name:    "enabled turns on an excluded tool",
+			cfg:     config.ToolsConfig{Exclude: []string{GroupKeytool}, Enabled: map[string]bool{"sui-keytool-list": true}},
+			exposed: []string{"sui-keytool-list"},
+			hidden:  []string{"sui-keytool-export"},
+		},
+		{
+			name:    "enabled turns on a tool outside include",
+			cfg:     config.ToolsConfig{Include: []string{GroupQuery}, Enabled: map[string]bool{"sui-move-test": true}},
+			exposed: []string{"sui-gas", "sui-move-test"},
+			hidden:  []string{"sui-move-build"},
+		},
+		{
+			name:    "enabled turns off an included tool",
+			cfg:     config.ToolsConfig{Enabled: map[string]bool{"sui-keytool-export": false}},
+			exposed: []string{"sui-keytool-list"},
+			hidden:  []string{"sui-keytool-export"},
+		},
+		{
+			name:     "read-only hides signing tools",
+			readOnly: true,
+			exposed:  []string{"sui-gas", "sui-move-build"},
+			hidden:   []string{"sui-transfer", "sui-switch-env", "sui-move-new", "sui-keytool-list"},
+		},
+		{
+			name:     "read-only beats include",
+			cfg:      config.ToolsConfig{Include: []string{GroupPayments, GroupQuery}},
+			readOnly: true,
+			exposed:  []string{"sui-gas"},
+			hidden:   []string{"sui-pay-sui", "sui-faucet"},
+		},
+		{
+			name:     "read-only beats enabled",
+			cfg:      config.ToolsConfig{Enabled: map[string]bool{"sui-transfer": true, "sui-keytool-export": true}},
+			readOnly: true,
+			hidden:   []string{"sui-transfer", "sui-keytool-export"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			allow, err := NewToolFilter(tc.cfg, tc.readOnly)
+			if err != nil {
+				t.Fatalf("NewToolFilter: %v", err)
+			}
+			for _, name := range tc.exposed {
+				if !allow(name) {
+					t.Errorf("%s is hidden, want it exposed", name)
+				}
+			}
+			for _, name := range tc.hidden {
+				if allow(name) {
+					t.Errorf("%s is exposed, want it hidden", name)
+				}
+			}
+		})
+	}
+}
+
+func TestToolFilterRejectsUnknownNames(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.ToolsConfig
+		wantErr string
+	}{
+		{name: "unknown group in include", cfg: config.ToolsConfig{Include: []string{"payment"}}, wantErr: `unknown tool or group "payment"`},
+		{name: "unknown tool in exclude", cfg: config.ToolsConfig{Exclude: []string{"sui-trasnfer"}}, wantErr: `unknown tool or group "sui-trasnfer"`},
+		{name: "unknown tool in enabled", cfg: config.ToolsConfig{Enabled: map[string]bool{"sui-export": false}}, wantErr: `tools.enabled: unknown tool "sui-export"`},
+		{name: "group in enabled", cfg: config.ToolsConfig{Enabled: map[string]bool{GroupKeytool: false}}, wantErr: `tools.enabled: unknown tool "keytool"`},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := NewToolFilter(tc.cfg, false); err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("NewToolFilter error = %v, want %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestEveryToolHasAKnownGroup(t *testing.T) {
+	for name, group := range toolGroups {
+		if err := checkToolOrGroup(group); err != nil {
+			t.Errorf("%s is in %v", name, err)
+		}
+	}
+	for name := range readOnlyTools {
+		if _, ok := toolGroups[name]; !ok {
+			t.Errorf("read-only tool %s has no group", name)
+		}
+	}
+}
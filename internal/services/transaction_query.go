@@ -0,0 +1,88 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/krli/go-sui-mcp/internal/sui"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// transactionFilter builds the single fullnode filter a sui-query-transactions call asks for
+func transactionFilter(request mcp.CallToolRequest) (map[string]any, error) {
+	args := request.GetArguments()
+	from, _ := args["from-address"].(string)
+	to, _ := args["to-address"].(string)
+	object, _ := args["object"].(string)
+	pkg, _ := args["package"].(string)
+	module, _ := args["module"].(string)
+	function, _ := args["function"].(string)
+
+	given := 0
+	for _, v := range []string{from + to, object, pkg} {
+		if v != "" {
+			given++
+		}
+	}
+	if given != 1 {
+		return nil, errors.New("give exactly one of from-address/to-address (both may be combined), object or package")
+	}
+	if pkg == "" && (module != "" || function != "") {
+		return nil, errors.New("module and function need package")
+	}
+	if module == "" && function != "" {
+		return nil, errors.New("function needs module")
+	}
+	for field, name := range map[string]string{"module": module, "function": function} {
+		if name != "" && !moveIdentifierPattern.MatchString(name) {
+			return nil, fmt.Errorf("%s %q is not a Move identifier", field, name)
+		}
+	}
+
+	switch {
+	case from != "" && to != "":
+		return map[string]any{"FromAndToAddress": map[string]string{"from": from, "to": to}}, nil
+	case from != "":
+		return map[string]any{"FromAddress": from}, nil
+	case to != "":
+		return map[string]any{"ToAddress": to}, nil
+	case object != "":
+		return map[string]any{"ChangedObject": object}, nil
+	}
+	call := map[string]any{"package": pkg}
+	if module != "" {
+		call["module"] = module
+	}
+	if function != "" {
+		call["function"] = function
+	}
+	return map[string]any{"MoveFunction": call}, nil
+}
+
+// queryTransactions fetches up to limit transactions matching filter within the time range
+func (s *SuiService) queryTransactions(ctx context.Context, filter map[string]any, within timeRange, cursor string, limit int, descending bool) (TransactionList, error) {
+	fetch := func(cursor string, size int) (queryPage[sui.TransactionBlockResponse, string], error) {
+		page, err := s.client.QueryTransactions(ctx, filter, cursor, size, descending)
+		if err != nil {
+			return queryPage[sui.TransactionBlockResponse, string]{}, err
+		}
+		result := queryPage[sui.TransactionBlockResponse, string]{Items: page.Data, HasNext: page.HasNextPage && page.NextCursor != nil}
+		if result.HasNext {
+			result.Next = *page.NextCursor
+		}
+		return result, nil
+	}
+	cursorOf := func(tx sui.TransactionBlockResponse) string { return tx.Digest }
+	timestampOf := func(tx sui.TransactionBlockResponse) (int64, bool) { return parseTimestampMs(tx.TimestampMs) }
+
+	page, err := collectInRange(fetch, cursorOf, timestampOf, within, cursor, limit, descending)
+	if err != nil {
+		return TransactionList{}, err
+	}
+	list := TransactionList{Filter: filter, Transactions: []TransactionListEntry{}, NextCursor: page.Next, HasNextPage: page.HasNext}
+	for _, tx := range page.Items {
+		list.Transactions = append(list.Transactions, summarizeListedTransaction(tx))
+	}
+	return list, nil
+}
@@ -0,0 +1,166 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"regexp"
+	"strings"
+
+	"github.com/krli/go-sui-mcp/internal/sui"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// argKind is the format an identifier argument must have
+type argKind int
+
+const (
+	// argAddress is a 32-byte hex address
+	argAddress argKind = iota
+	// argAddressOrAlias is an address or the alias of a keystore key
+	argAddressOrAlias
+	// argObjectID is a 32-byte hex object ID
+	argObjectID
+	// argDigest is a base58 transaction digest
+	argDigest
+)
+
+// identifierArguments lists, per tool, the arguments holding addresses, object
+// IDs or digests; array arguments are checked element by element
+var identifierArguments = map[string]map[string]argKind{
+	"sui-balance-summary":     {"address": argAddressOrAlias},
+	"sui-objects-summary":     {"address": argAddressOrAlias},
+	"sui-object":              {"objectID": argObjectID},
+	"sui-resolve-name":        {"address": argAddress},
+	"sui-process-transaction": {"txID": argDigest},
+	"sui-query-events":        {"from-address": argAddress, "tx-digest": argDigest, "package": argObjectID},
+	"sui-subscribe":           {"from-address": argAddress, "address": argAddress, "object-id": argObjectID, "package": argObjectID},
+	"sui-query-transactions":  {"from-address": argAddress, "to-address": argAddress, "object": argObjectID, "package": argObjectID, "cursor": argDigest},
+	"sui-pay-sui":             {"recipients": argAddress, "input-coins": argObjectID},
+	"sui-switch-address":      {"address": argAddressOrAlias},
+	"sui-gas":                 {"address": argAddressOrAlias},
+	"sui-faucet":              {"address": argAddressOrAlias},
+	"sui-transfer":            {"to": argAddress, "object-id": argObjectID},
+	"sui-transfer-sui":        {"to": argAddress, "sui-coin-object-id": argObjectID},
+	"sui-split-coin":          {"coin-id": argObjectID},
+	"sui-merge-coin":          {"primary-coin": argObjectID, "coin-to-merge": argObjectID},
+	"sui-pay":                 {"input-coins": argObjectID, "recipients": argAddress},
+	"sui-pay-all-sui":         {"input-coins": argObjectID, "recipient": argAddress},
+	"sui-call":                {"package": argObjectID},
+	"sui-dynamic-field":       {"parent-object-id": argObjectID},
+	"sui-move-module":         {"package-id": argObjectID},
+	"sui-keytool-export":      {"address": argAddressOrAlias},
+}
+
+// commonIdentifierArguments apply to every tool that has them
+var commonIdentifierArguments = map[string]argKind{
+	"sender": argAddress,
+}
+
+// aliasPattern matches the key aliases the sui keystore generates and accepts
+var aliasPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]*$`)
+
+// ValidationMiddleware checks identifier arguments before a tool runs and
+// rewrites addresses and object IDs into their canonical 32-byte form. It also
+// refuses any string argument starting with "-", which the CLI would parse as
+// a flag. Refusals name the offending argument.
+func ValidationMiddleware() server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			args, err := validateArguments(request.Params.Name, request.GetArguments())
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("%s was not executed: %v", request.Params.Name, err)), nil
+			}
+			if args != nil {
+				request.Params.Arguments = args
+			}
+			return next(ctx, request)
+		}
+	}
+}
+
+// validateArguments returns a copy of args with identifiers normalized, or an
+// error naming the first invalid argument
+func validateArguments(tool string, args map[string]any) (map[string]any, error) {
+	if args == nil {
+		return nil, nil
+	}
+	kinds := maps.Clone(commonIdentifierArguments)
+	maps.Copy(kinds, identifierArguments[tool])
+
+	normalized := maps.Clone(args)
+	for name, value := range args {
+		kind, isIdentifier := kinds[name]
+		spec := argSpec{kind: kind, identifier: isIdentifier, name: acceptsName(tool, name)}
+		switch value := value.(type) {
+		case string:
+			v, err := validateValue(name, value, spec)
+			if err != nil {
+				return nil, err
+			}
+			normalized[name] = v
+		case []any:
+			items := make([]any, len(value))
+			for i, item := range value {
+				items[i] = item
+				str, ok := item.(string)
+				if !ok {
+					continue
+				}
+				v, err := validateValue(fmt.Sprintf("%s[%d]", name, i), str, spec)
+				if err != nil {
+					return nil, err
+				}
+				items[i] = v
+			}
+			normalized[name] = items
+		}
+	}
+	return normalized, nil
+}
+
+// argSpec describes what a single argument may hold
+type argSpec struct {
+	kind argKind
+	// identifier is set when kind applies; other arguments only get the flag check
+	identifier bool
+	// name is set when a SuiNS name is also accepted; NameResolutionMiddleware resolves it
+	name bool
+}
+
+// validateValue checks a single string argument named field
+func validateValue(field, value string, spec argSpec) (string, error) {
+	if strings.HasPrefix(strings.TrimSpace(value), "-") {
+		return "", fmt.Errorf("invalid %s: %q starts with \"-\" and would be read as a command-line flag", field, value)
+	}
+	if !spec.identifier || value == "" || (spec.name && sui.IsName(value)) {
+		return value, nil
+	}
+
+	switch spec.kind {
+	case argAddressOrAlias:
+		if !strings.HasPrefix(value, "0x") && aliasPattern.MatchString(value) {
+			return value, nil
+		}
+		fallthrough
+	case argAddress, argObjectID:
+		normalized, err := sui.NormalizeAddress(value)
+		if err != nil {
+			expected := "a 0x-prefixed address of up to 64 hex digits"
+			if spec.kind == argObjectID {
+				expected = "a 0x-prefixed object ID of up to 64 hex digits"
+			}
+			if spec.name {
+				expected += " or a SuiNS name such as alice.sui"
+			}
+			return "", fmt.Errorf("invalid %s: %v; expected %s", field, err, expected)
+		}
+		return normalized, nil
+	case argDigest:
+		if err := sui.ValidateDigest(value); err != nil {
+			return "", fmt.Errorf("invalid %s: %v; expected a base58 transaction digest", field, err)
+		}
+	}
+	return value, nil
+}
@@ -0,0 +1,515 @@
+// Package subscriptions watches the chain on behalf of MCP client sessions and
+// notifies them of new events, balance changes and object mutations, either as
+// logging notifications or, for subscribed resources, as resource updates.
+//
+// Fullnodes no longer serve websocket subscriptions, so each subscription keeps
+// a cursor into the fullnode's event or transaction index and is polled from
+// there whenever the fullnode reports a new checkpoint.
+package subscriptions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/krli/go-sui-mcp/internal/config"
+	"github.com/krli/go-sui-mcp/internal/sui"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Kinds of subscription
+const (
+	// KindEvents watches for Move events matching a suix_queryEvents filter
+	KindEvents = "events"
+	// KindAddress watches for transactions that change an address's balances
+	KindAddress = "address"
+	// KindObject watches for transactions that mutate, wrap or delete an object
+	KindObject = "object"
+)
+
+const (
+	// pageLimit is the page size of every poll query
+	pageLimit = 50
+	// maxPollPages bounds the pages one subscription reads per poll; the rest
+	// is picked up by the next poll
+	maxPollPages = 10
+	// pollTimeout bounds the fullnode calls of one subscription per poll
+	pollTimeout = 30 * time.Second
+)
+
+// Logger names the MCP logger that subscription notifications come from
+const Logger = "sui-subscriptions"
+
+// ErrSessionGone is returned by a Notifier when the session no longer exists;
+// every subscription of that session is then dropped
+var ErrSessionGone = errors.New("session is gone")
+
+// Source is the chain access the poller needs; *sui.Client implements it
+type Source interface {
+	LatestCheckpoint(ctx context.Context) (uint64, error)
+	QueryEvents(ctx context.Context, filter map[string]any, cursor *sui.EventID, limit int, descending bool) (*sui.EventPage, error)
+	QueryTransactions(ctx context.Context, filter map[string]any, cursor string, limit int, descending bool) (*sui.TransactionPage, error)
+}
+
+// Notifier delivers a change to the session that subscribed
+type Notifier func(session string, change Change) error
+
+// SessionNotifier sends each change to its session as an MCP logging
+// notification at notice level. It is sent whatever logging level the client
+// set, since the session asked for it by subscribing. Changes of a subscribed
+// resource are sent as notifications/resources/updated instead, so the client
+// reads the resource again.
+func SessionNotifier(s *server.MCPServer) Notifier {
+	return func(session string, change Change) error {
+		method, params := string(mcp.MethodNotificationMessage), map[string]any{
+			"level":  mcp.LoggingLevelNotice,
+			"logger": Logger,
+			"data":   change,
+		}
+		if change.URI != "" {
+			method, params = mcp.MethodNotificationResourceUpdated, map[string]any{"uri": change.URI}
+		}
+		err := s.SendNotificationToSpecificClient(session, method, params)
+		if errors.Is(err, server.ErrSessionNotFound) {
+			return ErrSessionGone
+		}
+		return err
+	}
+}
+
+// Spec describes what a subscription watches
+type Spec struct {
+	Kind string `json:"kind"`
+	// EventFilter is the suix_queryEvents filter of an events subscription
+	EventFilter map[string]any `json:"eventFilter,omitempty"`
+	// Address is the watched address of an address subscription
+	Address string `json:"address,omitempty"`
+	// ObjectID is the watched object of an object subscription
+	ObjectID string `json:"objectId,omitempty"`
+	// URI is the resource a resources/subscribe request asked for, if any
+	URI string `json:"uri,omitempty"`
+}
+
+// Subscription is a Spec being watched for one session
+type Subscription struct {
+	ID string `json:"id"`
+	Spec
+	// Env is the network the subscription watches; empty for the default
+	Env     string    `json:"env,omitempty"`
+	Created time.Time `json:"created"`
+}
+
+// Change is what one poll of a subscription found
+type Change struct {
+	Subscription string `json:"subscription"`
+	Kind         string `json:"kind"`
+	URI          string `json:"uri,omitempty"`
+	// Checkpoint is the latest checkpoint when the change was seen
+	Checkpoint   uint64              `json:"checkpoint"`
+	Events       []sui.Event         `json:"events,omitempty"`
+	Transactions []TransactionChange `json:"transactions,omitempty"`
+}
+
+// TransactionChange is one transaction seen by an address or object subscription
+type TransactionChange struct {
+	Digest      string `json:"digest"`
+	Sender      string `json:"sender,omitempty"`
+	Status      string `json:"status,omitempty"`
+	TimestampMs string `json:"timestampMs,omitempty"`
+	// BalanceChanges are the watched address's own balance changes
+	BalanceChanges []sui.BalanceChange `json:"balanceChanges,omitempty"`
+}
+
+// txCursor is one transaction filter of a subscription and how far it has been read
+type txCursor struct {
+	filter map[string]any
+	cursor string
+}
+
+// watch is a subscription with its polling state; only the poller touches the state
+type watch struct {
+	sub     Subscription
+	session string
+	// ctx carries the env polls run against
+	ctx         context.Context
+	rpc         string
+	checkpoint  uint64
+	eventCursor *sui.EventID
+	txCursors   []*txCursor
+}
+
+// Manager holds the subscriptions of every session and polls them in the background
+type Manager struct {
+	source        Source
+	notify        Notifier
+	interval      time.Duration
+	maxPerSession int
+
+	mu      sync.Mutex
+	watches map[string]*watch
+	nextID  int
+	start   sync.Once
+	stop    sync.Once
+	done    chan struct{}
+}
+
+// New creates a manager; polling starts with the first subscription
+func New(source Source, notify Notifier, cfg config.SubscriptionsConfig) *Manager {
+	m := &Manager{
+		source:        source,
+		notify:        notify,
+		interval:      cfg.PollInterval,
+		maxPerSession: cfg.MaxPerSession,
+		watches:       make(map[string]*watch),
+		done:          make(chan struct{}),
+	}
+	if m.interval <= 0 {
+		m.interval = 5 * time.Second
+	}
+	if m.maxPerSession <= 0 {
+		m.maxPerSession = 20
+	}
+	return m
+}
+
+// Close stops polling
+func (m *Manager) Close() {
+	m.stop.Do(func() { close(m.done) })
+}
+
+// Subscribe starts watching spec for session, on the env selected in ctx.
+// Only changes after the call are reported.
+func (m *Manager) Subscribe(ctx context.Context, session string, spec Spec) (Subscription, error) {
+	w := &watch{session: session, ctx: context.Background()}
+	if env, ok := sui.EnvFromContext(ctx); ok {
+		w.ctx = sui.WithEnv(w.ctx, env)
+		w.rpc = env.RPC
+		w.sub.Env = env.Alias
+	}
+	switch spec.Kind {
+	case KindEvents:
+		if len(spec.EventFilter) == 0 {
+			return Subscription{}, errors.New("an events subscription needs an event filter")
+		}
+	case KindAddress:
+		if spec.Address == "" {
+			return Subscription{}, errors.New("an address subscription needs an address")
+		}
+		w.txCursors = []*txCursor{
+			{filter: map[string]any{"FromAddress": spec.Address}},
+			{filter: map[string]any{"ToAddress": spec.Address}},
+		}
+	case KindObject:
+		if spec.ObjectID == "" {
+			return Subscription{}, errors.New("an object subscription needs an object ID")
+		}
+		w.txCursors = []*txCursor{{filter: map[string]any{"ChangedObject": spec.ObjectID}}}
+	default:
+		return Subscription{}, fmt.Errorf("unknown subscription kind %q (expected %s, %s or %s)", spec.Kind, KindEvents, KindAddress, KindObject)
+	}
+
+	// Checked before seeking so a refused subscription costs no fullnode calls
+	m.mu.Lock()
+	existing, err := m.admit(session, spec)
+	m.mu.Unlock()
+	if existing != nil {
+		return existing.sub, nil
+	}
+	if err != nil {
+		return Subscription{}, err
+	}
+
+	if err := m.seek(ctx, w, spec); err != nil {
+		return Subscription{}, err
+	}
+
+	m.mu.Lock()
+	// The session may have subscribed again while seek ran
+	if existing, err := m.admit(session, spec); existing != nil || err != nil {
+		m.mu.Unlock()
+		if existing != nil {
+			return existing.sub, nil
+		}
+		return Subscription{}, err
+	}
+	m.nextID++
+	w.sub.ID = fmt.Sprintf("sub-%d", m.nextID)
+	w.sub.Spec = spec
+	w.sub.Created = time.Now().UTC()
+	m.watches[w.sub.ID] = w
+	m.mu.Unlock()
+
+	m.start.Do(func() { go m.run() })
+	return w.sub, nil
+}
+
+// admit checks whether session may add a subscription to spec. It returns the
+// session's subscription to the same resource, if there is one, since
+// subscribing to a resource twice is a no-op. m.mu must be held.
+func (m *Manager) admit(session string, spec Spec) (*watch, error) {
+	held := 0
+	for _, other := range m.watches {
+		if other.session != session {
+			continue
+		}
+		if spec.URI != "" && other.sub.URI == spec.URI {
+			return other, nil
+		}
+		held++
+	}
+	if held >= m.maxPerSession {
+		return nil, fmt.Errorf("this session already holds %d subscriptions, the most allowed", held)
+	}
+	return nil, nil
+}
+
+// seek positions a new watch's cursors at the newest matching event or
+// transaction, so that polling only reports what comes after
+func (m *Manager) seek(ctx context.Context, w *watch, spec Spec) error {
+	checkpoint, err := m.source.LatestCheckpoint(ctx)
+	if err != nil {
+		return err
+	}
+	w.checkpoint = checkpoint
+
+	if spec.Kind == KindEvents {
+		page, err := m.source.QueryEvents(ctx, spec.EventFilter, nil, 1, true)
+		if err != nil {
+			return err
+		}
+		if len(page.Data) > 0 {
+			w.eventCursor = &page.Data[0].ID
+		}
+		return nil
+	}
+	for _, c := range w.txCursors {
+		page, err := m.source.QueryTransactions(ctx, c.filter, "", 1, true)
+		if err != nil {
+			return err
+		}
+		if len(page.Data) > 0 {
+			c.cursor = page.Data[0].Digest
+		}
+	}
+	return nil
+}
+
+// Unsubscribe stops one of session's subscriptions
+func (m *Manager) Unsubscribe(session, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	w, ok := m.watches[id]
+	if !ok || w.session != session {
+		return fmt.Errorf("no subscription %s in this session", id)
+	}
+	delete(m.watches, id)
+	return nil
+}
+
+// UnsubscribeURI stops session's subscription to a resource; it is not an
+// error if there is none
+func (m *Manager) UnsubscribeURI(session, uri string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for id, w := range m.watches {
+		if w.session == session && w.sub.URI == uri {
+			delete(m.watches, id)
+		}
+	}
+}
+
+// CloseSession stops every subscription of session and returns how many there were
+func (m *Manager) CloseSession(session string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	n := 0
+	for id, w := range m.watches {
+		if w.session == session {
+			delete(m.watches, id)
+			n++
+		}
+	}
+	return n
+}
+
+// List returns session's subscriptions, oldest first
+func (m *Manager) List(session string) []Subscription {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	subs := []Subscription{}
+	for _, w := range m.watches {
+		if w.session == session {
+			subs = append(subs, w.sub)
+		}
+	}
+	slices.SortFunc(subs, func(a, b Subscription) int { return a.Created.Compare(b.Created) })
+	return subs
+}
+
+// active reports whether w is still subscribed
+func (m *Manager) active(w *watch) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.watches[w.sub.ID] == w
+}
+
+// run polls every subscription once per interval until Close
+func (m *Manager) run() {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-m.done:
+			return
+		case <-ticker.C:
+			m.pollAll()
+		}
+	}
+}
+
+// pollAll polls every subscription whose fullnode has moved on to a new checkpoint
+func (m *Manager) pollAll() {
+	m.mu.Lock()
+	watches := slices.Collect(maps.Values(m.watches))
+	m.mu.Unlock()
+
+	// One checkpoint lookup per fullnode serves every subscription on it
+	checkpoints := make(map[string]uint64)
+	for _, w := range watches {
+		checkpoint, ok := checkpoints[w.rpc]
+		if !ok {
+			ctx, cancel := context.WithTimeout(w.ctx, pollTimeout)
+			var err error
+			checkpoint, err = m.source.LatestCheckpoint(ctx)
+			cancel()
+			if err != nil {
+				log.Printf("subscriptions: %s: %v", w.sub.ID, err)
+				continue
+			}
+			checkpoints[w.rpc] = checkpoint
+		}
+		if checkpoint == w.checkpoint {
+			continue
+		}
+
+		change, err := m.poll(w, checkpoint)
+		if err != nil {
+			log.Printf("subscriptions: %s: %v", w.sub.ID, err)
+			continue
+		}
+		w.checkpoint = checkpoint
+		if (len(change.Events) == 0 && len(change.Transactions) == 0) || !m.active(w) {
+			continue
+		}
+		if err := m.notify(w.session, change); errors.Is(err, ErrSessionGone) {
+			m.CloseSession(w.session)
+		} else if err != nil {
+			log.Printf("subscriptions: %s: cannot notify session: %v", w.sub.ID, err)
+		}
+	}
+}
+
+// poll reads what a subscription's cursors have not seen yet and advances them.
+// On an error the cursors stay where they were, so the next poll reads the
+// same changes again rather than losing them.
+func (m *Manager) poll(w *watch, checkpoint uint64) (Change, error) {
+	ctx, cancel := context.WithTimeout(w.ctx, pollTimeout)
+	defer cancel()
+	change := Change{Subscription: w.sub.ID, Kind: w.sub.Kind, URI: w.sub.URI, Checkpoint: checkpoint}
+
+	if w.sub.Kind == KindEvents {
+		cursor := w.eventCursor
+		for range maxPollPages {
+			page, err := m.source.QueryEvents(ctx, w.sub.EventFilter, cursor, pageLimit, false)
+			if err != nil {
+				return Change{}, err
+			}
+			change.Events = append(change.Events, page.Data...)
+			if len(page.Data) > 0 {
+				cursor = &page.Data[len(page.Data)-1].ID
+			}
+			if !page.HasNextPage {
+				break
+			}
+		}
+		w.eventCursor = cursor
+		return change, nil
+	}
+
+	seen := make(map[string]bool)
+	cursors := make([]string, len(w.txCursors))
+	for i, c := range w.txCursors {
+		cursors[i] = c.cursor
+		for range maxPollPages {
+			page, err := m.source.QueryTransactions(ctx, c.filter, cursors[i], pageLimit, false)
+			if err != nil {
+				return Change{}, err
+			}
+			for _, tx := range page.Data {
+				cursors[i] = tx.Digest
+				if seen[tx.Digest] {
+					continue
+				}
+				seen[tx.Digest] = true
+				if tc, ok := w.transactionChange(tx); ok {
+					change.Transactions = append(change.Transactions, tc)
+				}
+			}
+			if !page.HasNextPage {
+				break
+			}
+		}
+	}
+	for i, c := range w.txCursors {
+		c.cursor = cursors[i]
+	}
+	slices.SortStableFunc(change.Transactions, func(a, b TransactionChange) int {
+		return compareTimestamps(a.TimestampMs, b.TimestampMs)
+	})
+	return change, nil
+}
+
+// transactionChange reduces a transaction to what the subscription reports;
+// address subscriptions skip transactions that leave the address's balances alone
+func (w *watch) transactionChange(tx sui.TransactionBlockResponse) (TransactionChange, bool) {
+	tc := TransactionChange{Digest: tx.Digest, TimestampMs: tx.TimestampMs}
+	if tx.Transaction != nil {
+		tc.Sender = tx.Transaction.Data.Sender
+	}
+	if tx.Effects != nil {
+		tc.Status = tx.Effects.Status.Status
+	}
+	if w.sub.Kind != KindAddress {
+		return tc, true
+	}
+	for _, bc := range tx.BalanceChanges {
+		if bc.Owner.Kind == "address" && bc.Owner.Address == w.sub.Address {
+			tc.BalanceChanges = append(tc.BalanceChanges, bc)
+		}
+	}
+	return tc, len(tc.BalanceChanges) > 0
+}
+
+// compareTimestamps orders decimal millisecond timestamps; missing ones sort last
+func compareTimestamps(a, b string) int {
+	switch {
+	case a == b:
+		return 0
+	case a == "":
+		return 1
+	case b == "":
+		return -1
+	case len(a) != len(b):
+		return len(a) - len(b)
+	case a < b:
+		return -1
+	default:
+		return 1
+	}
+}
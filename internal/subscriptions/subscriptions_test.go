@@ -0,0 +1,351 @@
+package subscriptions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/krli/go-sui-mcp/internal/config"
+	"github.com/krli/go-sui-mcp/internal/sui"
+)
+
+const watched = "0x00000000000000000000000000000000000000000000000000000000000a11ce"
+
+// fakeSource serves a growing chain of events and per-filter transactions,
+// oldest first, and fails the query call numbered failOn
+type fakeSource struct {
+	mu         sync.Mutex
+	checkpoint uint64
+	events     []sui.Event
+	// txs are keyed by the filter kind, e.g. FromAddress
+	txs    map[string][]sui.TransactionBlockResponse
+	calls  int
+	failOn int
+	// seeking, when set, runs on every LatestCheckpoint call
+	seeking func()
+	latest  int
+}
+
+func (s *fakeSource) LatestCheckpoint(ctx context.Context) (uint64, error) {
+	s.mu.Lock()
+	s.latest++
+	seeking := s.seeking
+	s.mu.Unlock()
+	if seeking != nil {
+		seeking()
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.checkpoint, nil
+}
+
+// query counts a query call and reports whether it is the one to fail
+func (s *fakeSource) query() error {
+	s.calls++
+	if s.calls == s.failOn {
+		return errors.New("fullnode unavailable")
+	}
+	return nil
+}
+
+// window returns the positions of a page of up to limit items after the one at
+// after (from the start when -1) and whether more follow
+func window(n, after, limit int, descending bool) ([]int, bool) {
+	var order []int
+	for i := range n {
+		order = append(order, i)
+	}
+	if descending {
+		slices.Reverse(order)
+	}
+	start := slices.Index(order, after) + 1
+	end := min(start+limit, n)
+	return order[start:end], end < n
+}
+
+func (s *fakeSource) QueryEvents(ctx context.Context, filter map[string]any, cursor *sui.EventID, limit int, descending bool) (*sui.EventPage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.query(); err != nil {
+		return nil, err
+	}
+	after := -1
+	if cursor != nil {
+		after = slices.IndexFunc(s.events, func(e sui.Event) bool { return e.ID == *cursor })
+	}
+	positions, more := window(len(s.events), after, limit, descending)
+	page := &sui.EventPage{Data: []sui.Event{}, HasNextPage: more}
+	for _, i := range positions {
+		page.Data = append(page.Data, s.events[i])
+	}
+	if len(page.Data) > 0 {
+		page.NextCursor = &page.Data[len(page.Data)-1].ID
+	}
+	return page, nil
+}
+
+func (s *fakeSource) QueryTransactions(ctx context.Context, filter map[string]any, cursor string, limit int, descending bool) (*sui.TransactionPage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.query(); err != nil {
+		return nil, err
+	}
+	var txs []sui.TransactionBlockResponse
+	for kind := range filter {
+		txs = s.txs[kind]
+	}
+	after := slices.IndexFunc(txs, func(tx sui.TransactionBlockResponse) bool { return tx.Digest == cursor })
+	positions, more := window(len(txs), after, limit, descending)
+	page := &sui.TransactionPage{Data: []sui.TransactionBlockResponse{}, HasNextPage: more}
+	for _, i := range positions {
+		page.Data = append(page.Data, txs[i])
+	}
+	if len(page.Data) > 0 {
+		page.NextCursor = &page.Data[len(page.Data)-1].Digest
+	}
+	return page, nil
+}
+
+// emit adds n events and moves the chain on to the next checkpoint
+func (s *fakeSource) emit(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for range n {
+		seq := len(s.events)
+		s.events = append(s.events, sui.Event{ID: sui.EventID{TxDigest: fmt.Sprintf("tx-%d", seq), EventSeq: "0"}, Type: "0x2::market::Listed"})
+	}
+	s.checkpoint++
+}
+
+// transact adds a transaction changing the watched address's balance to the
+// listing of a filter kind and moves the chain on to the next checkpoint
+func (s *fakeSource) transact(kind, digest string, timestampMs int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.txs == nil {
+		s.txs = make(map[string][]sui.TransactionBlockResponse)
+	}
+	s.txs[kind] = append(s.txs[kind], sui.TransactionBlockResponse{
+		Digest:         digest,
+		TimestampMs:    strconv.Itoa(timestampMs),
+		BalanceChanges: []sui.BalanceChange{{Owner: sui.Owner{Kind: "address", Address: watched}, CoinType: "0x2::sui::SUI", Amount: "-1"}},
+	})
+	s.checkpoint++
+}
+
+// failQuery makes the nth query call from now fail
+func (s *fakeSource) failQuery(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failOn = s.calls + n
+}
+
+// recorder collects the changes sent to sessions
+type recorder struct {
+	mu      sync.Mutex
+	changes []Change
+}
+
+func (r *recorder) notify(session string, change Change) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.changes = append(r.changes, change)
+	return nil
+}
+
+func (r *recorder) take() []Change {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	changes := r.changes
+	r.changes = nil
+	return changes
+}
+
+// newTestManager creates a manager whose background polling never fires, so
+// the test drives polls itself
+func newTestManager(t *testing.T, source Source, maxPerSession int) (*Manager, *recorder) {
+	rec := &recorder{}
+	m := New(source, rec.notify, config.SubscriptionsConfig{PollInterval: time.Hour, MaxPerSession: maxPerSession})
+	t.Cleanup(m.Close)
+	return m, rec
+}
+
+func eventDigests(changes []Change) []string {
+	var digests []string
+	for _, change := range changes {
+		for _, event := range change.Events {
+			digests = append(digests, event.ID.TxDigest)
+		}
+	}
+	return digests
+}
+
+func txDigests(changes []Change) []string {
+	var digests []string
+	for _, change := range changes {
+		for _, tx := range change.Transactions {
+			digests = append(digests, tx.Digest)
+		}
+	}
+	return digests
+}
+
+func TestPollKeepsEventCursorOnError(t *testing.T) {
+	source := &fakeSource{checkpoint: 1}
+	source.emit(3)
+	m, rec := newTestManager(t, source, 0)
+	if _, err := m.Subscribe(context.Background(), "s1", Spec{Kind: KindEvents, EventFilter: map[string]any{"Sender": watched}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Spans three pages; the second fails
+	source.emit(120)
+	source.failQuery(2)
+	m.pollAll()
+	if changes := rec.take(); len(changes) > 0 {
+		t.Fatalf("a failed poll notified %v", eventDigests(changes))
+	}
+
+	m.pollAll()
+	var want []string
+	for i := 3; i < 123; i++ {
+		want = append(want, fmt.Sprintf("tx-%d", i))
+	}
+	if got := eventDigests(rec.take()); !slices.Equal(got, want) {
+		t.Errorf("after the retry got %v, want events 3 to 122 once each", got)
+	}
+
+	source.emit(2)
+	m.pollAll()
+	if got := eventDigests(rec.take()); !slices.Equal(got, []string{"tx-123", "tx-124"}) {
+		t.Errorf("next poll got %v, want tx-123 and tx-124", got)
+	}
+}
+
+func TestPollKeepsTransactionCursorsOnError(t *testing.T) {
+	source := &fakeSource{checkpoint: 1}
+	source.transact("FromAddress", "sent-0", 1000)
+	m, rec := newTestManager(t, source, 0)
+	if _, err := m.Subscribe(context.Background(), "s1", Spec{Kind: KindAddress, Address: watched}); err != nil {
+		t.Fatal(err)
+	}
+
+	source.transact("FromAddress", "sent-1", 2000)
+	source.transact("ToAddress", "received-1", 1500)
+	// The sent listing is read, then the received one fails
+	source.failQuery(2)
+	m.pollAll()
+	if changes := rec.take(); len(changes) > 0 {
+		t.Fatalf("a failed poll notified %v", txDigests(changes))
+	}
+
+	m.pollAll()
+	if got := txDigests(rec.take()); !slices.Equal(got, []string{"received-1", "sent-1"}) {
+		t.Errorf("after the retry got %v, want received-1 and sent-1 in time order", got)
+	}
+
+	source.transact("ToAddress", "received-2", 3000)
+	m.pollAll()
+	if got := txDigests(rec.take()); !slices.Equal(got, []string{"received-2"}) {
+		t.Errorf("next poll got %v, want received-2 only", got)
+	}
+}
+
+func TestSubscribeRechecksAfterSeeking(t *testing.T) {
+	tests := []struct {
+		name          string
+		maxPerSession int
+		spec          Spec
+		// wantSubs is how many subscriptions the session must end up with
+		wantSubs int
+		// wantErrs is how many of the two calls must fail
+		wantErrs int
+	}{
+		{
+			name:          "limit",
+			maxPerSession: 1,
+			spec:          Spec{Kind: KindObject, ObjectID: "0x5"},
+			wantSubs:      1,
+			wantErrs:      1,
+		},
+		{
+			name:          "same resource",
+			maxPerSession: 5,
+			spec:          Spec{Kind: KindObject, ObjectID: "0x5", URI: "sui://object/0x5"},
+			wantSubs:      1,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Both calls pass the first check, then wait in seek until both got there
+			var arrived sync.WaitGroup
+			arrived.Add(2)
+			source := &fakeSource{checkpoint: 1, seeking: func() {
+				arrived.Done()
+				arrived.Wait()
+			}}
+			m, _ := newTestManager(t, source, tc.maxPerSession)
+
+			var wg sync.WaitGroup
+			subs := make([]Subscription, 2)
+			errs := make([]error, 2)
+			for i := range 2 {
+				wg.Go(func() {
+					subs[i], errs[i] = m.Subscribe(context.Background(), "s1", tc.spec)
+				})
+			}
+			wg.Wait()
+
+			failed := 0
+			for _, err := range errs {
+				if err != nil {
+					failed++
+				}
+			}
+			if failed != tc.wantErrs {
+				t.Errorf("%d calls failed (%v), want %d", failed, errs, tc.wantErrs)
+			}
+			if got := m.List("s1"); len(got) != tc.wantSubs {
+				t.Errorf("session holds %d subscriptions, want %d", len(got), tc.wantSubs)
+			}
+			if tc.wantErrs == 0 && subs[0].ID != subs[1].ID {
+				t.Errorf("subscribed twice to one resource as %s and %s", subs[0].ID, subs[1].ID)
+			}
+		})
+	}
+}
+
+func TestCloseStopsPolling(t *testing.T) {
+	source := &fakeSource{checkpoint: 1}
+	m := New(source, (&recorder{}).notify, config.SubscriptionsConfig{PollInterval: time.Millisecond})
+	if _, err := m.Subscribe(context.Background(), "s1", Spec{Kind: KindObject, ObjectID: "0x5"}); err != nil {
+		t.Fatal(err)
+	}
+
+	latest := func() int {
+		source.mu.Lock()
+		defer source.mu.Unlock()
+		return source.latest
+	}
+	for deadline := time.Now().Add(5 * time.Second); latest() < 3; {
+		if time.Now().After(deadline) {
+			t.Fatal("polling did not start")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	m.Close()
+	// A poll already under way may still finish
+	time.Sleep(10 * time.Millisecond)
+	before := latest()
+	time.Sleep(50 * time.Millisecond)
+	if after := latest(); after != before {
+		t.Errorf("polled %d more times after Close", after-before)
+	}
+}
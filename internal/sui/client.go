@@ -1,110 +1,368 @@
 package sui
 
 import (
-	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"os/exec"
+	"math/big"
+	"strconv"
 	"strings"
+	"sync"
 
-	"github.com/spf13/viper"
+	"github.com/krli/go-sui-mcp/internal/config"
 )
 
+// Backends accepted by the sui.backend setting
+const (
+	// BackendCLI shells out to the sui binary for every operation
+	BackendCLI = "cli"
+	// BackendRPC serves reads from a fullnode over JSON-RPC; writes still use the CLI
+	BackendRPC = "rpc"
+)
+
+// errAddressRequired is returned by RPC reads that the CLI would default to the active address
+var errAddressRequired = errors.New("address is required when sui.backend is rpc")
+
 // Client provides methods to interact with the Sui client CLI
 type Client struct {
 	executablePath string
+	executor       Executor
+	// rpc, when set, serves read queries instead of the CLI
+	rpc *RPCClient
+	// envs resolves the per-call env argument; nil when per-call envs are not available
+	envs *Environments
+
+	// envRPCs caches one RPC client per environment URL
+	mu      sync.Mutex
+	envRPCs map[string]*RPCClient
 }
 
-// NewClient creates a new Sui client instance
-func NewClient() *Client {
+// NewClient creates a Sui client from the validated sui config section
+func NewClient(cfg config.SuiConfig) (*Client, error) {
 	// Use the configured executable path or default to "sui"
-	execPath := viper.GetString("sui.executable_path")
+	execPath := cfg.ExecutablePath
 	if execPath == "" {
 		execPath = "sui"
 	}
 
+	client := NewClientWithExecutor(execPath, NewProcessExecutor())
+	if cfg.ClientConfig != "" && cfg.EnvConfigDir != "" {
+		client.UseEnvironments(NewEnvironments(cfg.ClientConfig, cfg.EnvConfigDir))
+	}
+
+	switch cfg.Backend {
+	case "", BackendCLI:
+	case BackendRPC:
+		if cfg.RPCURL == "" {
+			return nil, errors.New("sui.rpc_url is required when sui.backend is rpc")
+		}
+		client.UseRPC(NewRPCClient(cfg.RPCURL, nil))
+	default:
+		return nil, fmt.Errorf("unknown sui.backend %q (expected %q or %q)", cfg.Backend, BackendCLI, BackendRPC)
+	}
+
+	return client, nil
+}
+
+// NewClientWithExecutor creates a Sui client that runs commands through the given executor
+func NewClientWithExecutor(executablePath string, executor Executor) *Client {
 	return &Client{
-		executablePath: execPath,
+		executablePath: executablePath,
+		executor:       executor,
 	}
 }
 
-// ExecuteCommand runs a Sui command and returns the output
-func (c *Client) ExecuteCommand(args ...string) (string, error) {
-	cmd := exec.Command(c.executablePath, args...)
+// TxOptions are the flags shared by every signing command
+type TxOptions struct {
+	// GasBudget in MIST; empty lets the CLI estimate it
+	GasBudget string
+	// DryRun simulates the transaction and returns its predicted effects without submitting it
+	DryRun bool
+}
+
+// UseRPC routes read queries through the given JSON-RPC client instead of the CLI
+func (c *Client) UseRPC(rpc *RPCClient) {
+	c.rpc = rpc
+}
 
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
+// UseEnvironments enables per-call environment selection
+func (c *Client) UseEnvironments(envs *Environments) {
+	c.envs = envs
+}
 
-	err := cmd.Run()
-	if err != nil {
-		return "", fmt.Errorf("error executing sui command: %v\nStderr: %s", err, stderr.String())
+// ResolveEnv looks up an environment by alias for use with WithEnv
+func (c *Client) ResolveEnv(alias string) (Env, error) {
+	if c.envs == nil {
+		return Env{}, errors.New("per-call env selection is not available: sui.client_config is not set")
 	}
+	return c.envs.Resolve(alias)
+}
 
-	return stdout.String(), nil
+// ResolveSender returns the call's environment, or the active env when none is
+// selected, set up to sign with address. The caller checks that the keystore holds it.
+func (c *Client) ResolveSender(ctx context.Context, address string) (Env, error) {
+	if c.envs == nil {
+		return Env{}, errors.New("per-call sender selection is not available: sui.client_config is not set")
+	}
+	env, _ := EnvFromContext(ctx)
+	return c.envs.ResolveSender(env.Alias, address)
+}
+
+// rpcFor returns the RPC client for the call's environment, or nil when reads go through the CLI
+func (c *Client) rpcFor(ctx context.Context) *RPCClient {
+	env, ok := EnvFromContext(ctx)
+	if c.rpc == nil || !ok || env.RPC == "" {
+		return c.rpc
+	}
+	return c.rpcAt(env.RPC)
+}
+
+// rpcAt returns a cached RPC client for the fullnode at url
+func (c *Client) rpcAt(url string) *RPCClient {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.envRPCs == nil {
+		c.envRPCs = make(map[string]*RPCClient)
+	}
+	rpc, ok := c.envRPCs[url]
+	if !ok {
+		rpc = NewRPCClient(url, nil)
+		c.envRPCs[url] = rpc
+	}
+	return rpc
+}
+
+// fullnodeRPC returns an RPC client for reads the CLI cannot do, such as SuiNS
+// lookups and transaction queries; purpose names the read in the error.
+// Without the rpc backend it uses the fullnode of the call's env, or of the active env.
+func (c *Client) fullnodeRPC(ctx context.Context, purpose string) (*RPCClient, error) {
+	if rpc := c.rpcFor(ctx); rpc != nil {
+		return rpc, nil
+	}
+	env, ok := EnvFromContext(ctx)
+	if !ok && c.envs != nil {
+		var err error
+		if env, err = c.envs.Active(); err != nil {
+			return nil, err
+		}
+	}
+	if env.RPC == "" {
+		return nil, fmt.Errorf("%s needs a fullnode: set sui.rpc_url or sui.client_config", purpose)
+	}
+	return c.rpcAt(env.RPC), nil
+}
+
+// ExecuteCommand runs a Sui command and returns the output. Client commands run
+// against the environment selected with WithEnv, if any, through its own config file.
+func (c *Client) ExecuteCommand(ctx context.Context, args ...string) (string, error) {
+	if env, ok := EnvFromContext(ctx); ok && len(args) > 0 && args[0] == "client" {
+		args = append([]string{"client", "--client.config", env.ClientConfig}, args[1:]...)
+	}
+	output, err := c.executor.Execute(ctx, c.executablePath, args...)
+	record(ctx, c.executablePath, args, err)
+	return output, err
 }
 
 // GetVersion returns the Sui client version
-func (c *Client) GetVersion() (string, error) {
-	output, err := c.ExecuteCommand("--version")
+func (c *Client) GetVersion(ctx context.Context) (string, error) {
+	output, err := c.ExecuteCommand(ctx, "--version")
 	if err != nil {
 		return "", err
 	}
 	return strings.TrimSpace(output), nil
 }
 
-func (c *Client) GetSuiPath() (string, error) {
-
-	output, err := exec.Command("which", "sui").Output()
+// GetSuiPath returns the resolved path of the sui executable
+func (c *Client) GetSuiPath(ctx context.Context) (string, error) {
+	output, err := c.executor.Execute(ctx, "which", c.executablePath)
+	record(ctx, "which", []string{c.executablePath}, err)
 	if err != nil {
 		return "", err
 	}
-	return strings.TrimSpace(string(output)), nil
+	return strings.TrimSpace(output), nil
+}
+
+// executeJSON runs a command with --json and decodes its output into v
+func (c *Client) executeJSON(ctx context.Context, v any, args ...string) error {
+	output, err := c.ExecuteCommand(ctx, append(args, "--json")...)
+	if err != nil {
+		return err
+	}
+	if err := decodeJSONOutput(output, v); err != nil {
+		return fmt.Errorf("failed to parse output of sui %s: %w", strings.Join(args[:min(2, len(args))], " "), err)
+	}
+	return nil
 }
 
-// GetBalance gets the balance for a specific address
-func (c *Client) GetBalance(address string) (string, error) {
+// executeTransaction runs a signing command with the shared transaction flags and
+// decodes the transaction response. Dry runs are simulated by the fullnode when
+// the rpc backend is enabled and by the CLI otherwise.
+func (c *Client) executeTransaction(ctx context.Context, opts TxOptions, args ...string) (*TransactionBlockResponse, error) {
+	if opts.GasBudget != "" {
+		args = append(args, "--gas-budget", opts.GasBudget)
+	}
+
+	if rpc := c.rpcFor(ctx); opts.DryRun && rpc != nil {
+		output, err := c.ExecuteCommand(ctx, append(args, "--serialize-unsigned-transaction")...)
+		if err != nil {
+			return nil, err
+		}
+		raw, err := rpc.DryRunTransactionBlock(ctx, lastLine(output))
+		if err != nil {
+			return nil, err
+		}
+		var resp TransactionBlockResponse
+		if err := json.Unmarshal(raw, &resp); err != nil {
+			return nil, fmt.Errorf("failed to parse dry run result: %w", err)
+		}
+		return &resp, nil
+	}
+
+	if opts.DryRun {
+		args = append(args, "--dry-run")
+	}
+	var resp TransactionBlockResponse
+	if err := c.executeJSON(ctx, &resp, args...); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// GetBalance gets the balance of every coin type held by a specific address
+func (c *Client) GetBalance(ctx context.Context, address string) ([]Balance, error) {
+	if rpc := c.rpcFor(ctx); rpc != nil {
+		if address == "" {
+			return nil, errAddressRequired
+		}
+		raw, err := rpc.GetAllBalances(ctx, address)
+		if err != nil {
+			return nil, err
+		}
+		var balances []Balance
+		if err := json.Unmarshal(raw, &balances); err != nil {
+			return nil, fmt.Errorf("failed to parse balances: %w", err)
+		}
+		return balances, nil
+	}
+
 	args := []string{"client", "balance"}
 	if address != "" {
 		args = append(args, address)
 	}
-	return c.ExecuteCommand(args...)
+	output, err := c.ExecuteCommand(ctx, append(args, "--json")...)
+	if err != nil {
+		return nil, err
+	}
+	balances, err := parseCLIBalances(output)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse output of sui client balance: %w", err)
+	}
+	return balances, nil
 }
 
 // GetObjects gets objects owned by an address
-func (c *Client) GetObjects(address string) (string, error) {
+func (c *Client) GetObjects(ctx context.Context, address string) ([]ObjectData, error) {
+	if rpc := c.rpcFor(ctx); rpc != nil {
+		if address == "" {
+			return nil, errAddressRequired
+		}
+		var objects []ObjectData
+		cursor := ""
+		for {
+			raw, err := rpc.GetOwnedObjects(ctx, address, cursor)
+			if err != nil {
+				return nil, err
+			}
+			var page struct {
+				Data        json.RawMessage `json:"data"`
+				NextCursor  *string         `json:"nextCursor"`
+				HasNextPage bool            `json:"hasNextPage"`
+			}
+			if err := json.Unmarshal(raw, &page); err != nil {
+				return nil, fmt.Errorf("failed to parse owned objects: %w", err)
+			}
+			data, err := decodeObjects(page.Data)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse owned objects: %w", err)
+			}
+			objects = append(objects, data...)
+			if !page.HasNextPage || page.NextCursor == nil {
+				return objects, nil
+			}
+			cursor = *page.NextCursor
+		}
+	}
+
 	args := []string{"client", "objects"}
 	if address != "" {
 		args = append(args, address)
 	}
-	args = append(args, "--json")
-	return c.ExecuteCommand(args...)
+	var raw json.RawMessage
+	if err := c.executeJSON(ctx, &raw, args...); err != nil {
+		return nil, err
+	}
+	objects, err := decodeObjects(raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse owned objects: %w", err)
+	}
+	return objects, nil
 }
 
-func (c *Client) GetObject(objectID string) (string, error) {
-	args := []string{"client", "object", objectID, "--json"}
-	return c.ExecuteCommand(args...)
+// GetObject gets the details of a single object
+func (c *Client) GetObject(ctx context.Context, objectID string) (*ObjectData, error) {
+	var raw json.RawMessage
+	if rpc := c.rpcFor(ctx); rpc != nil {
+		var err error
+		if raw, err = rpc.GetObject(ctx, objectID); err != nil {
+			return nil, err
+		}
+	} else if err := c.executeJSON(ctx, &raw, "client", "object", objectID); err != nil {
+		return nil, err
+	}
+
+	obj, err := decodeObject(raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse object %s: %w", objectID, err)
+	}
+	return obj, nil
 }
 
 // GetActiveValidators gets the list of active validators
-func (c *Client) GetActiveValidators() (string, error) {
+func (c *Client) GetActiveValidators(ctx context.Context) (string, error) {
 	args := []string{"client", "active-validators"}
-	return c.ExecuteCommand(args...)
+	return c.ExecuteCommand(ctx, args...)
 }
 
 // GetNetwork returns the current network info
-func (c *Client) GetNetwork() (string, error) {
+func (c *Client) GetNetwork(ctx context.Context) (string, error) {
 	args := []string{"client", "envs"}
-	return c.ExecuteCommand(args...)
+	return c.ExecuteCommand(ctx, args...)
 }
 
 // GetTransaction retrieves information about a specific transaction
-func (c *Client) GetTransaction(txID string) (string, error) {
-	args := []string{"client", "tx-block", txID}
-	return c.ExecuteCommand(args...)
+func (c *Client) GetTransaction(ctx context.Context, txID string) (*TransactionBlockResponse, error) {
+	if rpc := c.rpcFor(ctx); rpc != nil {
+		raw, err := rpc.GetTransactionBlock(ctx, txID)
+		if err != nil {
+			return nil, err
+		}
+		var resp TransactionBlockResponse
+		if err := json.Unmarshal(raw, &resp); err != nil {
+			return nil, fmt.Errorf("failed to parse transaction %s: %w", txID, err)
+		}
+		return &resp, nil
+	}
+
+	var resp TransactionBlockResponse
+	if err := c.executeJSON(ctx, &resp, "client", "tx-block", txID); err != nil {
+		return nil, err
+	}
+	return &resp, nil
 }
 
 // PaySUI transfers SUI tokens to recipients (supports multiple recipients)
-func (c *Client) PaySUI(recipients []string, inputCoins []string, amounts []uint64, gasBudget string) (string, error) {
+func (c *Client) PaySUI(ctx context.Context, recipients []string, inputCoins []string, amounts []*big.Int, opts TxOptions) (*TransactionBlockResponse, error) {
 	args := []string{"client", "pay-sui"}
 
 	// Add multiple --input-coins flags
@@ -119,134 +377,366 @@ func (c *Client) PaySUI(recipients []string, inputCoins []string, amounts []uint
 
 	// Add multiple --amounts flags
 	for _, amount := range amounts {
-		args = append(args, "--amounts", fmt.Sprintf("%d", amount))
-	}
-
-	if gasBudget != "" {
-		args = append(args, "--gas-budget", gasBudget)
+		args = append(args, "--amounts", amount.String())
 	}
 
-	return c.ExecuteCommand(args...)
+	return c.executeTransaction(ctx, opts, args...)
 }
 
 // ============ Address and Environment Management ============
 
 // GetActiveAddress returns the current active address
-func (c *Client) GetActiveAddress() (string, error) {
+func (c *Client) GetActiveAddress(ctx context.Context) (string, error) {
 	args := []string{"client", "active-address"}
-	return c.ExecuteCommand(args...)
+	return c.ExecuteCommand(ctx, args...)
 }
 
 // GetAddresses returns all addresses managed by the client
-func (c *Client) GetAddresses() (string, error) {
+func (c *Client) GetAddresses(ctx context.Context) (string, error) {
 	args := []string{"client", "addresses"}
-	return c.ExecuteCommand(args...)
+	return c.ExecuteCommand(ctx, args...)
+}
+
+// ListAddresses returns the addresses in the keystore and which one is active
+func (c *Client) ListAddresses(ctx context.Context) (*AddressList, error) {
+	var list AddressList
+	if err := c.executeJSON(ctx, &list, "client", "addresses"); err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// SwitchAddress makes address the active address of the shared client config
+func (c *Client) SwitchAddress(ctx context.Context, address string) (string, error) {
+	return c.executeShared(ctx, "client", "switch", "--address", address)
+}
+
+// SwitchEnv makes alias the active environment of the shared client config
+func (c *Client) SwitchEnv(ctx context.Context, alias string) (string, error) {
+	return c.executeShared(ctx, "client", "switch", "--env", alias)
+}
+
+// executeShared runs a client command against the user's own client config,
+// ignoring any per-call environment, so that changes to it persist
+func (c *Client) executeShared(ctx context.Context, args ...string) (string, error) {
+	ctx = context.WithValue(ctx, envKey{}, nil)
+	if c.envs != nil {
+		args = append([]string{"client", "--client.config", c.envs.Source()}, args[1:]...)
+	}
+	return c.ExecuteCommand(ctx, args...)
+}
+
+// ResolveName returns the address a SuiNS name such as alice.sui points at
+func (c *Client) ResolveName(ctx context.Context, name string) (string, error) {
+	rpc, err := c.fullnodeRPC(ctx, "name resolution")
+	if err != nil {
+		return "", err
+	}
+	// SuiNS names are registered in lower case; IsName accepts any case
+	raw, err := rpc.ResolveNameServiceAddress(ctx, strings.ToLower(name))
+	if err != nil {
+		return "", err
+	}
+	var address *string
+	if err := json.Unmarshal(raw, &address); err != nil {
+		return "", fmt.Errorf("failed to parse address of %s: %w", name, err)
+	}
+	if address == nil {
+		return "", fmt.Errorf("name %s is not registered", name)
+	}
+	return NormalizeAddress(*address)
+}
+
+// LookupNames returns the SuiNS names that point at address
+func (c *Client) LookupNames(ctx context.Context, address string) ([]string, error) {
+	rpc, err := c.fullnodeRPC(ctx, "name resolution")
+	if err != nil {
+		return nil, err
+	}
+	raw, err := rpc.ResolveNameServiceNames(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+	var page struct {
+		Data []string `json:"data"`
+	}
+	if err := json.Unmarshal(raw, &page); err != nil {
+		return nil, fmt.Errorf("failed to parse names of %s: %w", address, err)
+	}
+	return page.Data, nil
+}
+
+// QueryTransactions returns a page of up to limit transactions matching filter,
+// a single suix_queryTransactionBlocks filter such as {"FromAddress": "0x..."},
+// starting after the transaction digest cursor (the first page when empty)
+func (c *Client) QueryTransactions(ctx context.Context, filter map[string]any, cursor string, limit int, descending bool) (*TransactionPage, error) {
+	rpc, err := c.fullnodeRPC(ctx, "querying transactions")
+	if err != nil {
+		return nil, err
+	}
+	raw, err := rpc.QueryTransactionBlocks(ctx, filter, cursor, limit, descending)
+	if err != nil {
+		return nil, err
+	}
+	var page TransactionPage
+	if err := json.Unmarshal(raw, &page); err != nil {
+		return nil, fmt.Errorf("failed to parse transactions: %w", err)
+	}
+	return &page, nil
+}
+
+// QueryEvents returns a page of up to limit events matching filter, a single
+// suix_queryEvents filter such as {"Sender": "0x..."}, starting after cursor
+// (the first page when nil)
+func (c *Client) QueryEvents(ctx context.Context, filter map[string]any, cursor *EventID, limit int, descending bool) (*EventPage, error) {
+	rpc, err := c.fullnodeRPC(ctx, "querying events")
+	if err != nil {
+		return nil, err
+	}
+	raw, err := rpc.QueryEvents(ctx, filter, cursor, limit, descending)
+	if err != nil {
+		return nil, err
+	}
+	var page EventPage
+	if err := json.Unmarshal(raw, &page); err != nil {
+		return nil, fmt.Errorf("failed to parse events: %w", err)
+	}
+	return &page, nil
+}
+
+// LatestCheckpoint returns the sequence number of the latest checkpoint the fullnode has executed
+func (c *Client) LatestCheckpoint(ctx context.Context) (uint64, error) {
+	rpc, err := c.fullnodeRPC(ctx, "watching checkpoints")
+	if err != nil {
+		return 0, err
+	}
+	raw, err := rpc.GetLatestCheckpointSequenceNumber(ctx)
+	if err != nil {
+		return 0, err
+	}
+	var seq string
+	if err := json.Unmarshal(raw, &seq); err != nil {
+		return 0, fmt.Errorf("unexpected checkpoint sequence number %s: %w", raw, err)
+	}
+	n, err := strconv.ParseUint(seq, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("unexpected checkpoint sequence number %s: %w", raw, err)
+	}
+	return n, nil
 }
 
 // GetActiveEnv returns the current active environment
-func (c *Client) GetActiveEnv() (string, error) {
+func (c *Client) GetActiveEnv(ctx context.Context) (string, error) {
 	args := []string{"client", "active-env"}
-	return c.ExecuteCommand(args...)
+	return c.ExecuteCommand(ctx, args...)
 }
 
 // GetEnvs returns all Sui environments
-func (c *Client) GetEnvs() (string, error) {
+func (c *Client) GetEnvs(ctx context.Context) (string, error) {
 	args := []string{"client", "envs"}
-	return c.ExecuteCommand(args...)
+	return c.ExecuteCommand(ctx, args...)
 }
 
 // GetChainIdentifier queries the chain identifier from the RPC endpoint
-func (c *Client) GetChainIdentifier() (string, error) {
+func (c *Client) GetChainIdentifier(ctx context.Context) (string, error) {
+	if rpc := c.rpcFor(ctx); rpc != nil {
+		raw, err := rpc.GetChainIdentifier(ctx)
+		if err != nil {
+			return "", err
+		}
+		var id string
+		if err := json.Unmarshal(raw, &id); err != nil {
+			return "", fmt.Errorf("unexpected chain identifier %s: %w", raw, err)
+		}
+		return id, nil
+	}
+
 	args := []string{"client", "chain-identifier"}
-	return c.ExecuteCommand(args...)
+	return c.ExecuteCommand(ctx, args...)
 }
 
 // ============ Gas Management ============
 
 // GetGas obtains all gas objects owned by the address
-func (c *Client) GetGas(address string) (string, error) {
+func (c *Client) GetGas(ctx context.Context, address string) ([]GasCoin, error) {
+	if rpc := c.rpcFor(ctx); rpc != nil {
+		if address == "" {
+			return nil, errAddressRequired
+		}
+		coins, err := c.GetCoins(ctx, address, "")
+		if err != nil {
+			return nil, err
+		}
+		return coinsToGasCoins(coins)
+	}
+
 	args := []string{"client", "gas"}
 	if address != "" {
 		args = append(args, address)
 	}
-	return c.ExecuteCommand(args...)
+	var gasCoins []GasCoin
+	if err := c.executeJSON(ctx, &gasCoins, args...); err != nil {
+		return nil, err
+	}
+	return gasCoins, nil
+}
+
+// GetCoins lists every coin of coinType owned by address. An empty address
+// means the address the call signs with.
+func (c *Client) GetCoins(ctx context.Context, address string, coinType string) ([]Coin, error) {
+	if rpc := c.rpcFor(ctx); rpc != nil {
+		if address == "" {
+			signer, err := c.signer(ctx)
+			if err != nil {
+				return nil, err
+			}
+			address = signer
+		}
+
+		var coins []Coin
+		cursor := ""
+		for {
+			raw, err := rpc.GetCoins(ctx, address, coinType, cursor)
+			if err != nil {
+				return nil, err
+			}
+			var page struct {
+				Data        []Coin  `json:"data"`
+				NextCursor  *string `json:"nextCursor"`
+				HasNextPage bool    `json:"hasNextPage"`
+			}
+			if err := json.Unmarshal(raw, &page); err != nil {
+				return nil, fmt.Errorf("failed to parse coins: %w", err)
+			}
+			coins = append(coins, page.Data...)
+			if !page.HasNextPage || page.NextCursor == nil {
+				return coins, nil
+			}
+			cursor = *page.NextCursor
+		}
+	}
+
+	args := []string{"client", "balance"}
+	if address != "" {
+		args = append(args, address)
+	}
+	args = append(args, "--coin-type", coinType, "--with-coins", "--json")
+	output, err := c.ExecuteCommand(ctx, args...)
+	if err != nil {
+		return nil, err
+	}
+	coins, err := parseCLICoins(output)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse output of sui client balance: %w", err)
+	}
+	return coins, nil
+}
+
+// GetCoinMetadata returns the name, symbol and decimals of a coin type. With the
+// CLI backend metadata is only known for coin types the signer holds.
+func (c *Client) GetCoinMetadata(ctx context.Context, coinType string) (*CoinMetadata, error) {
+	if rpc := c.rpcFor(ctx); rpc != nil {
+		raw, err := rpc.GetCoinMetadata(ctx, coinType)
+		if err != nil {
+			return nil, err
+		}
+		var meta *CoinMetadata
+		if err := json.Unmarshal(raw, &meta); err != nil {
+			return nil, fmt.Errorf("failed to parse coin metadata: %w", err)
+		}
+		if meta == nil {
+			return nil, fmt.Errorf("no coin metadata for %s", coinType)
+		}
+		return meta, nil
+	}
+
+	output, err := c.ExecuteCommand(ctx, "client", "balance", "--coin-type", coinType, "--json")
+	if err != nil {
+		return nil, err
+	}
+	groups, err := decodeCLIBalanceGroups(output)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse output of sui client balance: %w", err)
+	}
+	for _, group := range groups {
+		if group.meta != nil {
+			return group.meta, nil
+		}
+	}
+	return nil, fmt.Errorf("no coin metadata for %s: the signer holds none of it", coinType)
+}
+
+// signer returns the address the call signs with: its sender, or the active address
+func (c *Client) signer(ctx context.Context) (string, error) {
+	if env, ok := EnvFromContext(ctx); ok && env.Sender != "" {
+		return env.Sender, nil
+	}
+	output, err := c.GetActiveAddress(ctx)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(output), nil
 }
 
 // RequestFromFaucet requests gas coins from faucet
-func (c *Client) RequestFromFaucet(address string) (string, error) {
+func (c *Client) RequestFromFaucet(ctx context.Context, address string) (string, error) {
 	args := []string{"client", "faucet"}
 	if address != "" {
 		args = append(args, "--address", address)
 	}
-	return c.ExecuteCommand(args...)
+	return c.ExecuteCommand(ctx, args...)
 }
 
 // ============ Transaction Operations ============
 
 // Transfer transfers an object to another address
-func (c *Client) Transfer(to string, objectID string, gasBudget string) (string, error) {
+func (c *Client) Transfer(ctx context.Context, to string, objectID string, opts TxOptions) (*TransactionBlockResponse, error) {
 	args := []string{"client", "transfer",
 		"--to", to,
 		"--object-id", objectID}
 
-	if gasBudget != "" {
-		args = append(args, "--gas-budget", gasBudget)
-	}
-
-	return c.ExecuteCommand(args...)
+	return c.executeTransaction(ctx, opts, args...)
 }
 
-// TransferSUI transfers SUI to another address
-func (c *Client) TransferSUI(to string, suiCoinObjectID string, amount uint64, gasBudget string) (string, error) {
+// TransferSUI transfers SUI to another address; a nil amount transfers the whole coin
+func (c *Client) TransferSUI(ctx context.Context, to string, suiCoinObjectID string, amount *big.Int, opts TxOptions) (*TransactionBlockResponse, error) {
 	args := []string{"client", "transfer-sui",
 		"--to", to,
 		"--sui-coin-object-id", suiCoinObjectID}
 
-	if amount > 0 {
-		args = append(args, "--amount", fmt.Sprintf("%d", amount))
+	if amount != nil && amount.Sign() > 0 {
+		args = append(args, "--amount", amount.String())
 	}
 
-	if gasBudget != "" {
-		args = append(args, "--gas-budget", gasBudget)
-	}
-
-	return c.ExecuteCommand(args...)
+	return c.executeTransaction(ctx, opts, args...)
 }
 
 // SplitCoin splits a coin object into multiple coins
-func (c *Client) SplitCoin(coinID string, amounts []uint64, gasBudget string) (string, error) {
+func (c *Client) SplitCoin(ctx context.Context, coinID string, amounts []*big.Int, opts TxOptions) (*TransactionBlockResponse, error) {
 	args := []string{"client", "split-coin",
 		"--coin-id", coinID}
 
 	// Convert amounts to comma-separated string
 	amountStrs := make([]string, len(amounts))
 	for i, amt := range amounts {
-		amountStrs[i] = fmt.Sprintf("%d", amt)
+		amountStrs[i] = amt.String()
 	}
 	args = append(args, "--amounts", strings.Join(amountStrs, ","))
 
-	if gasBudget != "" {
-		args = append(args, "--gas-budget", gasBudget)
-	}
-
-	return c.ExecuteCommand(args...)
+	return c.executeTransaction(ctx, opts, args...)
 }
 
 // MergeCoin merges two coin objects into one
-func (c *Client) MergeCoin(primaryCoin string, coinToMerge string, gasBudget string) (string, error) {
+func (c *Client) MergeCoin(ctx context.Context, primaryCoin string, coinToMerge string, opts TxOptions) (*TransactionBlockResponse, error) {
 	args := []string{"client", "merge-coin",
 		"--primary-coin", primaryCoin,
 		"--coin-to-merge", coinToMerge}
 
-	if gasBudget != "" {
-		args = append(args, "--gas-budget", gasBudget)
-	}
-
-	return c.ExecuteCommand(args...)
+	return c.executeTransaction(ctx, opts, args...)
 }
 
 // Pay pays coins to recipients following specified amounts
-func (c *Client) Pay(inputCoins []string, recipients []string, amounts []uint64, gasBudget string) (string, error) {
+func (c *Client) Pay(ctx context.Context, inputCoins []string, recipients []string, amounts []*big.Int, opts TxOptions) (*TransactionBlockResponse, error) {
 	args := []string{"client", "pay"}
 
 	// Add multiple --input-coins flags
@@ -261,18 +751,14 @@ func (c *Client) Pay(inputCoins []string, recipients []string, amounts []uint64,
 
 	// Add multiple --amounts flags
 	for _, amount := range amounts {
-		args = append(args, "--amounts", fmt.Sprintf("%d", amount))
+		args = append(args, "--amounts", amount.String())
 	}
 
-	if gasBudget != "" {
-		args = append(args, "--gas-budget", gasBudget)
-	}
-
-	return c.ExecuteCommand(args...)
+	return c.executeTransaction(ctx, opts, args...)
 }
 
 // PayAllSUI pays all residual SUI coins to the recipient
-func (c *Client) PayAllSUI(inputCoins []string, recipient string, gasBudget string) (string, error) {
+func (c *Client) PayAllSUI(ctx context.Context, inputCoins []string, recipient string, opts TxOptions) (*TransactionBlockResponse, error) {
 	args := []string{"client", "pay-all-sui"}
 
 	// Add multiple --input-coins flags
@@ -282,17 +768,13 @@ func (c *Client) PayAllSUI(inputCoins []string, recipient string, gasBudget stri
 
 	args = append(args, "--recipient", recipient)
 
-	if gasBudget != "" {
-		args = append(args, "--gas-budget", gasBudget)
-	}
-
-	return c.ExecuteCommand(args...)
+	return c.executeTransaction(ctx, opts, args...)
 }
 
 // ============ Contract Interaction ============
 
 // Call calls a Move function
-func (c *Client) Call(packageID string, module string, function string, typeArgs []string, args []string, gasBudget string) (string, error) {
+func (c *Client) Call(ctx context.Context, packageID string, module string, function string, typeArgs []string, args []string, opts TxOptions) (*TransactionBlockResponse, error) {
 	cmdArgs := []string{"client", "call",
 		"--package", packageID,
 		"--module", module,
@@ -308,50 +790,62 @@ func (c *Client) Call(packageID string, module string, function string, typeArgs
 		cmdArgs = append(cmdArgs, "--args", arg)
 	}
 
-	if gasBudget != "" {
-		cmdArgs = append(cmdArgs, "--gas-budget", gasBudget)
-	}
-
-	return c.ExecuteCommand(cmdArgs...)
+	return c.executeTransaction(ctx, opts, cmdArgs...)
 }
 
 // Publish publishes Move modules
-func (c *Client) Publish(packagePath string, gasBudget string, skipDependencyVerification bool) (string, error) {
+func (c *Client) Publish(ctx context.Context, packagePath string, opts TxOptions, skipDependencyVerification bool) (*TransactionBlockResponse, error) {
 	args := []string{"client", "publish", packagePath}
 
-	if gasBudget != "" {
-		args = append(args, "--gas-budget", gasBudget)
-	}
-
 	if skipDependencyVerification {
 		args = append(args, "--skip-dependency-verification")
 	}
 
-	return c.ExecuteCommand(args...)
+	return c.executeTransaction(ctx, opts, args...)
 }
 
 // GetDynamicField queries a dynamic field by its address
-func (c *Client) GetDynamicField(parentObjectID string, name string) (string, error) {
+func (c *Client) GetDynamicField(ctx context.Context, parentObjectID string, name string) (string, error) {
+	if rpc := c.rpcFor(ctx); rpc != nil {
+		if name == "" {
+			return indentJSON(rpc.GetDynamicFields(ctx, parentObjectID))
+		}
+		if !json.Valid([]byte(name)) {
+			return "", errors.New(`name must be a JSON dynamic field name such as {"type":"u64","value":"1"} when sui.backend is rpc`)
+		}
+		return indentJSON(rpc.GetDynamicFieldObject(ctx, parentObjectID, json.RawMessage(name)))
+	}
+
 	args := []string{"client", "dynamic-field", parentObjectID}
 	if name != "" {
 		args = append(args, "--name", name)
 	}
-	return c.ExecuteCommand(args...)
+	return c.ExecuteCommand(ctx, args...)
+}
+
+// GetMoveModule returns the normalized form of a published Move module: its
+// structs with their fields and abilities, and its function signatures
+func (c *Client) GetMoveModule(ctx context.Context, packageID string, module string) (json.RawMessage, error) {
+	rpc, err := c.fullnodeRPC(ctx, "reading Move modules")
+	if err != nil {
+		return nil, err
+	}
+	return rpc.GetNormalizedMoveModule(ctx, packageID, module)
 }
 
 // ============ Move Development ============
 
 // MoveBuild builds a Move package
-func (c *Client) MoveBuild(packagePath string) (string, error) {
+func (c *Client) MoveBuild(ctx context.Context, packagePath string) (string, error) {
 	args := []string{"move", "build"}
 	if packagePath != "" {
 		args = append(args, "--path", packagePath)
 	}
-	return c.ExecuteCommand(args...)
+	return c.ExecuteCommand(ctx, args...)
 }
 
 // MoveTest runs Move unit tests
-func (c *Client) MoveTest(packagePath string, filter string) (string, error) {
+func (c *Client) MoveTest(ctx context.Context, packagePath string, filter string) (string, error) {
 	args := []string{"move", "test"}
 	if packagePath != "" {
 		args = append(args, "--path", packagePath)
@@ -359,28 +853,28 @@ func (c *Client) MoveTest(packagePath string, filter string) (string, error) {
 	if filter != "" {
 		args = append(args, "--filter", filter)
 	}
-	return c.ExecuteCommand(args...)
+	return c.ExecuteCommand(ctx, args...)
 }
 
 // MoveNew creates a new Move package
-func (c *Client) MoveNew(name string, path string) (string, error) {
+func (c *Client) MoveNew(ctx context.Context, name string, path string) (string, error) {
 	args := []string{"move", "new", name}
 	if path != "" {
 		args = append(args, path)
 	}
-	return c.ExecuteCommand(args...)
+	return c.ExecuteCommand(ctx, args...)
 }
 
 // ============ Keytool Management ============
 
 // KeytoolList lists all keys in the keystore
-func (c *Client) KeytoolList() (string, error) {
+func (c *Client) KeytoolList(ctx context.Context) (string, error) {
 	args := []string{"keytool", "list"}
-	return c.ExecuteCommand(args...)
+	return c.ExecuteCommand(ctx, args...)
 }
 
 // KeytoolGenerate generates a new keypair
-func (c *Client) KeytoolGenerate(keyScheme string, derivationPath string, wordLength string) (string, error) {
+func (c *Client) KeytoolGenerate(ctx context.Context, keyScheme string, derivationPath string, wordLength string) (string, error) {
 	args := []string{"keytool", "generate", keyScheme}
 	if derivationPath != "" {
 		args = append(args, "--derivation-path", derivationPath)
@@ -388,22 +882,11 @@ func (c *Client) KeytoolGenerate(keyScheme string, derivationPath string, wordLe
 	if wordLength != "" {
 		args = append(args, "--word-length", wordLength)
 	}
-	return c.ExecuteCommand(args...)
+	return c.ExecuteCommand(ctx, args...)
 }
 
 // KeytoolExport exports the private key for a given address
-func (c *Client) KeytoolExport(address string) (string, error) {
+func (c *Client) KeytoolExport(ctx context.Context, address string) (string, error) {
 	args := []string{"keytool", "export", address}
-	return c.ExecuteCommand(args...)
-}
-
-// ============ Helper Functions ============
-
-// uint64SliceToStrings converts a slice of uint64 to a slice of strings
-func uint64SliceToStrings(nums []uint64) []string {
-	strs := make([]string, len(nums))
-	for i, num := range nums {
-		strs[i] = fmt.Sprintf("%d", num)
-	}
-	return strs
+	return c.ExecuteCommand(ctx, args...)
 }
@@ -0,0 +1,103 @@
+package sui_test
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/krli/go-sui-mcp/internal/sui"
+	"github.com/krli/go-sui-mcp/internal/sui/suitest"
+)
+
+// pagedStub serves pages[i] to the request whose cursor param, at index
+// cursorParam, is the nextCursor of pages[i-1]
+func pagedStub(t *testing.T, method string, cursorParam int, pages ...map[string]any) (*sui.Client, *suitest.RPCStub) {
+	t.Helper()
+	stub := suitest.NewRPCStub()
+	stub.OnFunc(method, func(params []json.RawMessage) any {
+		var cursor *string
+		if len(params) > cursorParam {
+			json.Unmarshal(params[cursorParam], &cursor)
+		}
+		if cursor == nil {
+			return pages[0]
+		}
+		for i, page := range pages[:len(pages)-1] {
+			if page["nextCursor"] == *cursor {
+				return pages[i+1]
+			}
+		}
+		t.Errorf("%s called with unknown cursor %q", method, *cursor)
+		return map[string]any{"data": []any{}, "hasNextPage": false}
+	})
+	rpc, srv := suitest.NewRPCClient(stub)
+	t.Cleanup(srv.Close)
+	client := suitest.NewClient(suitest.NewFakeExecutor())
+	client.UseRPC(rpc)
+	return client, stub
+}
+
+// ownedObject is a suix_getOwnedObjects entry for the recorded object with another ID
+func ownedObject(t *testing.T, objectID string) any {
+	t.Helper()
+	var obj map[string]any
+	if err := json.Unmarshal([]byte(suitest.Fixture("rpc_get_object.json")), &obj); err != nil {
+		t.Fatal(err)
+	}
+	obj["data"].(map[string]any)["objectId"] = objectID
+	return obj
+}
+
+func TestGetObjectsFollowsPages(t *testing.T) {
+	ids := []string{
+		"0x0000000000000000000000000000000000000000000000000000000000000a01",
+		"0x0000000000000000000000000000000000000000000000000000000000000a02",
+		"0x0000000000000000000000000000000000000000000000000000000000000a03",
+	}
+	client, stub := pagedStub(t, "suix_getOwnedObjects", 2,
+		map[string]any{"data": []any{ownedObject(t, ids[0]), ownedObject(t, ids[1])}, "nextCursor": ids[1], "hasNextPage": true},
+		map[string]any{"data": []any{ownedObject(t, ids[2])}, "nextCursor": ids[2], "hasNextPage": false},
+	)
+
+	objects, err := client.GetObjects(context.Background(), suitest.ActiveAddress)
+	if err != nil {
+		t.Fatalf("GetObjects: %v", err)
+	}
+	var got []string
+	for _, obj := range objects {
+		got = append(got, obj.ObjectID)
+	}
+	if strings.Join(got, ",") != strings.Join(ids, ",") {
+		t.Errorf("GetObjects returned %v, want %v", got, ids)
+	}
+	if n := len(stub.Requests()); n != 2 {
+		t.Errorf("GetObjects made %d requests, want 2", n)
+	}
+}
+
+func TestGetGasFollowsPages(t *testing.T) {
+	coin := func(id, balance string) map[string]any {
+		return map[string]any{"coinType": "0x2::sui::SUI", "coinObjectId": id, "version": "1", "digest": suitest.TxDigest, "balance": balance}
+	}
+	client, stub := pagedStub(t, "suix_getCoins", 2,
+		map[string]any{"data": []any{coin("0xc1", "1000000000"), coin("0xc2", "2000000000")}, "nextCursor": "0xc2", "hasNextPage": true},
+		map[string]any{"data": []any{coin("0xc3", "500000000")}, "nextCursor": "0xc3", "hasNextPage": true},
+		map[string]any{"data": []any{}, "nextCursor": nil, "hasNextPage": false},
+	)
+
+	gas, err := client.GetGas(context.Background(), suitest.ActiveAddress)
+	if err != nil {
+		t.Fatalf("GetGas: %v", err)
+	}
+	var total uint64
+	for _, coin := range gas {
+		total += coin.MistBalance
+	}
+	if len(gas) != 3 || total != 3500000000 {
+		t.Errorf("GetGas returned %d coins totalling %d MIST, want 3 totalling 3500000000", len(gas), total)
+	}
+	if n := len(stub.Requests()); n != 3 {
+		t.Errorf("GetGas made %d requests, want 3", n)
+	}
+}
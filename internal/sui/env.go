@@ -0,0 +1,243 @@
+package sui
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Env is a network environment from the user's client.yaml, prepared for per-call use
+type Env struct {
+	Alias string
+	// RPC is the fullnode URL the environment points at
+	RPC string
+	// Sender is the address that signs, when it differs from the active address
+	Sender string
+	// ClientConfig is a private copy of client.yaml with this environment active,
+	// passed to every client command via --client.config
+	ClientConfig string
+}
+
+type envKey struct{}
+
+// WithEnv returns a context under which the Client runs commands against env
+func WithEnv(ctx context.Context, env Env) context.Context {
+	return context.WithValue(ctx, envKey{}, env)
+}
+
+// EnvFromContext returns the environment selected for the call with WithEnv, if any
+func EnvFromContext(ctx context.Context) (Env, bool) {
+	env, ok := ctx.Value(envKey{}).(Env)
+	return env, ok
+}
+
+// Environments derives one client config per environment, and per sender on
+// demand, from the user's client.yaml, so calls can target different networks
+// and sign with different addresses concurrently without ever switching the
+// shared active env or address.
+type Environments struct {
+	source string
+	dir    string
+
+	mu      sync.Mutex
+	modTime time.Time
+	// clientConfig is the parsed source; activeEnv is its active_env
+	clientConfig map[string]any
+	activeEnv    string
+	envs         map[string]Env
+	// senders caches the per-sender copies by env alias and address
+	senders map[[2]string]Env
+}
+
+// NewEnvironments reads environments from the client config at source and writes
+// the per-environment copies under dir
+func NewEnvironments(source, dir string) *Environments {
+	return &Environments{source: source, dir: dir}
+}
+
+// Source returns the path of the shared client config
+func (e *Environments) Source() string {
+	return e.source
+}
+
+// Resolve returns the named environment, regenerating the derived configs when client.yaml has changed
+func (e *Environments) Resolve(alias string) (Env, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err := e.refresh(); err != nil {
+		return Env{}, err
+	}
+	return e.lookup(alias)
+}
+
+// Active returns the environment that is active in client.yaml
+func (e *Environments) Active() (Env, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err := e.refresh(); err != nil {
+		return Env{}, err
+	}
+	return e.lookup(e.activeEnv)
+}
+
+// ResolveSender returns a copy of the named environment that signs with address.
+// An empty alias selects the active env of client.yaml.
+func (e *Environments) ResolveSender(alias, address string) (Env, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err := e.refresh(); err != nil {
+		return Env{}, err
+	}
+	if alias == "" {
+		alias = e.activeEnv
+	}
+	env, err := e.lookup(alias)
+	if err != nil {
+		return Env{}, err
+	}
+
+	key := [2]string{alias, address}
+	if sender, ok := e.senders[key]; ok {
+		return sender, nil
+	}
+	path := filepath.Join(e.dir, pathSegment(alias), "senders", pathSegment(address), "client.yaml")
+	if err := e.write(path, alias, address); err != nil {
+		return Env{}, err
+	}
+	env.Sender, env.ClientConfig = address, path
+	e.senders[key] = env
+	return env, nil
+}
+
+// refresh re-reads client.yaml and rewrites the per-environment copies when it has changed
+func (e *Environments) refresh() error {
+	info, err := os.Stat(e.source)
+	if err != nil {
+		return fmt.Errorf("cannot read sui client config: %w", err)
+	}
+	if e.envs != nil && info.ModTime().Equal(e.modTime) {
+		return nil
+	}
+	if err := e.derive(); err != nil {
+		return err
+	}
+	e.modTime = info.ModTime()
+	return nil
+}
+
+// lookup returns a derived environment by alias
+func (e *Environments) lookup(alias string) (Env, error) {
+	env, ok := e.envs[alias]
+	if !ok {
+		known := make([]string, 0, len(e.envs))
+		for name := range e.envs {
+			known = append(known, name)
+		}
+		slices.Sort(known)
+		return Env{}, fmt.Errorf("unknown env %q (configured envs: %v)", alias, known)
+	}
+	return env, nil
+}
+
+// derive writes a copy of client.yaml per environment with active_env set to it.
+// All other settings, including the keystore and active address, are kept as they are.
+func (e *Environments) derive() error {
+	data, err := os.ReadFile(e.source)
+	if err != nil {
+		return fmt.Errorf("cannot read sui client config: %w", err)
+	}
+	var clientConfig map[string]any
+	if err := yaml.Unmarshal(data, &clientConfig); err != nil {
+		return fmt.Errorf("invalid sui client config %s: %w", e.source, err)
+	}
+	// The copies live elsewhere, so a relative keystore path must be anchored to the original
+	if keystore, ok := clientConfig["keystore"].(map[string]any); ok {
+		if file, ok := keystore["File"].(string); ok && !filepath.IsAbs(file) {
+			keystore["File"] = filepath.Join(filepath.Dir(e.source), file)
+		}
+	}
+	e.clientConfig = clientConfig
+	e.activeEnv, _ = clientConfig["active_env"].(string)
+	e.senders = make(map[[2]string]Env)
+
+	list, _ := clientConfig["envs"].([]any)
+	e.envs = make(map[string]Env, len(list))
+	for _, item := range list {
+		entry, _ := item.(map[string]any)
+		alias, _ := entry["alias"].(string)
+		if alias == "" {
+			continue
+		}
+		rpc, _ := entry["rpc"].(string)
+
+		path := filepath.Join(e.dir, pathSegment(alias), "client.yaml")
+		if err := e.write(path, alias, ""); err != nil {
+			e.envs = nil
+			return err
+		}
+		e.envs[alias] = Env{Alias: alias, RPC: rpc, ClientConfig: path}
+	}
+	return nil
+}
+
+// write saves a copy of the parsed client.yaml to path with active_env set to
+// alias and, unless address is empty, active_address set to address
+func (e *Environments) write(path, alias, address string) error {
+	clientConfig := maps.Clone(e.clientConfig)
+	clientConfig["active_env"] = alias
+	if address != "" {
+		clientConfig["active_address"] = address
+	}
+	out, err := yaml.Marshal(clientConfig)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create env config directory: %w", err)
+	}
+
+	// sui processes of calls in flight may be reading the previous copy, so the
+	// new one is written alongside and renamed over it
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".client-*.yaml")
+	if err != nil {
+		return fmt.Errorf("failed to write env config: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(out); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write env config: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write env config: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write env config: %w", err)
+	}
+	return nil
+}
+
+// pathSegment escapes an env alias or address into a single directory name.
+// Distinct aliases map to distinct names, and none can climb out of the
+// parent directory, whatever separators or dots the alias contains.
+func pathSegment(s string) string {
+	s = url.PathEscape(s)
+	if strings.HasPrefix(s, ".") {
+		s = "%2E" + s[1:]
+	}
+	if s == "" {
+		s = "%00"
+	}
+	return s
+}
@@ -0,0 +1,146 @@
+package sui_test
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/krli/go-sui-mcp/internal/sui"
+	"gopkg.in/yaml.v3"
+)
+
+// writeClientConfig writes a client.yaml with an env per alias
+func writeClientConfig(t *testing.T, path string, aliases ...string) {
+	t.Helper()
+	envs := make([]map[string]any, 0, len(aliases))
+	for _, alias := range aliases {
+		envs = append(envs, map[string]any{"alias": alias, "rpc": "https://" + alias + ".example"})
+	}
+	data, err := yaml.Marshal(map[string]any{
+		"keystore":       map[string]any{"File": "sui.keystore"},
+		"envs":           envs,
+		"active_env":     aliases[0],
+		"active_address": "0x1",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// readActive returns the active_env and active_address of a derived client config
+func readActive(t *testing.T, path string) (env, address string) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var cfg struct {
+		ActiveEnv     string `yaml:"active_env"`
+		ActiveAddress string `yaml:"active_address"`
+	}
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		t.Fatal(err)
+	}
+	return cfg.ActiveEnv, cfg.ActiveAddress
+}
+
+// within reports whether path lies strictly inside dir
+func within(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != "." && !strings.HasPrefix(rel, "..")
+}
+
+func TestEnvironmentAliasesStayInDir(t *testing.T) {
+	root := t.TempDir()
+	source := filepath.Join(root, "client.yaml")
+	dir := filepath.Join(root, "envs")
+	aliases := []string{"testnet", "../escape", "a/b", "a%2Fb", "b", "..", ".", ".hidden", `c\d`}
+	writeClientConfig(t, source, aliases...)
+	envs := sui.NewEnvironments(source, dir)
+
+	seen := map[string]string{}
+	for _, alias := range aliases {
+		t.Run(alias, func(t *testing.T) {
+			env, err := envs.Resolve(alias)
+			if err != nil {
+				t.Fatalf("Resolve: %v", err)
+			}
+			envDir := filepath.Dir(env.ClientConfig)
+			if !within(dir, env.ClientConfig) || filepath.Dir(envDir) != dir {
+				t.Errorf("config for %q is at %s, not in its own directory under %s", alias, env.ClientConfig, dir)
+			}
+			if other, ok := seen[envDir]; ok {
+				t.Errorf("%q and %q share %s", alias, other, envDir)
+			}
+			seen[envDir] = alias
+			if active, _ := readActive(t, env.ClientConfig); active != alias {
+				t.Errorf("active_env = %q, want %q", active, alias)
+			}
+
+			sender, err := envs.ResolveSender(alias, "../../"+alias)
+			if err != nil {
+				t.Fatalf("ResolveSender: %v", err)
+			}
+			if !within(envDir, sender.ClientConfig) {
+				t.Errorf("sender config for %q is at %s, outside %s", alias, sender.ClientConfig, envDir)
+			}
+			if active, address := readActive(t, sender.ClientConfig); active != alias || address != "../../"+alias {
+				t.Errorf("sender config has env %q address %q", active, address)
+			}
+		})
+	}
+}
+
+func TestEnvironmentsRewriteAtomically(t *testing.T) {
+	root := t.TempDir()
+	source := filepath.Join(root, "client.yaml")
+	dir := filepath.Join(root, "envs")
+	writeClientConfig(t, source, "testnet", "devnet")
+	envs := sui.NewEnvironments(source, dir)
+
+	before, err := envs.Resolve("testnet")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// client.yaml changes: the derived copies are regenerated in place
+	writeClientConfig(t, source, "devnet", "testnet", "mainnet")
+	later := time.Now().Add(time.Second)
+	if err := os.Chtimes(source, later, later); err != nil {
+		t.Fatal(err)
+	}
+	after, err := envs.Resolve("testnet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after.ClientConfig != before.ClientConfig {
+		t.Errorf("config moved from %s to %s", before.ClientConfig, after.ClientConfig)
+	}
+	if _, err := envs.Resolve("mainnet"); err != nil {
+		t.Errorf("new env not picked up: %v", err)
+	}
+
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && d.Name() != "client.yaml" {
+			t.Errorf("temporary file left behind: %s", path)
+		}
+		if !d.IsDir() {
+			if info, err := d.Info(); err == nil && info.Mode().Perm() != 0o600 {
+				t.Errorf("%s has mode %v, want 0600", path, info.Mode().Perm())
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
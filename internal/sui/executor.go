@@ -0,0 +1,51 @@
+package sui
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"time"
+)
+
+// waitDelay bounds how long we wait for a killed command's output pipes to close
+const waitDelay = 5 * time.Second
+
+// Executor runs an external command on behalf of the Client and returns its stdout.
+// It is the seam used to swap the real sui binary for a scripted fake in tests.
+type Executor interface {
+	Execute(ctx context.Context, name string, args ...string) (string, error)
+}
+
+// ProcessExecutor executes commands as local child processes
+type ProcessExecutor struct{}
+
+// NewProcessExecutor creates an executor that spawns real processes
+func NewProcessExecutor() *ProcessExecutor {
+	return &ProcessExecutor{}
+}
+
+// Execute runs the command and returns its stdout, wrapping stderr into the error on failure.
+// The process is killed if ctx is cancelled or its deadline passes.
+func (e *ProcessExecutor) Execute(ctx context.Context, name string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.WaitDelay = waitDelay
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		if errors.Is(ctxErr, context.DeadlineExceeded) {
+			return "", fmt.Errorf("sui command timed out: %w", ctxErr)
+		}
+		return "", fmt.Errorf("sui command cancelled: %w", ctxErr)
+	}
+	if err != nil {
+		return "", fmt.Errorf("error executing sui command: %w\nStderr: %s", err, stderr.String())
+	}
+
+	return stdout.String(), nil
+}
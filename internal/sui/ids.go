@@ -0,0 +1,68 @@
+package sui
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+)
+
+// addressHexLen is the number of hex digits in a 32-byte address or object ID
+const addressHexLen = 64
+
+// NormalizeAddress validates a 0x-prefixed address or object ID and returns
+// it in canonical form: lower case and zero-padded to 32 bytes, so that the
+// short form 0x2 becomes 0x000…0002
+func NormalizeAddress(s string) (string, error) {
+	hex, ok := strings.CutPrefix(strings.ToLower(s), "0x")
+	switch {
+	case !ok:
+		return "", fmt.Errorf("%q must start with 0x", s)
+	case hex == "":
+		return "", fmt.Errorf("%q has no hex digits", s)
+	case len(hex) > addressHexLen:
+		return "", fmt.Errorf("%q is longer than 32 bytes", s)
+	}
+	for _, r := range hex {
+		if !strings.ContainsRune("0123456789abcdef", r) {
+			return "", fmt.Errorf("%q contains the non-hex character %q", s, r)
+		}
+	}
+	return "0x" + strings.Repeat("0", addressHexLen-len(hex)) + hex, nil
+}
+
+// namePattern matches SuiNS names in either form: alice.sui, sub.alice.sui or @alice, alice@sub
+var namePattern = regexp.MustCompile(`^(?:[a-z0-9-]+\.)+sui$|^(?:[a-z0-9-]+(?:\.[a-z0-9-]+)*)?@[a-z0-9-]+$`)
+
+// IsName reports whether s looks like a SuiNS name rather than an address or alias
+func IsName(s string) bool {
+	return namePattern.MatchString(strings.ToLower(s))
+}
+
+// base58Alphabet is the Bitcoin alphabet Sui uses for transaction digests
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// ValidateDigest checks that s is a base58 transaction digest of 32 bytes
+func ValidateDigest(s string) error {
+	if s == "" {
+		return errors.New("digest is empty")
+	}
+	n := new(big.Int)
+	zeros := 0
+	for i, r := range s {
+		digit := strings.IndexRune(base58Alphabet, r)
+		if digit < 0 {
+			return fmt.Errorf("%q contains the non-base58 character %q", s, r)
+		}
+		if digit == 0 && i == zeros {
+			zeros++
+		}
+		n.Mul(n, big.NewInt(58))
+		n.Add(n, big.NewInt(int64(digit)))
+	}
+	if size := zeros + len(n.Bytes()); size != 32 {
+		return fmt.Errorf("%q decodes to %d bytes, not 32", s, size)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package sui_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/krli/go-sui-mcp/internal/sui"
+	"github.com/krli/go-sui-mcp/internal/sui/suitest"
+)
+
+func TestNormalizeAddress(t *testing.T) {
+	tests := []struct {
+		in, want string
+		wantErr  string
+	}{
+		{in: "0x2", want: "0x" + strings.Repeat("0", 63) + "2"},
+		{in: "0X" + strings.ToUpper(suitest.ActiveAddress[2:]), want: suitest.ActiveAddress},
+		{in: suitest.ActiveAddress, want: suitest.ActiveAddress},
+		{in: "0x" + strings.Repeat("f", 65), wantErr: "longer than 32 bytes"},
+		{in: "2", wantErr: "must start with 0x"},
+		{in: "-0x2", wantErr: "must start with 0x"},
+		{in: "0x", wantErr: "no hex digits"},
+		{in: "0x12g4", wantErr: `non-hex character 'g'`},
+		{in: "0x12 4", wantErr: `non-hex character ' '`},
+	}
+	for _, tc := range tests {
+		got, err := sui.NormalizeAddress(tc.in)
+		switch {
+		case tc.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tc.wantErr)):
+			t.Errorf("NormalizeAddress(%q) error = %v, want %q", tc.in, err, tc.wantErr)
+		case tc.wantErr == "" && (err != nil || got != tc.want):
+			t.Errorf("NormalizeAddress(%q) = %q, %v, want %q", tc.in, got, err, tc.want)
+		}
+	}
+}
+
+func TestValidateDigest(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		wantErr string
+	}{
+		{name: "recorded digest", in: suitest.TxDigest},
+		// 32 zero bytes encode as 32 '1's
+		{name: "all zero bytes", in: strings.Repeat("1", 32)},
+		{name: "empty", in: "", wantErr: "empty"},
+		{name: "zero is not base58", in: "0" + suitest.TxDigest[1:], wantErr: `non-base58 character '0'`},
+		{name: "capital O is not base58", in: "O" + suitest.TxDigest[1:], wantErr: `non-base58 character 'O'`},
+		{name: "capital I is not base58", in: "I" + suitest.TxDigest[1:], wantErr: `non-base58 character 'I'`},
+		{name: "lower-case l is not base58", in: "l" + suitest.TxDigest[1:], wantErr: `non-base58 character 'l'`},
+		{name: "leading dash", in: "-" + suitest.TxDigest[1:], wantErr: `non-base58 character '-'`},
+		{name: "hex", in: "0x" + strings.Repeat("a", 64), wantErr: "non-base58"},
+		{name: "too short", in: suitest.TxDigest[:20], wantErr: "not 32"},
+		{name: "too long", in: suitest.TxDigest + "zz", wantErr: "not 32"},
+		{name: "one zero byte too many", in: strings.Repeat("1", 33), wantErr: "decodes to 33 bytes"},
+	}
+	for _, tc := range tests {
+		err := sui.ValidateDigest(tc.in)
+		switch {
+		case tc.wantErr == "" && err != nil:
+			t.Errorf("%s: ValidateDigest(%q) = %v", tc.name, tc.in, err)
+		case tc.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tc.wantErr)):
+			t.Errorf("%s: ValidateDigest(%q) = %v, want %q", tc.name, tc.in, err, tc.wantErr)
+		}
+	}
+}
+
+func TestIsName(t *testing.T) {
+	tests := []struct {
+		in   string
+		want bool
+	}{
+		{"alice.sui", true},
+		{"sub.alice.sui", true},
+		{"Alice.SUI", true},
+		{"@alice", true},
+		{"sub@alice", true},
+		{"alice", false},
+		{"0x2", false},
+		{"alice.eth", false},
+		{".sui", false},
+		{"alice@", false},
+	}
+	for _, tc := range tests {
+		if got := sui.IsName(tc.in); got != tc.want {
+			t.Errorf("IsName(%q) = %v, want %v", tc.in, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package sui
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// mistPerSui is the number of MIST in one SUI
+const mistPerSui = 1_000_000_000
+
+// decodeJSONOutput decodes CLI --json output into v, skipping any banner the
+// CLI prints before the JSON document
+func decodeJSONOutput(output string, v any) error {
+	start := strings.IndexAny(output, "[{")
+	if start < 0 {
+		return fmt.Errorf("no JSON in output: %q", strings.TrimSpace(output))
+	}
+	return json.Unmarshal([]byte(output[start:]), v)
+}
+
+// decodeObject decodes either a bare object or a {"data": ..., "error": ...} object response
+func decodeObject(raw json.RawMessage) (*ObjectData, error) {
+	var wrapped struct {
+		Data  *ObjectData     `json:"data"`
+		Error json.RawMessage `json:"error"`
+	}
+	if err := json.Unmarshal(raw, &wrapped); err != nil {
+		return nil, err
+	}
+	if wrapped.Data != nil {
+		return wrapped.Data, nil
+	}
+	if len(wrapped.Error) > 0 && !bytes.Equal(wrapped.Error, []byte("null")) {
+		return nil, fmt.Errorf("object not available: %s", wrapped.Error)
+	}
+
+	var obj ObjectData
+	if err := json.Unmarshal(raw, &obj); err != nil {
+		return nil, err
+	}
+	if obj.ObjectID == "" {
+		return nil, errors.New("response contains no object")
+	}
+	return &obj, nil
+}
+
+// decodeObjects decodes a list of bare or wrapped objects
+func decodeObjects(raw json.RawMessage) ([]ObjectData, error) {
+	var items []json.RawMessage
+	if err := json.Unmarshal(raw, &items); err != nil {
+		return nil, err
+	}
+	objects := make([]ObjectData, 0, len(items))
+	for _, item := range items {
+		obj, err := decodeObject(item)
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, *obj)
+	}
+	return objects, nil
+}
+
+// cliBalanceGroup is one coin type in the output of `sui client balance --json`
+type cliBalanceGroup struct {
+	meta  *CoinMetadata
+	coins []Coin
+}
+
+// decodeCLIBalanceGroups decodes `sui client balance --json`, which is shaped
+// [[[metadata|null, [coin, ...]], ...], hasMore]
+func decodeCLIBalanceGroups(output string) ([]cliBalanceGroup, error) {
+	var top []json.RawMessage
+	if err := decodeJSONOutput(output, &top); err != nil {
+		return nil, err
+	}
+	if len(top) == 0 {
+		return nil, errors.New("empty balance output")
+	}
+
+	var raw [][]json.RawMessage
+	if err := json.Unmarshal(top[0], &raw); err != nil {
+		return nil, err
+	}
+
+	groups := make([]cliBalanceGroup, 0, len(raw))
+	for _, entry := range raw {
+		if len(entry) != 2 {
+			return nil, fmt.Errorf("unexpected balance entry with %d elements", len(entry))
+		}
+		var group cliBalanceGroup
+		if err := json.Unmarshal(entry[0], &group.meta); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(entry[1], &group.coins); err != nil {
+			return nil, err
+		}
+		groups = append(groups, group)
+	}
+	return groups, nil
+}
+
+// parseCLIBalances decodes `sui client balance --json` into per coin type totals
+func parseCLIBalances(output string) ([]Balance, error) {
+	groups, err := decodeCLIBalanceGroups(output)
+	if err != nil {
+		return nil, err
+	}
+
+	balances := make([]Balance, 0, len(groups))
+	for _, group := range groups {
+		coins := group.coins
+		if len(coins) == 0 {
+			continue
+		}
+
+		total := new(big.Int)
+		for _, coin := range coins {
+			amount, ok := new(big.Int).SetString(coin.Balance, 10)
+			if !ok {
+				return nil, fmt.Errorf("invalid balance %q for coin %s", coin.Balance, coin.CoinObjectID)
+			}
+			total.Add(total, amount)
+		}
+
+		balance := Balance{
+			CoinType:        coins[0].CoinType,
+			CoinObjectCount: len(coins),
+			TotalBalance:    total.String(),
+		}
+		if group.meta != nil {
+			balance.Symbol = group.meta.Symbol
+			balance.Decimals = group.meta.Decimals
+		}
+		balances = append(balances, balance)
+	}
+	return balances, nil
+}
+
+// parseCLICoins decodes `sui client balance --with-coins --json` into the individual coins
+func parseCLICoins(output string) ([]Coin, error) {
+	groups, err := decodeCLIBalanceGroups(output)
+	if err != nil {
+		return nil, err
+	}
+	var coins []Coin
+	for _, group := range groups {
+		coins = append(coins, group.coins...)
+	}
+	return coins, nil
+}
+
+// coinsToGasCoins converts SUI coins into the gas coin shape printed by `sui client gas`
+func coinsToGasCoins(coins []Coin) ([]GasCoin, error) {
+	gasCoins := make([]GasCoin, 0, len(coins))
+	for _, coin := range coins {
+		mist, err := strconv.ParseUint(coin.Balance, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid balance %q for coin %s: %w", coin.Balance, coin.CoinObjectID, err)
+		}
+		gasCoins = append(gasCoins, GasCoin{
+			GasCoinID:   coin.CoinObjectID,
+			MistBalance: mist,
+			SuiBalance:  FormatMist(mist),
+		})
+	}
+	return gasCoins, nil
+}
+
+// FormatMist renders a MIST amount as a decimal SUI string without trailing zeros
+func FormatMist(mist uint64) string {
+	whole := mist / mistPerSui
+	frac := mist % mistPerSui
+	if frac == 0 {
+		return strconv.FormatUint(whole, 10)
+	}
+	return strings.TrimRight(fmt.Sprintf("%d.%09d", whole, frac), "0")
+}
+
+// lastLine returns the last non-empty line of output, which is where the CLI
+// prints serialized transaction bytes after any warnings
+func lastLine(output string) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
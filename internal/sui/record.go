@@ -0,0 +1,61 @@
+package sui
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"slices"
+	"sync"
+)
+
+// Command describes one sui invocation made while serving a request
+type Command struct {
+	Argv []string `json:"argv"`
+	// ExitCode is the process exit status; -1 when the process did not exit normally or never ran
+	ExitCode int `json:"exitCode"`
+}
+
+// CommandLog collects the commands run under a context created by WithCommandLog
+type CommandLog struct {
+	mu       sync.Mutex
+	commands []Command
+}
+
+type commandLogKey struct{}
+
+// WithCommandLog returns a context under which every command the Client runs is recorded in the returned log
+func WithCommandLog(ctx context.Context) (context.Context, *CommandLog) {
+	log := &CommandLog{}
+	return context.WithValue(ctx, commandLogKey{}, log), log
+}
+
+// Commands returns the commands recorded so far
+func (l *CommandLog) Commands() []Command {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return slices.Clone(l.commands)
+}
+
+// record appends a finished command to the log carried by ctx, if any
+func record(ctx context.Context, name string, args []string, err error) {
+	log, ok := ctx.Value(commandLogKey{}).(*CommandLog)
+	if !ok {
+		return
+	}
+
+	exitCode := 0
+	if err != nil {
+		exitCode = -1
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			exitCode = exitErr.ExitCode()
+		}
+	}
+
+	log.mu.Lock()
+	defer log.mu.Unlock()
+	log.commands = append(log.commands, Command{
+		Argv:     append([]string{name}, args...),
+		ExitCode: exitCode,
+	})
+}
@@ -0,0 +1,250 @@
+package sui
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// defaultRPCTimeout is used when no *http.Client is supplied
+const defaultRPCTimeout = 30 * time.Second
+
+// RPCClient talks to a Sui fullnode over JSON-RPC 2.0
+type RPCClient struct {
+	url        string
+	httpClient *http.Client
+	nextID     atomic.Uint64
+}
+
+// RPCError is an error object returned by the fullnode
+type RPCError struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("sui rpc error %d: %s", e.Code, e.Message)
+}
+
+type rpcRequest struct {
+	JSONRPC string `json:"jsonrpc"`
+	ID      uint64 `json:"id"`
+	Method  string `json:"method"`
+	Params  []any  `json:"params"`
+}
+
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      uint64          `json:"id"`
+	Result  json.RawMessage `json:"result"`
+	Error   *RPCError       `json:"error"`
+}
+
+// NewRPCClient creates a JSON-RPC client for the fullnode at url. A nil
+// httpClient gets a client with a default timeout.
+func NewRPCClient(url string, httpClient *http.Client) *RPCClient {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: defaultRPCTimeout}
+	}
+	return &RPCClient{
+		url:        url,
+		httpClient: httpClient,
+	}
+}
+
+// Call invokes a JSON-RPC method and returns the raw result
+func (r *RPCClient) Call(ctx context.Context, method string, params ...any) (json.RawMessage, error) {
+	if params == nil {
+		params = []any{}
+	}
+	body, err := json.Marshal(rpcRequest{
+		JSONRPC: "2.0",
+		ID:      r.nextID.Add(1),
+		Method:  method,
+		Params:  params,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s request: %w", method, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("sui rpc %s failed: %w", method, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s response: %w", method, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("sui rpc %s failed: %s: %s", method, resp.Status, bytes.TrimSpace(data))
+	}
+
+	var rpcResp rpcResponse
+	if err := json.Unmarshal(data, &rpcResp); err != nil {
+		return nil, fmt.Errorf("failed to decode %s response: %w", method, err)
+	}
+	if rpcResp.Error != nil {
+		return nil, rpcResp.Error
+	}
+	return rpcResp.Result, nil
+}
+
+// ============ Read API ============
+
+// objectDataOptions requests everything the CLI shows for an object
+var objectDataOptions = map[string]bool{
+	"showType":                true,
+	"showOwner":               true,
+	"showPreviousTransaction": true,
+	"showStorageRebate":       true,
+	"showContent":             true,
+	"showDisplay":             true,
+}
+
+// txBlockOptions requests everything the CLI shows for a transaction block
+var txBlockOptions = map[string]bool{
+	"showInput":          true,
+	"showEffects":        true,
+	"showEvents":         true,
+	"showBalanceChanges": true,
+	"showObjectChanges":  true,
+}
+
+// GetObject calls sui_getObject
+func (r *RPCClient) GetObject(ctx context.Context, objectID string) (json.RawMessage, error) {
+	return r.Call(ctx, "sui_getObject", objectID, objectDataOptions)
+}
+
+// GetOwnedObjects calls suix_getOwnedObjects for a page of objects owned by
+// address, starting after cursor (the first page when empty)
+func (r *RPCClient) GetOwnedObjects(ctx context.Context, address string, cursor string) (json.RawMessage, error) {
+	var cur any
+	if cursor != "" {
+		cur = cursor
+	}
+	query := map[string]any{"options": objectDataOptions}
+	return r.Call(ctx, "suix_getOwnedObjects", address, query, cur, nil)
+}
+
+// GetAllBalances calls suix_getAllBalances
+func (r *RPCClient) GetAllBalances(ctx context.Context, address string) (json.RawMessage, error) {
+	return r.Call(ctx, "suix_getAllBalances", address)
+}
+
+// GetCoins calls suix_getCoins for a page of coins of coinType (SUI when empty),
+// starting after cursor (the first page when empty)
+func (r *RPCClient) GetCoins(ctx context.Context, address string, coinType string, cursor string) (json.RawMessage, error) {
+	var ct, cur any
+	if coinType != "" {
+		ct = coinType
+	}
+	if cursor != "" {
+		cur = cursor
+	}
+	return r.Call(ctx, "suix_getCoins", address, ct, cur, nil)
+}
+
+// GetCoinMetadata calls suix_getCoinMetadata; the result is null for unknown coin types
+func (r *RPCClient) GetCoinMetadata(ctx context.Context, coinType string) (json.RawMessage, error) {
+	return r.Call(ctx, "suix_getCoinMetadata", coinType)
+}
+
+// ResolveNameServiceAddress calls suix_resolveNameServiceAddress; the result is null for unregistered names
+func (r *RPCClient) ResolveNameServiceAddress(ctx context.Context, name string) (json.RawMessage, error) {
+	return r.Call(ctx, "suix_resolveNameServiceAddress", name)
+}
+
+// ResolveNameServiceNames calls suix_resolveNameServiceNames for the first page of names pointing at address
+func (r *RPCClient) ResolveNameServiceNames(ctx context.Context, address string) (json.RawMessage, error) {
+	return r.Call(ctx, "suix_resolveNameServiceNames", address, nil, nil)
+}
+
+// GetTransactionBlock calls sui_getTransactionBlock
+func (r *RPCClient) GetTransactionBlock(ctx context.Context, digest string) (json.RawMessage, error) {
+	return r.Call(ctx, "sui_getTransactionBlock", digest, txBlockOptions)
+}
+
+// txQueryOptions requests what a compact transaction listing needs
+var txQueryOptions = map[string]bool{
+	"showInput":          true,
+	"showEffects":        true,
+	"showBalanceChanges": true,
+}
+
+// QueryTransactionBlocks calls suix_queryTransactionBlocks for a page of up to
+// limit transactions matching filter, starting after cursor (the first page when empty)
+func (r *RPCClient) QueryTransactionBlocks(ctx context.Context, filter map[string]any, cursor string, limit int, descending bool) (json.RawMessage, error) {
+	var cur any
+	if cursor != "" {
+		cur = cursor
+	}
+	query := map[string]any{"filter": filter, "options": txQueryOptions}
+	return r.Call(ctx, "suix_queryTransactionBlocks", query, cur, limit, descending)
+}
+
+// QueryEvents calls suix_queryEvents for a page of up to limit events matching
+// filter, starting after cursor (the first page when nil)
+func (r *RPCClient) QueryEvents(ctx context.Context, filter map[string]any, cursor *EventID, limit int, descending bool) (json.RawMessage, error) {
+	return r.Call(ctx, "suix_queryEvents", filter, cursor, limit, descending)
+}
+
+// GetLatestCheckpointSequenceNumber calls sui_getLatestCheckpointSequenceNumber
+func (r *RPCClient) GetLatestCheckpointSequenceNumber(ctx context.Context) (json.RawMessage, error) {
+	return r.Call(ctx, "sui_getLatestCheckpointSequenceNumber")
+}
+
+// GetNormalizedMoveModule calls sui_getNormalizedMoveModule for the structs and
+// functions of module in package packageID
+func (r *RPCClient) GetNormalizedMoveModule(ctx context.Context, packageID string, module string) (json.RawMessage, error) {
+	return r.Call(ctx, "sui_getNormalizedMoveModule", packageID, module)
+}
+
+// GetChainIdentifier calls sui_getChainIdentifier
+func (r *RPCClient) GetChainIdentifier(ctx context.Context) (json.RawMessage, error) {
+	return r.Call(ctx, "sui_getChainIdentifier")
+}
+
+// GetDynamicFields calls suix_getDynamicFields for the first page of fields of parentObjectID
+func (r *RPCClient) GetDynamicFields(ctx context.Context, parentObjectID string) (json.RawMessage, error) {
+	return r.Call(ctx, "suix_getDynamicFields", parentObjectID, nil, nil)
+}
+
+// GetDynamicFieldObject calls suix_getDynamicFieldObject; name is the JSON
+// encoded DynamicFieldName, e.g. {"type":"u64","value":"1"}
+func (r *RPCClient) GetDynamicFieldObject(ctx context.Context, parentObjectID string, name json.RawMessage) (json.RawMessage, error) {
+	return r.Call(ctx, "suix_getDynamicFieldObject", parentObjectID, name)
+}
+
+// ============ Write API ============
+
+// DryRunTransactionBlock calls sui_dryRunTransactionBlock with base64 encoded unsigned transaction bytes
+func (r *RPCClient) DryRunTransactionBlock(ctx context.Context, txBytes string) (json.RawMessage, error) {
+	return r.Call(ctx, "sui_dryRunTransactionBlock", txBytes)
+}
+
+// indentJSON renders a raw RPC result the way the CLI prints --json output
+func indentJSON(raw json.RawMessage, err error) (string, error) {
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, raw, "", "  "); err != nil {
+		return string(raw), nil
+	}
+	buf.WriteByte('\n')
+	return buf.String(), nil
+}
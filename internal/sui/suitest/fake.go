@@ -0,0 +1,206 @@
+// Package suitest provides a scriptable fake sui executor and recorded CLI
+// fixtures so the client and service layers can be exercised without a real
+// sui binary or a running chain.
+package suitest
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/krli/go-sui-mcp/internal/sui"
+)
+
+//go:embed fixtures/*
+var fixtures embed.FS
+
+// Executable is the sui executable path of clients created with NewClient
+const Executable = "sui"
+
+// Identifiers that appear in the recorded fixtures
+const (
+	// ActiveAddress is the active address of the recorded CLI
+	ActiveAddress = "0x7d20dcdb2bca4f508ea9613994683eb4e76e9c4ed371169677c1be02aaf0b58e"
+	// OtherAddress is the second address managed by the recorded CLI
+	OtherAddress = "0x398807039e4e99793c63a3a8b315c32c7878663e5f7ca0e9e19d3dddcbfb04f3"
+	// CoinObjectID is the larger of the two recorded SUI coins
+	CoinObjectID = "0x8bf92f132a6a9bd4ab32b083bcc0d54fc81bcd6fd07c0742c87e5c56e354cb04"
+	// SmallCoinObjectID is the smaller of the two recorded SUI coins
+	SmallCoinObjectID = "0x0b5f2c9e4f6c1f4e0c4f7f7a2d9b8e1c3a5d7f9b1c3e5a7d9f1b3c5e7a9d1f3b"
+	// TxDigest is the digest of the recorded transaction
+	TxDigest = "2s7G1dNpVSfEM7uU1Zxy6aRqmYfgnDPv8cmFkg3aV89m"
+)
+
+// Response is the scripted result of a single command invocation
+type Response struct {
+	Output string
+	Err    error
+	// Block, if set, makes the command hang until it is closed or the context is done
+	Block <-chan struct{}
+	// Killed, if set, receives the context error when a blocked command is
+	// stopped by its context; it must have room for every such command
+	Killed chan<- error
+}
+
+// Call records a command the fake executor received
+type Call struct {
+	Name string
+	Args []string
+}
+
+// FakeExecutor is a sui.Executor that answers from scripted responses instead of spawning processes
+type FakeExecutor struct {
+	mu        sync.Mutex
+	responses map[string]Response
+	fallback  *Response
+	calls     []Call
+}
+
+// NewFakeExecutor creates an empty fake executor; unscripted commands return an error
+func NewFakeExecutor() *FakeExecutor {
+	return &FakeExecutor{
+		responses: make(map[string]Response),
+	}
+}
+
+// NewRecordedExecutor creates a fake executor pre-scripted with the recorded
+// fixtures for every read-only command the service exposes
+func NewRecordedExecutor() *FakeExecutor {
+	f := NewFakeExecutor()
+	f.OnFixture("version.txt", "--version")
+	f.OnProgram(Response{Output: Fixture("which.txt")}, "which", Executable)
+	f.OnFixture("balance.json", "client", "balance", "--json")
+	f.OnFixture("balance.json", "client", "balance", "--coin-type", sui.SUICoinType, "--with-coins", "--json")
+	f.OnFixture("objects.json", "client", "objects", "--json")
+	f.OnFixture("object.json", "client", "object", CoinObjectID, "--json")
+	f.OnFixture("tx_block.json", "client", "tx-block", TxDigest, "--json")
+	f.OnFixture("active_address.txt", "client", "active-address")
+	f.OnFixture("addresses.txt", "client", "addresses")
+	f.OnFixture("addresses.json", "client", "addresses", "--json")
+	f.OnFixture("active_env.txt", "client", "active-env")
+	f.OnFixture("envs.txt", "client", "envs")
+	f.OnFixture("chain_identifier.txt", "client", "chain-identifier")
+	f.OnFixture("gas.json", "client", "gas", "--json")
+	f.OnFixture("keytool_list.txt", "keytool", "list")
+	return f
+}
+
+// NewClient wires a sui.Client to the given fake executor
+func NewClient(f *FakeExecutor) *sui.Client {
+	return sui.NewClientWithExecutor(Executable, f)
+}
+
+// On scripts the output returned for the exact argument list of the sui executable
+func (f *FakeExecutor) On(output string, args ...string) *FakeExecutor {
+	return f.OnResponse(Response{Output: output}, args...)
+}
+
+// OnFixture scripts the contents of a recorded fixture for the exact argument list
+func (f *FakeExecutor) OnFixture(name string, args ...string) *FakeExecutor {
+	return f.On(Fixture(name), args...)
+}
+
+// OnError scripts a failure for the exact argument list
+func (f *FakeExecutor) OnError(err error, args ...string) *FakeExecutor {
+	return f.OnResponse(Response{Err: err}, args...)
+}
+
+// OnResponse scripts an arbitrary response for the exact argument list of the sui executable
+func (f *FakeExecutor) OnResponse(resp Response, args ...string) *FakeExecutor {
+	return f.OnProgram(resp, Executable, args...)
+}
+
+// OnProgram scripts a response for another program, such as which, run with
+// the exact argument list
+func (f *FakeExecutor) OnProgram(resp Response, name string, args ...string) *FakeExecutor {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.responses[key(name, args)] = resp
+	return f
+}
+
+// Fallback sets the response returned for commands that have not been scripted
+func (f *FakeExecutor) Fallback(resp Response) *FakeExecutor {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.fallback = &resp
+	return f
+}
+
+// Execute implements sui.Executor
+func (f *FakeExecutor) Execute(ctx context.Context, name string, args ...string) (string, error) {
+	resp, err := f.lookup(name, args)
+	if err != nil {
+		return "", err
+	}
+
+	if resp.Block != nil {
+		select {
+		case <-resp.Block:
+		case <-ctx.Done():
+			if resp.Killed != nil {
+				resp.Killed <- ctx.Err()
+			}
+			return "", ctx.Err()
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	return resp.Output, resp.Err
+}
+
+func (f *FakeExecutor) lookup(name string, args []string) (Response, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls = append(f.calls, Call{Name: name, Args: append([]string(nil), args...)})
+
+	if resp, ok := f.responses[key(name, args)]; ok {
+		return resp, nil
+	}
+	if f.fallback != nil {
+		return *f.fallback, nil
+	}
+	return Response{}, fmt.Errorf("suitest: unscripted command: %s %s", name, strings.Join(args, " "))
+}
+
+// Calls returns every command received so far, in order
+func (f *FakeExecutor) Calls() []Call {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Call(nil), f.calls...)
+}
+
+// LastCall returns the most recent command, or false if none was received
+func (f *FakeExecutor) LastCall() (Call, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.calls) == 0 {
+		return Call{}, false
+	}
+	return f.calls[len(f.calls)-1], true
+}
+
+// Reset forgets all recorded calls while keeping the scripted responses
+func (f *FakeExecutor) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = nil
+}
+
+// Fixture returns the contents of a recorded CLI output file, panicking if it does not exist
+func Fixture(name string) string {
+	data, err := fixtures.ReadFile("fixtures/" + name)
+	if err != nil {
+		panic(fmt.Sprintf("suitest: missing fixture %q: %v", name, err))
+	}
+	return string(data)
+}
+
+// key identifies a command by its full argv, program name included
+func key(name string, args []string) string {
+	return strings.Join(append([]string{name}, args...), "\x00")
+}
@@ -0,0 +1,142 @@
+package suitest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	"github.com/krli/go-sui-mcp/internal/sui"
+)
+
+// RPCRequest records a JSON-RPC call the stub received
+type RPCRequest struct {
+	Method string
+	Params []json.RawMessage
+}
+
+// RPCStub is an http.Handler that answers Sui JSON-RPC calls from scripted results
+type RPCStub struct {
+	mu       sync.Mutex
+	results  map[string]json.RawMessage
+	funcs    map[string]func(params []json.RawMessage) any
+	errors   map[string]*sui.RPCError
+	requests []RPCRequest
+}
+
+// NewRPCStub creates a stub with no scripted methods; unscripted methods return -32601
+func NewRPCStub() *RPCStub {
+	return &RPCStub{
+		results: make(map[string]json.RawMessage),
+		funcs:   make(map[string]func([]json.RawMessage) any),
+		errors:  make(map[string]*sui.RPCError),
+	}
+}
+
+// NewRecordedRPCStub creates a stub pre-scripted with the recorded fullnode fixtures
+func NewRecordedRPCStub() *RPCStub {
+	s := NewRPCStub()
+	s.OnFixture("sui_getObject", "rpc_get_object.json")
+	s.OnFixture("suix_getAllBalances", "rpc_get_all_balances.json")
+	s.OnFixture("suix_getCoins", "rpc_get_coins.json")
+	s.OnFixture("sui_getTransactionBlock", "rpc_get_transaction_block.json")
+	s.OnFixture("suix_queryTransactionBlocks", "rpc_query_transaction_blocks.json")
+	s.OnFixture("suix_queryEvents", "rpc_query_events.json")
+	s.OnFixture("sui_getNormalizedMoveModule", "rpc_get_normalized_move_module.json")
+	s.OnFixture("sui_dryRunTransactionBlock", "dry_run.json")
+	s.On("sui_getChainIdentifier", json.RawMessage(`"4c78adac"`))
+	return s
+}
+
+// NewRPCClient starts an httptest server for the stub and returns a client pointed at it.
+// Callers must Close the returned server.
+func NewRPCClient(stub *RPCStub) (*sui.RPCClient, *httptest.Server) {
+	srv := httptest.NewServer(stub)
+	return sui.NewRPCClient(srv.URL, srv.Client()), srv
+}
+
+// On scripts the result returned for a method; raw JSON is passed through unchanged
+func (s *RPCStub) On(method string, result any) *RPCStub {
+	raw, ok := result.(json.RawMessage)
+	if !ok {
+		var err error
+		if raw, err = json.Marshal(result); err != nil {
+			panic(fmt.Sprintf("suitest: cannot encode result for %s: %v", method, err))
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.results[method] = raw
+	return s
+}
+
+// OnFunc scripts a method whose result depends on its params, such as the
+// pages of a paginated method keyed by cursor; it takes precedence over On
+func (s *RPCStub) OnFunc(method string, fn func(params []json.RawMessage) any) *RPCStub {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.funcs[method] = fn
+	return s
+}
+
+// OnFixture scripts the contents of a recorded fixture as the result for a method
+func (s *RPCStub) OnFixture(method string, name string) *RPCStub {
+	return s.On(method, json.RawMessage(Fixture(name)))
+}
+
+// OnError scripts a JSON-RPC error for a method
+func (s *RPCStub) OnError(method string, code int, message string) *RPCStub {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.errors[method] = &sui.RPCError{Code: code, Message: message}
+	return s
+}
+
+// Requests returns every call received so far, in order
+func (s *RPCStub) Requests() []RPCRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]RPCRequest(nil), s.requests...)
+}
+
+// ServeHTTP implements http.Handler
+func (s *RPCStub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ID     json.RawMessage   `json:"id"`
+		Method string            `json:"method"`
+		Params []json.RawMessage `json:"params"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	s.requests = append(s.requests, RPCRequest{Method: req.Method, Params: req.Params})
+	result, ok := s.results[req.Method]
+	fn := s.funcs[req.Method]
+	rpcErr := s.errors[req.Method]
+	s.mu.Unlock()
+	if fn != nil {
+		raw, err := json.Marshal(fn(req.Params))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		result, ok = raw, true
+	}
+
+	resp := map[string]any{"jsonrpc": "2.0", "id": req.ID}
+	switch {
+	case rpcErr != nil:
+		resp["error"] = rpcErr
+	case ok:
+		resp["result"] = result
+	default:
+		resp["error"] = &sui.RPCError{Code: -32601, Message: "Method not found: " + req.Method}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
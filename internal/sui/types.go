@@ -0,0 +1,289 @@
+package sui
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SUICoinType is the fully qualified type of the native SUI coin
+const SUICoinType = "0x2::sui::SUI"
+
+// Version is an object version; the CLI and RPC encode it either as a number or a decimal string
+type Version uint64
+
+// UnmarshalJSON accepts both 42 and "42"
+func (v *Version) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		n, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid version %q: %w", s, err)
+		}
+		*v = Version(n)
+		return nil
+	}
+	var n uint64
+	if err := json.Unmarshal(data, &n); err != nil {
+		return fmt.Errorf("invalid version %s: %w", data, err)
+	}
+	*v = Version(n)
+	return nil
+}
+
+// AddressList is the output of sui client addresses --json
+type AddressList struct {
+	ActiveAddress string `json:"activeAddress"`
+	// Addresses holds an [alias, address] pair per key in the keystore
+	Addresses [][2]string `json:"addresses"`
+}
+
+// Contains reports whether address is managed by the keystore
+func (l *AddressList) Contains(address string) bool {
+	for _, entry := range l.Addresses {
+		if strings.EqualFold(entry[1], address) {
+			return true
+		}
+	}
+	return false
+}
+
+// Owner describes who owns an object, normalised from Sui's tagged enum encoding
+type Owner struct {
+	// Kind is one of "address", "object", "shared" or "immutable"
+	Kind                 string  `json:"kind"`
+	Address              string  `json:"address,omitempty"`
+	InitialSharedVersion Version `json:"initialSharedVersion,omitempty"`
+}
+
+// UnmarshalJSON decodes "Immutable", {"AddressOwner": ...}, {"ObjectOwner": ...},
+// {"Shared": {...}} and {"ConsensusAddressOwner": {...}}, as well as the normalised form
+func (o *Owner) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*o = Owner{Kind: "immutable"}
+		return nil
+	}
+
+	var raw struct {
+		AddressOwner string `json:"AddressOwner"`
+		ObjectOwner  string `json:"ObjectOwner"`
+		Shared       *struct {
+			InitialSharedVersion Version `json:"initial_shared_version"`
+		} `json:"Shared"`
+		ConsensusAddressOwner *struct {
+			Owner string `json:"owner"`
+		} `json:"ConsensusAddressOwner"`
+		Kind                 string  `json:"kind"`
+		Address              string  `json:"address"`
+		InitialSharedVersion Version `json:"initialSharedVersion"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("invalid owner %s: %w", data, err)
+	}
+
+	switch {
+	case raw.AddressOwner != "":
+		*o = Owner{Kind: "address", Address: raw.AddressOwner}
+	case raw.ObjectOwner != "":
+		*o = Owner{Kind: "object", Address: raw.ObjectOwner}
+	case raw.Shared != nil:
+		*o = Owner{Kind: "shared", InitialSharedVersion: raw.Shared.InitialSharedVersion}
+	case raw.ConsensusAddressOwner != nil:
+		*o = Owner{Kind: "address", Address: raw.ConsensusAddressOwner.Owner}
+	default:
+		*o = Owner{Kind: raw.Kind, Address: raw.Address, InitialSharedVersion: raw.InitialSharedVersion}
+	}
+	return nil
+}
+
+// String renders the owner for humans
+func (o Owner) String() string {
+	switch o.Kind {
+	case "address":
+		return "address " + o.Address
+	case "object":
+		return "object " + o.Address
+	case "shared":
+		return fmt.Sprintf("shared (since version %d)", o.InitialSharedVersion)
+	default:
+		return o.Kind
+	}
+}
+
+// Balance is the total balance an address holds of one coin type
+type Balance struct {
+	CoinType        string `json:"coinType"`
+	CoinObjectCount int    `json:"coinObjectCount"`
+	// TotalBalance is the raw u128 amount in the coin's smallest unit
+	TotalBalance string `json:"totalBalance"`
+	// Symbol and Decimals are filled in when coin metadata is known
+	Symbol   string `json:"symbol,omitempty"`
+	Decimals int    `json:"decimals,omitempty"`
+}
+
+// Coin is a single coin object
+type Coin struct {
+	CoinType            string  `json:"coinType"`
+	CoinObjectID        string  `json:"coinObjectId"`
+	Version             Version `json:"version"`
+	Digest              string  `json:"digest"`
+	Balance             string  `json:"balance"`
+	PreviousTransaction string  `json:"previousTransaction,omitempty"`
+}
+
+// CoinMetadata describes a coin type
+type CoinMetadata struct {
+	Decimals    int    `json:"decimals"`
+	Name        string `json:"name"`
+	Symbol      string `json:"symbol"`
+	Description string `json:"description,omitempty"`
+	IconURL     string `json:"iconUrl,omitempty"`
+	ID          string `json:"id,omitempty"`
+}
+
+// GasCoin is a SUI coin usable for gas payment
+type GasCoin struct {
+	GasCoinID   string `json:"gasCoinId"`
+	MistBalance uint64 `json:"mistBalance"`
+	SuiBalance  string `json:"suiBalance"`
+}
+
+// ObjectData is an on-chain object as returned by `sui client object --json` and sui_getObject
+type ObjectData struct {
+	ObjectID            string  `json:"objectId"`
+	Version             Version `json:"version"`
+	Digest              string  `json:"digest"`
+	Type                string  `json:"type,omitempty"`
+	Owner               *Owner  `json:"owner,omitempty"`
+	PreviousTransaction string  `json:"previousTransaction,omitempty"`
+	StorageRebate       string  `json:"storageRebate,omitempty"`
+	// Content holds the Move fields of the object as decoded JSON
+	Content map[string]any `json:"content,omitempty"`
+	Display map[string]any `json:"display,omitempty"`
+}
+
+// ObjectRef identifies a specific version of an object
+type ObjectRef struct {
+	ObjectID string  `json:"objectId"`
+	Version  Version `json:"version"`
+	Digest   string  `json:"digest"`
+}
+
+// OwnedObjectRef is an object reference together with its new owner
+type OwnedObjectRef struct {
+	Owner     Owner     `json:"owner"`
+	Reference ObjectRef `json:"reference"`
+}
+
+// GasCostSummary breaks down the gas charged for a transaction, in MIST
+type GasCostSummary struct {
+	ComputationCost         string `json:"computationCost"`
+	StorageCost             string `json:"storageCost"`
+	StorageRebate           string `json:"storageRebate"`
+	NonRefundableStorageFee string `json:"nonRefundableStorageFee"`
+}
+
+// ExecutionStatus reports whether a transaction succeeded
+type ExecutionStatus struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// TransactionEffects are the on-chain effects of an executed transaction
+type TransactionEffects struct {
+	Status            ExecutionStatus  `json:"status"`
+	ExecutedEpoch     string           `json:"executedEpoch"`
+	GasUsed           GasCostSummary   `json:"gasUsed"`
+	TransactionDigest string           `json:"transactionDigest"`
+	Created           []OwnedObjectRef `json:"created,omitempty"`
+	Mutated           []OwnedObjectRef `json:"mutated,omitempty"`
+	Deleted           []ObjectRef      `json:"deleted,omitempty"`
+}
+
+// BalanceChange is the net change of one coin type for one owner
+type BalanceChange struct {
+	Owner    Owner  `json:"owner"`
+	CoinType string `json:"coinType"`
+	// Amount is a signed decimal string in the coin's smallest unit
+	Amount string `json:"amount"`
+}
+
+// ObjectChange describes an object created, mutated, transferred, deleted or published by a transaction
+type ObjectChange struct {
+	Type       string   `json:"type"`
+	Sender     string   `json:"sender,omitempty"`
+	Owner      *Owner   `json:"owner,omitempty"`
+	ObjectType string   `json:"objectType,omitempty"`
+	ObjectID   string   `json:"objectId,omitempty"`
+	PackageID  string   `json:"packageId,omitempty"`
+	Modules    []string `json:"modules,omitempty"`
+	Version    Version  `json:"version,omitempty"`
+	Digest     string   `json:"digest,omitempty"`
+}
+
+// EventID uniquely identifies an emitted event
+type EventID struct {
+	TxDigest string `json:"txDigest"`
+	EventSeq string `json:"eventSeq"`
+}
+
+// Event is a Move event emitted by a transaction
+type Event struct {
+	ID                EventID `json:"id"`
+	PackageID         string  `json:"packageId"`
+	TransactionModule string  `json:"transactionModule"`
+	Sender            string  `json:"sender"`
+	Type              string  `json:"type"`
+	ParsedJSON        any     `json:"parsedJson,omitempty"`
+	TimestampMs       string  `json:"timestampMs,omitempty"`
+}
+
+// EventPage is one page of suix_queryEvents results; NextCursor is the event to continue after
+type EventPage struct {
+	Data        []Event  `json:"data"`
+	NextCursor  *EventID `json:"nextCursor"`
+	HasNextPage bool     `json:"hasNextPage"`
+}
+
+// GasData is the gas configuration of a transaction
+type GasData struct {
+	Owner  string `json:"owner"`
+	Price  string `json:"price"`
+	Budget string `json:"budget"`
+}
+
+// TransactionData is the sender-signed part of a transaction that we surface
+type TransactionData struct {
+	Sender  string  `json:"sender"`
+	GasData GasData `json:"gasData"`
+}
+
+// TransactionBlock wraps the transaction data as returned with showInput
+type TransactionBlock struct {
+	Data TransactionData `json:"data"`
+}
+
+// TransactionBlockResponse is a transaction with its effects, as returned by
+// `sui client tx-block --json`, the signing commands with --json and sui_getTransactionBlock.
+// Dry runs have the same shape except that the transaction data is under Input.
+type TransactionBlockResponse struct {
+	Digest         string              `json:"digest"`
+	Transaction    *TransactionBlock   `json:"transaction,omitempty"`
+	Input          *TransactionData    `json:"input,omitempty"`
+	Effects        *TransactionEffects `json:"effects,omitempty"`
+	Events         []Event             `json:"events,omitempty"`
+	ObjectChanges  []ObjectChange      `json:"objectChanges,omitempty"`
+	BalanceChanges []BalanceChange     `json:"balanceChanges,omitempty"`
+	TimestampMs    string              `json:"timestampMs,omitempty"`
+	Checkpoint     string              `json:"checkpoint,omitempty"`
+}
+
+// TransactionPage is one page of suix_queryTransactionBlocks results; NextCursor
+// is the digest to continue after
+type TransactionPage struct {
+	Data        []TransactionBlockResponse `json:"data"`
+	NextCursor  *string                    `json:"nextCursor"`
+	HasNextPage bool                       `json:"hasNextPage"`
+}